@@ -14,6 +14,7 @@ import (
 	"github.com/yyovil/tandem/internal/models"
 	"github.com/yyovil/tandem/internal/provider"
 	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/schedule"
 	"github.com/yyovil/tandem/internal/session"
 	"github.com/yyovil/tandem/internal/tools"
 )
@@ -43,11 +44,20 @@ type AgentEvent struct {
 	Done      bool
 }
 
+type noticeContextKey struct{}
+
+// WithNotice hands the model a notice along with the prompt of the next Run with ctx. it's kept apart from what the user
+// wrote, and the tasks handed out during the run don't get it.
+func WithNotice(ctx context.Context, notice string) context.Context {
+	return context.WithValue(ctx, noticeContextKey{}, notice)
+}
+
 type Service interface {
 	pubsub.Subscriber[AgentEvent]
 	Model() models.Model
 	Run(ctx context.Context, sessionID string, content string, attachments ...message.Attachment) (<-chan AgentEvent, error)
 	Cancel(sessionID string)
+	CancelAll()
 	IsSessionBusy(sessionID string) bool
 	IsBusy() bool
 	Update(agentName config.AgentName, modelID models.ModelID) (models.Model, error)
@@ -56,6 +66,7 @@ type Service interface {
 
 type agent struct {
 	*pubsub.Broker[AgentEvent]
+	name     config.AgentName
 	sessions session.Service
 	messages message.Service
 
@@ -81,7 +92,8 @@ func (a *agent) Run(ctx context.Context, sessionID string, prompt string, attach
 		return nil, ErrSessionBusy
 	}
 
-	genCtx, cancel := context.WithCancel(ctx)
+	notice, _ := ctx.Value(noticeContextKey{}).(string)
+	genCtx, cancel := context.WithCancel(context.WithValue(ctx, noticeContextKey{}, nil))
 
 	a.activeRequests.Store(sessionID, cancel)
 	go func() {
@@ -90,6 +102,9 @@ func (a *agent) Run(ctx context.Context, sessionID string, prompt string, attach
 			events <- a.err(fmt.Errorf("panic while running the agent"))
 		})
 		var attachmentParts []message.ContentPart
+		if notice != "" {
+			attachmentParts = append(attachmentParts, message.ContextContent{Text: notice})
+		}
 		for _, attachment := range attachments {
 			attachmentParts = append(attachmentParts, message.BinaryContent{Path: attachment.FilePath, MIMEType: attachment.MimeType, Data: attachment.Content})
 		}
@@ -221,6 +236,18 @@ func (a *agent) Cancel(sessionID string) {
 	}
 }
 
+// CancelAll cancels every in-flight request of the agent, including summarizations.
+func (a *agent) CancelAll() {
+	a.activeRequests.Range(func(key, value any) bool {
+		if cancel, ok := value.(context.CancelFunc); ok {
+			logging.InfoPersist(fmt.Sprintf("Request cancellation initiated for: %s", key))
+			cancel()
+		}
+		a.activeRequests.Delete(key)
+		return true
+	})
+}
+
 func (a *agent) IsBusy() bool {
 	busy := false
 	a.activeRequests.Range(func(key, value interface{}) bool {
@@ -482,6 +509,7 @@ func (a *agent) createUserMessage(ctx context.Context, sessionID, content string
 
 func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message) (message.Message, *message.Message, error) {
 	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
+	ctx = context.WithValue(ctx, tools.AgentNameContextKey, string(a.name))
 	eventChan := a.provider.StreamResponse(ctx, msgHistory, a.tools)
 
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
@@ -517,6 +545,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 			for j := i; j < len(toolCalls); j++ {
 				toolResults[j] = message.ToolResult{
 					ToolCallID: toolCalls[j].ID,
+					Name:       toolCalls[j].Name,
 					Content:    "Tool execution canceled by user",
 					IsError:    true,
 				}
@@ -541,12 +570,27 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 			if tool == nil {
 				toolResults[i] = message.ToolResult{
 					ToolCallID: toolCall.ID,
+					Name:       toolCall.Name,
 					Content:    fmt.Sprintf("Tool not found: %s", toolCall.Name),
 					IsError:    true,
 				}
 				continue
 			}
 
+			// NOTE: the rules of engagement may only allow testing at certain hours, the calls reaching the targets wait
+			// for them or are refused. the rest, like handing out a task or reading a file, go on.
+			if tools.ReachesTargets(tool) {
+				if err := schedule.Hold(ctx, string(a.name)); err != nil {
+					toolResults[i] = message.ToolResult{
+						ToolCallID: toolCall.ID,
+						Name:       toolCall.Name,
+						Content:    err.Error(),
+						IsError:    true,
+					}
+					continue
+				}
+			}
+
 			toolResult, toolErr := tool.Run(ctx, tools.ToolCall{
 				ID:    toolCall.ID,
 				Name:  toolCall.Name,
@@ -565,6 +609,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 				toolResults[i] = message.ToolResult{
 					IsError:    true,
 					ToolCallID: toolCall.ID,
+					Name:       toolCall.Name,
 					Content:    toolErr.Error(),
 					Metadata:   toolResult.Metadata,
 				}
@@ -574,13 +619,18 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 
 			toolResults[i] = message.ToolResult{
 				ToolCallID: toolCall.ID,
+				Name:       toolCall.Name,
 				Content:    toolResult.Content,
 				Metadata:   toolResult.Metadata,
 				IsError:    toolResult.IsError,
 			}
+			if toolResult.Type == tools.ToolResponseTypeImage && len(toolResult.Data) > 0 {
+				toolResults[i].Images = []message.BinaryContent{{MIMEType: toolResult.MIMEType, Data: toolResult.Data}}
+			}
 		}
 	}
 out:
+	a.maskToolCalls(&assistantMsg)
 	if len(toolResults) == 0 {
 		return assistantMsg, nil, nil
 	}
@@ -729,6 +779,26 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 	return nil
 }
 
+// maskToolCalls rewrites the inputs of the tool calls that ran with the secrets of the vault masked. the secrets handed
+// to store_credential are in the vault by now, the history keeps their handles instead.
+func (a *agent) maskToolCalls(msg *message.Message) {
+	masked := false
+	for i, part := range msg.Parts {
+		if toolCall, ok := part.(message.ToolCall); ok {
+			if input := tools.MaskInput(toolCall.Name, toolCall.Input); input != toolCall.Input {
+				toolCall.Input = input
+				msg.Parts[i], masked = toolCall, true
+			}
+		}
+	}
+	if !masked {
+		return
+	}
+	if err := a.messages.Update(context.Background(), *msg); err != nil {
+		logging.Warn("failed to mask the secrets in the tool calls", "message", msg.ID, "error", err)
+	}
+}
+
 func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishReson message.FinishReason) {
 	msg.AddFinish(finishReson)
 	_ = a.messages.Update(ctx, *msg)
@@ -790,10 +860,11 @@ func NewAgent(
 
 	agent := &agent{
 		Broker:            pubsub.NewBroker[AgentEvent](),
+		name:              agentName,
 		provider:          agentProvider,
 		messages:          messages,
 		sessions:          sessions,
-		tools:             agentTools,
+		tools:             tools.Audited(agentTools...),
 		titleProvider:     titleProvider,
 		summarizeProvider: summarizeProvider,
 		activeRequests:    sync.Map{},
@@ -7,10 +7,14 @@ import (
 	"slices"
 
 	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
 	"github.com/yyovil/tandem/internal/logging"
 	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/report"
 	"github.com/yyovil/tandem/internal/session"
 	"github.com/yyovil/tandem/internal/tools"
+	"github.com/yyovil/tandem/internal/vault"
 )
 
 const AgentToolName = "subagent"
@@ -29,8 +33,12 @@ type AgentToolArgs struct {
 }
 
 type AgentTool struct {
-	messages message.Service
-	sessions session.Service
+	messages  message.Service
+	sessions  session.Service
+	inventory inventory.Service
+	vault     vault.Service
+	reports   report.Service
+	findings  finding.Service
 }
 
 func (a *AgentTool) Info() tools.ToolInfo {
@@ -73,7 +81,19 @@ func (a *AgentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	}
 
 	// NOTE: you can add more tools later here if needed on AgentName basis.
-	agentTools := tools.PenetrationTestingAgentTools
+	agentTools := append(slices.Clone(tools.PenetrationTestingAgentTools),
+		tools.NewNmapTool(a.inventory),
+		tools.NewStoreCredentialTool(a.vault),
+		tools.NewListCredentialsTool(a.vault),
+		tools.NewRecordFindingTool(a.findings, a.inventory),
+		tools.NewListFindingsTool(a.findings, a.inventory),
+		tools.NewSearchHistoryTool(a.messages),
+	)
+	if args.AgentName == config.Reporter {
+		agentTools = append(agentTools, tools.NewWriteReportSectionTool(a.reports))
+	}
+	agentTools = append(agentTools, tools.MCPToolsFor(ctx, args.AgentName)...)
+	agentTools = append(agentTools, tools.ScriptToolsFor(args.AgentName)...)
 	agent, err := NewAgent(args.AgentName, a.sessions, a.messages, agentTools, args.ExpectedOutput)
 	if err != nil {
 		return tools.NewTextErrorResponse("failed to create agent: " + err.Error()), nil
@@ -83,6 +103,12 @@ func (a *AgentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	if err != nil {
 		return tools.ToolResponse{}, fmt.Errorf("error creating session: %s", err)
 	}
+	// NOTE: the isolated container of the task session is thrown away once the subagent is done with it.
+	defer func() {
+		if err := tools.ReleaseSession(context.Background(), session.ID); err != nil {
+			logging.Error("failed to release the container of the task session", "session", session.ID, "error", err)
+		}
+	}()
 
 	done, err := agent.Run(ctx, session.ID, args.Prompt)
 	if err != nil {
@@ -122,9 +148,17 @@ func (a *AgentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 func NewAgentTool(
 	Sessions session.Service,
 	Messages message.Service,
+	Inventory inventory.Service,
+	Vault vault.Service,
+	Reports report.Service,
+	Findings finding.Service,
 ) tools.BaseTool {
 	return &AgentTool{
-		sessions: Sessions,
-		messages: Messages,
+		sessions:  Sessions,
+		messages:  Messages,
+		inventory: Inventory,
+		vault:     Vault,
+		reports:   Reports,
+		findings:  Findings,
 	}
 }
@@ -5,20 +5,38 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 
 	"github.com/yyovil/tandem/internal/agent"
+	"github.com/yyovil/tandem/internal/audit"
 	"github.com/yyovil/tandem/internal/config"
 	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/finding"
 	"github.com/yyovil/tandem/internal/format"
+	"github.com/yyovil/tandem/internal/importer"
+	"github.com/yyovil/tandem/internal/inventory"
 	"github.com/yyovil/tandem/internal/logging"
 	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/provider"
+	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/redact"
+	"github.com/yyovil/tandem/internal/report"
+	"github.com/yyovil/tandem/internal/schedule"
 	"github.com/yyovil/tandem/internal/session"
 	"github.com/yyovil/tandem/internal/tools"
+	"github.com/yyovil/tandem/internal/vault"
 )
 
 type App struct {
 	Sessions     session.Service
 	Messages     message.Service
+	Inventory    inventory.Service
+	Findings     finding.Service
+	Imports      importer.Service
+	Vault        vault.Service
+	Audit        audit.Service
+	Reports      report.Service
 	Orchestrator agent.Service
 	// ADHD: why we shouldn't initialise all the agents at once right in here? here's another thought. we don't want to have multiple agents of the same time, say couple of reconnoiters, doing some scanning because of the nature of the task in hand.
 }
@@ -27,18 +45,68 @@ func New(ctx context.Context, conn *sql.DB) (*App, error) {
 	q := db.New(conn)
 	sessions := session.NewService(q)
 	messages := message.NewService(q)
+	inventory := inventory.NewService(q)
+	findings := finding.NewService(q)
 
 	app := &App{
-		Sessions: sessions,
-		Messages: messages,
+		Sessions:  sessions,
+		Messages:  messages,
+		Inventory: inventory,
+		Findings:  findings,
+		Imports:   importer.NewService(q, inventory, findings),
+		Vault:     vault.NewService(q),
+		Audit:     audit.NewService(q),
+		Reports:   report.NewService(q),
+	}
+	tools.UseVault(app.Vault)
+	tools.UseAuditLog(app.Audit)
+
+	redactor, err := redact.New(config.Get().Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction rules: %w", err)
+	}
+	provider.UseRedactor(redactor)
+
+	testingWindows, err := schedule.FromConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+	if testingWindows.Defined() {
+		// NOTE: the agents notice a closed window on their next tool call, the operator hears about it right away.
+		go testingWindows.Watch(ctx, func(state schedule.State) {
+			switch {
+			case state.Open && state.Until.IsZero():
+				logging.InfoPersist("the testing window is open")
+			case state.Open:
+				logging.InfoPersist(fmt.Sprintf("the testing window is open until %s", state.Until.Format("Mon 15:04 MST")))
+			case state.Until.IsZero():
+				logging.WarnPersist(fmt.Sprintf("testing isn't allowed anymore, %s", state.Reason))
+			default:
+				logging.WarnPersist(fmt.Sprintf("the testing window closed, %s. it opens again at %s", state.Reason, state.Until.Format("Mon 15:04 MST")))
+			}
+		})
+	}
+
+	// NOTE: without the passphrase in the environment the vault stays locked until the operator unlocks it in the TUI.
+	if passphrase := os.Getenv(vault.PassphraseEnv); passphrase != "" {
+		if err := app.Vault.Unlock(ctx, passphrase); err != nil {
+			return nil, fmt.Errorf("failed to unlock the credential vault: %w", err)
+		}
 	}
 
-	var err error
 	app.Orchestrator, err = agent.NewAgent(
 		config.Orchestrator,
 		app.Sessions,
 		app.Messages,
-		[]tools.BaseTool{agent.NewAgentTool(app.Sessions, app.Messages)},
+		slices.Concat(
+			[]tools.BaseTool{
+				agent.NewAgentTool(app.Sessions, app.Messages, app.Inventory, app.Vault, app.Reports, app.Findings),
+				tools.NewListFindingsTool(app.Findings, app.Inventory),
+				tools.NewSearchHistoryTool(app.Messages),
+			},
+			tools.MCPToolsFor(ctx, config.Orchestrator),
+			tools.ScriptToolsFor(config.Orchestrator),
+		),
 		nil,
 	)
 
@@ -47,6 +115,8 @@ func New(ctx context.Context, conn *sql.DB) (*App, error) {
 		return nil, err
 	}
 
+	go app.releaseDeletedSessions(ctx)
+
 	return app, nil
 }
 
@@ -78,7 +148,7 @@ func (a *App) RunNonInteractive(ctx context.Context, prompt string, outputFormat
 	}
 	logging.Info("Created session for non-interactive run", "session_id", sess.ID)
 
-	done, err := a.Orchestrator.Run(ctx, sess.ID, prompt)
+	done, err := a.RunOrchestrator(ctx, sess.ID, prompt)
 	if err != nil {
 		return fmt.Errorf("failed to start agent processing stream: %w", err)
 	}
@@ -107,3 +177,72 @@ func (a *App) RunNonInteractive(ctx context.Context, prompt string, outputFormat
 
 	return nil
 }
+
+// RunOrchestrator hands a prompt to the orchestrator along with a notice about the scanner output imported since its last run.
+func (a *App) RunOrchestrator(ctx context.Context, sessionID string, prompt string, attachments ...message.Attachment) (<-chan agent.AgentEvent, error) {
+	// NOTE: a missed notice shouldn't keep the user from talking to the orchestrator, it comes along with the next prompt instead.
+	pending, err := a.Imports.Pending(ctx)
+	if err != nil {
+		logging.Error("failed to list the pending imports", "error", err)
+	}
+	notice, err := a.Imports.Notice(ctx, pending)
+	if err != nil {
+		logging.Error("failed to prepare the import notice", "error", err)
+		pending = nil
+	}
+	// NOTE: the notice isn't the user's, it's handed to the model beside the prompt rather than written into it.
+	if notice != "" {
+		ctx = agent.WithNotice(ctx, notice)
+	}
+
+	done, err := a.Orchestrator.Run(ctx, sessionID, prompt, attachments...)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Imports.MarkNotified(ctx, pending); err != nil {
+		logging.Error("failed to mark the imports notified", "error", err)
+	}
+	return done, nil
+}
+
+// EmergencyStop kills every process the agents started in the container, pauses it and cancels all in-flight requests.
+func (a *App) EmergencyStop(ctx context.Context) error {
+	err := tools.EmergencyStop(ctx)
+	a.Orchestrator.CancelAll()
+	if err != nil {
+		logging.ErrorPersist(fmt.Sprintf("emergency stop: %v", err))
+		return err
+	}
+	logging.WarnPersist("Emergency stop: agent processes killed and the container is paused")
+	return nil
+}
+
+// ResumeExecution lifts an emergency stop.
+func (a *App) ResumeExecution(ctx context.Context) error {
+	if err := tools.Resume(ctx); err != nil {
+		return err
+	}
+	logging.InfoPersist("Command execution resumed")
+	return nil
+}
+
+// Shutdown releases the isolated containers the sessions are still holding on to. the ones of the subagent tasks go as
+// soon as the tasks are done, the ones of the top-level sessions live as long as the app does.
+func (a *App) Shutdown(ctx context.Context) {
+	if err := tools.ReleaseSessions(ctx); err != nil {
+		logging.Error("failed to release the session containers", "error", err)
+	}
+}
+
+// releaseDeletedSessions releases the isolated container of every session deleted while the app runs.
+func (a *App) releaseDeletedSessions(ctx context.Context) {
+	defer logging.RecoverPanic("release-deleted-sessions", nil)
+	for event := range a.Sessions.Subscribe(ctx) {
+		if event.Type != pubsub.DeletedEvent {
+			continue
+		}
+		if err := tools.ReleaseSession(ctx, event.Payload.ID); err != nil {
+			logging.Error("failed to release the session container", "session_id", event.Payload.ID, "error", err)
+		}
+	}
+}
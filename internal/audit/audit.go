@@ -0,0 +1,269 @@
+// Package audit keeps a tamper-evident log of every tool execution of the engagement. each entry carries the hash of
+// the entry before it, so editing, inserting or removing an entry anywhere but at the end breaks the chain, and the
+// exports handed to the client are signed with the engagement's ed25519 key.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/tools"
+)
+
+// GenesisHash is what the first entry of the log chains to.
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// maxAppendAttempts bounds the retries when another process appends to the log at the same time.
+const maxAppendAttempts = 5
+
+// Entry is a tool execution as it was recorded.
+type Entry struct {
+	ID           int64  `json:"id"`
+	SessionID    string `json:"session_id,omitempty"`
+	Agent        string `json:"agent,omitempty"`
+	Tool         string `json:"tool"`
+	ToolCallID   string `json:"tool_call_id,omitempty"`
+	Input        string `json:"input,omitempty"`
+	Command      string `json:"command,omitempty"`
+	Executor     string `json:"executor,omitempty"`
+	ContainerID  string `json:"container_id,omitempty"`
+	ExitCode     *int   `json:"exit_code,omitempty"`
+	IsError      bool   `json:"is_error"`
+	OutputSHA256 string `json:"output_sha256"`
+	StartedAt    int64  `json:"started_at"`  // Unix timestamp in milliseconds
+	FinishedAt   int64  `json:"finished_at"` // Unix timestamp in milliseconds
+	PrevHash     string `json:"prev_hash"`
+	Hash         string `json:"hash"`
+}
+
+// ComputeHash hashes the entry along with the hash of the entry before it. the id isn't part of it, the order is
+// kept by the chain itself.
+func (e Entry) ComputeHash() string {
+	hashed := e
+	hashed.ID, hashed.Hash = 0, ""
+	data, _ := json.Marshal(hashed)
+	sum := sha256.Sum256(append([]byte(e.PrevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// TamperError tells where the chain breaks.
+type TamperError struct {
+	ID     int64
+	Reason string
+}
+
+func (e *TamperError) Error() string {
+	return fmt.Sprintf("audit entry %d: %s", e.ID, e.Reason)
+}
+
+// VerifyChain checks that every entry hashes to what it says and chains to the one before it, the first entry to
+// the anchor.
+func VerifyChain(entries []Entry, anchor string) error {
+	prev := anchor
+	for _, entry := range entries {
+		if entry.PrevHash != prev {
+			return &TamperError{ID: entry.ID, Reason: "doesn't chain to the entry before it, an entry was removed, inserted or edited"}
+		}
+		if entry.ComputeHash() != entry.Hash {
+			return &TamperError{ID: entry.ID, Reason: "doesn't match its hash, it was edited"}
+		}
+		prev = entry.Hash
+	}
+	return nil
+}
+
+type Service interface {
+	pubsub.Subscriber[Entry]
+	// Record appends a tool execution, it satisfies tools.AuditLog.
+	Record(ctx context.Context, call tools.ToolCall, response tools.ToolResponse, runErr error, startedAt, finishedAt time.Time) error
+	Append(ctx context.Context, entry Entry) (Entry, error)
+	// List returns the entries from the first to the last one started within [from, to), a zero time leaves that end
+	// open. the entries are chained in the order the calls finish, so the ones in between are returned as well, even
+	// when they started outside of the window, e.g. a subagent call that started before its first child call.
+	List(ctx context.Context, from, to time.Time) ([]Entry, error)
+	// Verify checks the chain of the whole log.
+	Verify(ctx context.Context) (int, error)
+}
+
+type service struct {
+	*pubsub.Broker[Entry]
+	q  db.Querier
+	mu sync.Mutex
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Entry](),
+		q:      q,
+	}
+}
+
+// executionMetadata is what the terminal, the script tools and their likes report about the command they ran.
+type executionMetadata struct {
+	Command     string `json:"command"`
+	Executor    string `json:"executor"`
+	ContainerID string `json:"container_id"`
+	ExitCode    *int   `json:"exit_code"`
+}
+
+func (s *service) Record(ctx context.Context, call tools.ToolCall, response tools.ToolResponse, runErr error, startedAt, finishedAt time.Time) error {
+	sessionID, _ := ctx.Value(tools.SessionIDContextKey).(string)
+	agentName, _ := ctx.Value(tools.AgentNameContextKey).(string)
+	entry := Entry{
+		SessionID:  sessionID,
+		Agent:      agentName,
+		Tool:       call.Name,
+		ToolCallID: call.ID,
+		// NOTE: the log is handed to the client, the secrets of the vault go into it as their handles.
+		Input:      tools.MaskInput(call.Name, call.Input),
+		IsError:    response.IsError || runErr != nil,
+		StartedAt:  startedAt.UnixMilli(),
+		FinishedAt: finishedAt.UnixMilli(),
+	}
+	var metadata executionMetadata
+	if response.Metadata != "" && json.Unmarshal([]byte(response.Metadata), &metadata) == nil {
+		entry.Command = metadata.Command
+		entry.Executor = metadata.Executor
+		entry.ContainerID = metadata.ContainerID
+		entry.ExitCode = metadata.ExitCode
+	}
+
+	output := sha256.New()
+	if runErr != nil {
+		output.Write([]byte(runErr.Error()))
+	} else {
+		output.Write([]byte(response.Content))
+		output.Write(response.Data)
+	}
+	entry.OutputSHA256 = hex.EncodeToString(output.Sum(nil))
+
+	_, err := s.Append(ctx, entry)
+	return err
+}
+
+func (s *service) Append(ctx context.Context, entry Entry) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// NOTE: the audit log outlives a canceled task, the entry of the command it cut short still has to be written.
+	ctx = context.WithoutCancel(ctx)
+
+	var err error
+	for range maxAppendAttempts {
+		var last db.AuditLog
+		last, err = s.q.GetLastAuditEntry(ctx)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			entry.PrevHash = GenesisHash
+		case err != nil:
+			return Entry{}, fmt.Errorf("failed to read the end of the audit log: %w", err)
+		default:
+			entry.PrevHash = last.Hash
+		}
+		entry.Hash = entry.ComputeHash()
+
+		var created db.AuditLog
+		created, err = s.q.CreateAuditEntry(ctx, toParams(entry))
+		if err == nil {
+			entry = fromDBItem(created)
+			s.Publish(pubsub.CreatedEvent, entry)
+			return entry, nil
+		}
+		// NOTE: prev_hash is unique, another process appended to the same entry first. chain to its entry instead.
+		if !strings.Contains(err.Error(), "UNIQUE") {
+			break
+		}
+	}
+	return Entry{}, fmt.Errorf("failed to append to the audit log: %w", err)
+}
+
+func (s *service) List(ctx context.Context, from, to time.Time) ([]Entry, error) {
+	var items []db.AuditLog
+	var err error
+	if from.IsZero() && to.IsZero() {
+		items, err = s.q.ListAuditEntries(ctx)
+	} else {
+		params := db.ListAuditEntriesBetweenParams{To: math.MaxInt64}
+		if !from.IsZero() {
+			params.From = from.UnixMilli()
+		}
+		if !to.IsZero() {
+			params.To = to.UnixMilli()
+		}
+		items, err = s.q.ListAuditEntriesBetween(ctx, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(items))
+	for i, item := range items {
+		entries[i] = fromDBItem(item)
+	}
+	return entries, nil
+}
+
+func (s *service) Verify(ctx context.Context) (int, error) {
+	entries, err := s.List(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), VerifyChain(entries, GenesisHash)
+}
+
+func toParams(entry Entry) db.CreateAuditEntryParams {
+	params := db.CreateAuditEntryParams{
+		SessionID:    entry.SessionID,
+		Agent:        entry.Agent,
+		Tool:         entry.Tool,
+		ToolCallID:   entry.ToolCallID,
+		Input:        entry.Input,
+		Command:      entry.Command,
+		Executor:     entry.Executor,
+		ContainerID:  entry.ContainerID,
+		IsError:      entry.IsError,
+		OutputSha256: entry.OutputSHA256,
+		StartedAt:    entry.StartedAt,
+		FinishedAt:   entry.FinishedAt,
+		PrevHash:     entry.PrevHash,
+		Hash:         entry.Hash,
+	}
+	if entry.ExitCode != nil {
+		params.ExitCode = sql.NullInt64{Int64: int64(*entry.ExitCode), Valid: true}
+	}
+	return params
+}
+
+func fromDBItem(item db.AuditLog) Entry {
+	entry := Entry{
+		ID:           item.ID,
+		SessionID:    item.SessionID,
+		Agent:        item.Agent,
+		Tool:         item.Tool,
+		ToolCallID:   item.ToolCallID,
+		Input:        item.Input,
+		Command:      item.Command,
+		Executor:     item.Executor,
+		ContainerID:  item.ContainerID,
+		IsError:      item.IsError,
+		OutputSHA256: item.OutputSha256,
+		StartedAt:    item.StartedAt,
+		FinishedAt:   item.FinishedAt,
+		PrevHash:     item.PrevHash,
+		Hash:         item.Hash,
+	}
+	if item.ExitCode.Valid {
+		exitCode := int(item.ExitCode.Int64)
+		entry.ExitCode = &exitCode
+	}
+	return entry
+}
@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/tools"
+)
+
+func newTestService(t *testing.T) (Service, *sql.DB) {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db.New(conn)), conn
+}
+
+func TestAuditLog(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tools.AgentNameContextKey, "reconnoiter")
+	service, conn := newTestService(t)
+
+	for i, command := range []string{"nmap -sV 10.10.10.5", "whoami", "id"} {
+		metadata := tools.WithResponseMetadata(tools.NewTextResponse("output"), tools.TerminalResponseMetadata{Command: command, Executor: "docker", ExitCode: i})
+		started := time.Now()
+		if err := service.Record(ctx, tools.ToolCall{ID: command, Name: tools.TerminalToolName, Input: `{}`}, metadata, nil, started, started.Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := service.List(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].PrevHash != GenesisHash || entries[2].Command != "id" || *entries[2].ExitCode != 2 || entries[1].Agent != "reconnoiter" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if count, err := service.Verify(ctx); err != nil || count != 3 {
+		t.Fatalf("expected an intact chain of 3 entries, got %d, %v", count, err)
+	}
+
+	if _, err := conn.Exec("UPDATE audit_log SET command = 'ls' WHERE id = 2"); err == nil {
+		t.Fatal("expected the audit log to refuse updates")
+	}
+	// NOTE: someone with the database in hand can drop the triggers, the chain still gives them away.
+	if _, err := conn.Exec("DROP TRIGGER audit_log_no_update; UPDATE audit_log SET command = 'ls' WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	var tampered *TamperError
+	if _, err := service.Verify(ctx); !errors.As(err, &tampered) || tampered.ID != 2 {
+		t.Fatalf("expected entry 2 to be reported, got %v", err)
+	}
+}
+
+func TestRecordWithholdsSecrets(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	call := tools.ToolCall{ID: "call-1", Name: tools.StoreCredentialToolName, Input: `{"username":"admin","secret":"hunter22"}`}
+	started := time.Now()
+	if err := service.Record(ctx, call, tools.NewTextResponse("stored as {{cred:1}}"), nil, started, started); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := service.List(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || strings.Contains(entries[0].Input, "hunter22") || !strings.Contains(entries[0].Input, "admin") {
+		t.Fatalf("expected the secret to be withheld, got %+v", entries)
+	}
+}
+
+func TestListWindowKeepsTheChain(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestService(t)
+
+	// NOTE: a subagent call starts before the calls of its task and is appended after them, when it finishes.
+	for _, entry := range []Entry{
+		{Tool: tools.TerminalToolName, Command: "whoami", StartedAt: 100, FinishedAt: 150},
+		{Tool: tools.TerminalToolName, Command: "nmap 10.10.10.5", StartedAt: 300, FinishedAt: 350},
+		{Tool: tools.TerminalToolName, Command: "id", StartedAt: 400, FinishedAt: 450},
+		{Tool: "subagent", StartedAt: 200, FinishedAt: 500},
+		{Tool: tools.TerminalToolName, Command: "uname -a", StartedAt: 600, FinishedAt: 650},
+	} {
+		if _, err := service.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := service.List(ctx, time.UnixMilli(250), time.UnixMilli(700))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[0].ID != 2 || entries[2].Tool != "subagent" {
+		t.Fatalf("expected entries 2 to 5, got %+v", entries)
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := NewExport(entries, "acme", time.UnixMilli(250), time.UnixMilli(700), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := export.Verify(); err != nil {
+		t.Fatalf("expected the export of the window to verify, got %v", err)
+	}
+
+	if entries, err := service.List(ctx, time.UnixMilli(1000), time.Time{}); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries after the last one, got %+v, %v", entries, err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := "f00d"
+	var entries []Entry
+	for i, command := range []string{"whoami", "id", "uname -a"} {
+		entry := Entry{ID: int64(i + 10), Tool: tools.TerminalToolName, Command: command, PrevHash: prev}
+		entry.Hash = entry.ComputeHash()
+		prev = entry.Hash
+		entries = append(entries, entry)
+	}
+
+	export, err := NewExport(entries, "acme", time.Time{}, time.Now(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := export.Verify(); err != nil {
+		t.Fatalf("expected the export to verify, got %v", err)
+	}
+
+	edited := export
+	edited.Entries = append([]Entry(nil), export.Entries...)
+	edited.Entries[1].Command = "rm -rf /"
+	if err := edited.Verify(); err == nil {
+		t.Fatal("expected an edited entry to break the signature")
+	}
+	removed := export
+	removed.Entries = []Entry{export.Entries[0], export.Entries[2]}
+	if err := removed.Verify(); err == nil {
+		t.Fatal("expected a removed entry to break the signature")
+	}
+	if err := VerifyChain(removed.Entries, removed.Entries[0].PrevHash); err == nil {
+		t.Fatal("expected a removed entry to break the chain")
+	}
+}
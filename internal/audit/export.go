@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ExportVersion is bumped whenever the layout of the export changes.
+const ExportVersion = 1
+
+// KeyFile is the name of the signing key within the data directory of the engagement.
+const KeyFile = "audit.key"
+
+// Export is the part of the log handed to the client, Signature signs the JSON of everything else.
+type Export struct {
+	Version    int     `json:"version"`
+	Engagement string  `json:"engagement,omitempty"`
+	From       int64   `json:"from,omitempty"` // Unix timestamp in milliseconds
+	To         int64   `json:"to,omitempty"`   // Unix timestamp in milliseconds
+	ExportedAt int64   `json:"exported_at"`    // Unix timestamp in milliseconds
+	PublicKey  string  `json:"public_key"`     // base64 ed25519 public key
+	Entries    []Entry `json:"entries"`
+	Signature  string  `json:"signature,omitempty"` // base64 ed25519 signature
+}
+
+// Fingerprint is the sha256 of the public key, short enough to be read out to the client for them to compare.
+func Fingerprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewExport signs the entries with the key.
+func NewExport(entries []Entry, engagement string, from, to time.Time, key ed25519.PrivateKey) (Export, error) {
+	export := Export{
+		Version:    ExportVersion,
+		Engagement: engagement,
+		ExportedAt: time.Now().UnixMilli(),
+		PublicKey:  base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Entries:    entries,
+	}
+	if export.Entries == nil {
+		export.Entries = []Entry{}
+	}
+	if !from.IsZero() {
+		export.From = from.UnixMilli()
+	}
+	if !to.IsZero() {
+		export.To = to.UnixMilli()
+	}
+	payload, err := export.payload()
+	if err != nil {
+		return Export{}, err
+	}
+	export.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return export, nil
+}
+
+func (e Export) payload() ([]byte, error) {
+	e.Signature = ""
+	return json.Marshal(e)
+}
+
+// Verify checks the signature of the export and the chain of its entries. the first entry chains to one that wasn't
+// exported, the signature vouches for it instead.
+func (e Export) Verify() error {
+	if e.Version != ExportVersion {
+		return fmt.Errorf("unsupported export version %d", e.Version)
+	}
+	publicKey, err := e.Key()
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil {
+		return errors.New("the export doesn't hold a valid signature")
+	}
+	payload, err := e.payload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return errors.New("the signature doesn't match, the export was modified after it was signed")
+	}
+	if len(e.Entries) == 0 {
+		return nil
+	}
+	return VerifyChain(e.Entries, e.Entries[0].PrevHash)
+}
+
+// Key is the public key the export was signed with.
+func (e Export) Key() (ed25519.PublicKey, error) {
+	publicKey, err := base64.StdEncoding.DecodeString(e.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("the export doesn't hold a valid ed25519 public key")
+	}
+	return publicKey, nil
+}
+
+// LoadKey reads the signing key of the engagement from its data directory, creating it on first use.
+func LoadKey(dataDir string) (ed25519.PrivateKey, error) {
+	path := filepath.Join(dataDir, KeyFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s isn't a PEM encoded key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the audit signing key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't an ed25519 key", path)
+	}
+	return key, nil
+}
+
+func createKey(path string) (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to save the audit signing key: %w", err)
+	}
+	return key, nil
+}
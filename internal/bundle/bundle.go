@@ -0,0 +1,245 @@
+// Package bundle packs an engagement into a single archive to hand it over to another tester, and merges such an
+// archive into the engagement of another installation.
+//
+// a bundle is a gzipped tar holding manifest.json first, the rows of the database as NDJSON under data/, the files of
+// the evidence directory under artifacts/ and the swarm.json, with its secrets stripped, and the rules of engagement
+// under config/. the manifest lists every other file with its size and sha256.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/version"
+)
+
+// Version is the version of the layout of the archive, bundles of a later version are refused.
+const Version = 1
+
+const (
+	ManifestFile = "manifest.json"
+	dataDir      = "data"
+	artifactsDir = "artifacts"
+	configDir    = "config"
+	configFile   = configDir + "/swarm.json"
+	roeFile      = configDir + "/RoE.md"
+)
+
+// Manifest describes a bundle.
+type Manifest struct {
+	Version int `json:"version"`
+	// SchemaVersion is the version of the database the bundle was exported from, the latest migration applied.
+	SchemaVersion int64          `json:"schema_version"`
+	Engagement    string         `json:"engagement,omitempty"`
+	TandemVersion string         `json:"tandem_version"`
+	CreatedAt     string         `json:"created_at"` // RFC 3339
+	Counts        map[string]int `json:"counts"`
+	Files         []File         `json:"files"`
+}
+
+// File is a file of the bundle besides the manifest.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Paths are where the engagement keeps what goes into a bundle.
+type Paths struct {
+	EvidenceDir string
+	ConfigFile  string
+	RoEFile     string
+}
+
+// entry is a file to write into the archive, either in memory or on disk.
+type entry struct {
+	File
+	content []byte
+	source  string
+}
+
+// Export writes the engagement behind conn and paths as a bundle. the audit log and the vault stay behind: the
+// chain of the audit log can't be merged into another one, export it with tandem audit export instead, and the
+// credentials in the vault are encrypted with the passphrase of this installation.
+func Export(ctx context.Context, w io.Writer, conn *sql.DB, paths Paths, engagement string) (Manifest, error) {
+	schemaVersion, err := goose.GetDBVersion(conn)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read the version of the database: %w", err)
+	}
+	manifest := Manifest{
+		Version:       Version,
+		SchemaVersion: schemaVersion,
+		Engagement:    engagement,
+		TandemVersion: version.Version,
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+		Counts:        map[string]int{},
+		Files:         []File{},
+	}
+
+	tables, err := dump(ctx, db.New(conn))
+	if err != nil {
+		return Manifest{}, err
+	}
+	var entries []entry
+	for _, table := range tables {
+		manifest.Counts[table.name] = table.rows
+		entries = append(entries, memoryEntry(dataDir+"/"+table.name+".ndjson", table.content))
+	}
+
+	if content, err := os.ReadFile(paths.ConfigFile); err == nil {
+		stripped, err := StripSecrets(content)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("failed to strip the secrets of %s: %w", paths.ConfigFile, err)
+		}
+		entries = append(entries, memoryEntry(configFile, stripped))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, err
+	}
+	if content, err := os.ReadFile(paths.RoEFile); err == nil {
+		entries = append(entries, memoryEntry(roeFile, content))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, err
+	}
+
+	artifacts, err := artifactEntries(paths.EvidenceDir)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to read the evidence: %w", err)
+	}
+	manifest.Counts["artifacts"] = len(artifacts)
+	entries = append(entries, artifacts...)
+	for _, e := range entries {
+		manifest.Files = append(manifest.Files, e.File)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := writeEntry(tw, memoryEntry(ManifestFile, content)); err != nil {
+		return Manifest{}, err
+	}
+	for _, e := range entries {
+		if err := writeEntry(tw, e); err != nil {
+			return Manifest{}, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, gz.Close()
+}
+
+func memoryEntry(path string, content []byte) entry {
+	sum := sha256.Sum256(content)
+	return entry{File: File{Path: path, Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}, content: content}
+}
+
+// artifactEntries hashes the files of the evidence directory, they're read again as they're written.
+func artifactEntries(dir string) ([]entry, error) {
+	var entries []entry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		file, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		file.Path = artifactsDir + "/" + filepath.ToSlash(rel)
+		entries = append(entries, entry{File: file, source: path})
+		return nil
+	})
+	return entries, err
+}
+
+func hashFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func writeEntry(tw *tar.Writer, e entry) error {
+	header := &tar.Header{Name: e.Path, Mode: 0o644, Size: e.Size, ModTime: time.Now(), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if e.source == "" {
+		_, err := tw.Write(e.content)
+		return err
+	}
+	f, err := os.Open(e.source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// NOTE: a file that grew since it was hashed is cut to the size in the manifest, its checksum fails the import.
+	if _, err := io.CopyN(tw, f, e.Size); err != nil {
+		return fmt.Errorf("%s changed while it was bundled: %w", e.source, err)
+	}
+	return nil
+}
+
+// writeNDJSON encodes the rows one per line.
+func writeNDJSON[T any](rows []T) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// readNDJSON decodes the rows of a file, a missing file has none.
+func readNDJSON[T any](path string) ([]T, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rows []T
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	for decoder.More() {
+		var row T
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,184 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/session"
+)
+
+func newTestDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+name+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func newTestPaths(t *testing.T) Paths {
+	dir := t.TempDir()
+	return Paths{
+		EvidenceDir: filepath.Join(dir, "evidence"),
+		ConfigFile:  filepath.Join(dir, ".tandem", "swarm.json"),
+		RoEFile:     filepath.Join(dir, ".tandem", "RoE.md"),
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	ctx := context.Background()
+	source, sourcePaths := newTestDB(t, "source"), newTestPaths(t)
+	q := db.New(source)
+	s, err := session.NewService(q).Create(ctx, "Recon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := message.NewService(q).Create(ctx, s.ID, message.CreateMessageParams{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "scan it"}}}); err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: stored before the agents kept the handles in the calls to store_credential.
+	if _, err := message.NewService(q).Create(ctx, s.ID, message.CreateMessageParams{Role: message.Assistant, Parts: []message.ContentPart{
+		message.TextContent{Text: "logging in as admin with {{cred:1}}"},
+		message.ToolCall{ID: "call-1", Name: "store_credential", Input: `{"username":"admin","secret":"hunter22"}`, Finished: true},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	host, err := inventory.NewService(q).RecordHost(ctx, inventory.Host{Address: "10.10.10.5", Source: "nmap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := finding.NewService(q).Record(ctx, finding.Finding{HostID: host.ID, Port: 22, Protocol: "tcp", Title: "Weak SSH ciphers", Source: "nmap", CVSSVector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:L/I:N/A:N"}); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(sourcePaths.EvidenceDir, "scan.xml"), "<nmaprun/>")
+	writeFile(t, sourcePaths.ConfigFile, `{"providers":{"anthropic":{"apiKey":"sk-ant-secret"}},"mcpServers":{"x":{"env":["TOKEN=abc"],"headers":{"Authorization":"Bearer abc"}}},"engagement":"acme"}`)
+	writeFile(t, sourcePaths.RoEFile, "# RoE")
+
+	var archive bytes.Buffer
+	manifest, err := Export(ctx, &archive, source, sourcePaths, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Counts["sessions"] != 1 || manifest.Counts["findings"] != 1 || manifest.Counts["artifacts"] != 1 {
+		t.Fatalf("unexpected counts %v", manifest.Counts)
+	}
+
+	// NOTE: the engagement imported into already has the host, under another ID.
+	target, targetPaths := newTestDB(t, "target"), newTestPaths(t)
+	local, err := inventory.NewService(db.New(target)).RecordHost(ctx, inventory.Host{Address: "10.10.10.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(targetPaths.EvidenceDir, "scan.xml"), "<other/>")
+	_, result, err := Import(ctx, bytes.NewReader(archive.Bytes()), target, targetPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sessions.Imported != 1 || result.Messages.Imported != 2 || result.Findings.Imported != 1 || result.Hosts.Skipped != 1 || result.Artifacts.Imported != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	findings, err := finding.NewService(db.New(target)).List(ctx)
+	if err != nil || len(findings) != 1 || findings[0].HostID != local.ID {
+		t.Fatalf("expected the finding on the host already there, got %+v %v", findings, err)
+	}
+	messages, err := message.NewService(db.New(target)).List(ctx, s.ID)
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("expected the messages of the session, got %d %v", len(messages), err)
+	}
+	if text := messages[1].Content().Text; text != "logging in as admin with {{foreign-cred:1}}" {
+		t.Errorf("expected the handle of the other vault to be disowned, got %q", text)
+	}
+	if input := messages[1].ToolCalls()[0].Input; strings.Contains(input, "hunter22") {
+		t.Errorf("expected the secret handed to store_credential withheld from the bundle, got %s", input)
+	}
+	if results, err := message.NewService(db.New(target)).Search(ctx, "scan", message.SearchOptions{}); err != nil || len(results) != 1 {
+		t.Fatalf("expected the imported message to be searchable, got %+v %v", results, err)
+	}
+	config, err := os.ReadFile(targetPaths.ConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"sk-ant-secret", "abc"} {
+		if strings.Contains(string(config), secret) {
+			t.Errorf("expected %q stripped from the bundled swarm.json:\n%s", secret, config)
+		}
+	}
+	if !strings.Contains(string(config), `"TOKEN="`) {
+		t.Errorf("expected the names of the environment variables to stay:\n%s", config)
+	}
+	if _, err := os.Stat(filepath.Join(targetPaths.EvidenceDir, "scan-"+manifest.Files[len(manifest.Files)-1].SHA256[:8]+".xml")); err != nil {
+		t.Errorf("expected the artifact beside the different one of the same name: %v", err)
+	}
+
+	_, again, err := Import(ctx, bytes.NewReader(archive.Bytes()), target, targetPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Sessions.Imported+again.Messages.Imported+again.Findings.Imported+again.Artifacts.Imported != 0 {
+		t.Fatalf("expected importing the bundle again to import nothing, got %+v", again)
+	}
+}
+
+func TestImportDamaged(t *testing.T) {
+	loot := memoryEntry(artifactsDir+"/loot.txt", []byte("admin:hunter2"))
+	manifest := Manifest{Version: Version, Files: []File{loot.File}}
+	// NOTE: the artifact is changed after the manifest was written.
+	loot.content = []byte("admin:hunter3")
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []entry{memoryEntry(ManifestFile, content), loot} {
+		if err := writeEntry(tw, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	paths := newTestPaths(t)
+	if _, _, err := Import(context.Background(), &archive, newTestDB(t, "target"), paths); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("expected the changed artifact to fail its checksum, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(paths.EvidenceDir, "loot.txt")); err == nil {
+		t.Error("expected nothing imported from a damaged bundle")
+	}
+}
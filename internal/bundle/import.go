@@ -0,0 +1,245 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+)
+
+// Count is how many rows or files of a bundle were imported, and how many were already there.
+type Count struct {
+	Imported int
+	Skipped  int
+}
+
+func (c *Count) add(rows int64) {
+	if rows > 0 {
+		c.Imported++
+	} else {
+		c.Skipped++
+	}
+}
+
+type Result struct {
+	Sessions       Count
+	Messages       Count
+	Hosts          Count
+	Ports          Count
+	Findings       Count
+	ReportSections Count
+	Artifacts      Count
+	// SetAside lists the files of the bundle written next to the different ones of the engagement rather than over them.
+	SetAside []string
+}
+
+// Import checks a bundle against its manifest and merges it into the engagement behind conn and paths. nothing is
+// imported unless every file of the bundle matches its checksum, and the rows are imported in a single transaction.
+func Import(ctx context.Context, r io.Reader, conn *sql.DB, paths Paths) (Manifest, Result, error) {
+	dir, err := os.MkdirTemp("", "tandem-bundle-")
+	if err != nil {
+		return Manifest{}, Result{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, err := extract(r, dir)
+	if err != nil {
+		return manifest, Result{}, err
+	}
+	schemaVersion, err := goose.GetDBVersion(conn)
+	if err != nil {
+		return manifest, Result{}, fmt.Errorf("failed to read the version of the database: %w", err)
+	}
+	if manifest.SchemaVersion > schemaVersion {
+		return manifest, Result{}, fmt.Errorf("the bundle was exported by a later version of tandem, %s, with database version %d, this one is at %d. upgrade tandem to import it", manifest.TandemVersion, manifest.SchemaVersion, schemaVersion)
+	}
+
+	var result Result
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return manifest, result, err
+	}
+	defer tx.Rollback()
+	if err := load(ctx, db.New(tx), dir, &result); err != nil {
+		return manifest, result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return manifest, result, err
+	}
+
+	for _, file := range manifest.Files {
+		extracted := filepath.Join(dir, filepath.FromSlash(file.Path))
+		switch {
+		case strings.HasPrefix(file.Path, artifactsDir+"/"):
+			target := filepath.Join(paths.EvidenceDir, filepath.FromSlash(strings.TrimPrefix(file.Path, artifactsDir+"/")))
+			written, err := place(extracted, target, file, uniqueName)
+			if err != nil {
+				return manifest, result, err
+			}
+			if written == "" {
+				result.Artifacts.Skipped++
+			} else {
+				result.Artifacts.Imported++
+			}
+		case file.Path == configFile:
+			written, err := place(extracted, paths.ConfigFile, file, bundledName)
+			if err != nil {
+				return manifest, result, err
+			}
+			if written != "" && written != paths.ConfigFile {
+				result.SetAside = append(result.SetAside, written)
+			}
+		case file.Path == roeFile:
+			written, err := place(extracted, paths.RoEFile, file, bundledName)
+			if err != nil {
+				return manifest, result, err
+			}
+			if written != "" && written != paths.RoEFile {
+				result.SetAside = append(result.SetAside, written)
+			}
+		}
+	}
+	return manifest, result, nil
+}
+
+// ReadManifest reads the manifest of a bundle without checking the rest of it.
+func ReadManifest(r io.Reader) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("not a tandem bundle: %w", err)
+	}
+	defer gz.Close()
+	return readManifest(tar.NewReader(gz))
+}
+
+func readManifest(tr *tar.Reader) (Manifest, error) {
+	header, err := tr.Next()
+	if err != nil || header.Name != ManifestFile {
+		return Manifest{}, errors.New("not a tandem bundle: it doesn't start with a manifest")
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("the manifest of the bundle is broken: %w", err)
+	}
+	if manifest.Version > Version {
+		return manifest, fmt.Errorf("the bundle is of version %d, this version of tandem reads up to %d. upgrade tandem to import it", manifest.Version, Version)
+	}
+	return manifest, nil
+}
+
+// extract unpacks a bundle into dir, checking every file against the manifest.
+func extract(r io.Reader, dir string) (Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("not a tandem bundle: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return manifest, err
+	}
+
+	expected := make(map[string]File, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if !validPath(file.Path) {
+			return manifest, fmt.Errorf("the manifest lists %q, which points outside of the bundle", file.Path)
+		}
+		expected[file.Path] = file
+	}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("the bundle is broken: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		file, ok := expected[header.Name]
+		if !ok {
+			return manifest, fmt.Errorf("%s isn't in the manifest of the bundle", header.Name)
+		}
+		delete(expected, header.Name)
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return manifest, err
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return manifest, err
+		}
+		hash := sha256.New()
+		size, err := io.Copy(io.MultiWriter(out, hash), io.LimitReader(tr, file.Size+1))
+		out.Close()
+		if err != nil {
+			return manifest, err
+		}
+		if size != file.Size || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+			return manifest, fmt.Errorf("%s doesn't match the checksum in the manifest, the bundle is damaged or was tampered with", header.Name)
+		}
+	}
+	for name := range expected {
+		return manifest, fmt.Errorf("%s is missing from the bundle", name)
+	}
+	return manifest, nil
+}
+
+// validPath keeps the files of a bundle from being extracted outside of the directory they're extracted to.
+func validPath(name string) bool {
+	return name != "" && name != ManifestFile && path.Clean(name) == name && !path.IsAbs(name) && !strings.HasPrefix(name, "../") && !strings.Contains(name, "\\")
+}
+
+// place copies an extracted file to target. an identical file already there is left alone and nothing is written,
+// a different one is kept and the file is written under the name rename gives it instead. it returns where the file
+// was written.
+func place(extracted, target string, file File, rename func(string, File) string) (string, error) {
+	if existing, err := hashFile(target); err == nil {
+		if existing.SHA256 == file.SHA256 {
+			return "", nil
+		}
+		target = rename(target, file)
+		if existing, err := hashFile(target); err == nil && existing.SHA256 == file.SHA256 {
+			return "", nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	content, err := os.ReadFile(extracted)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+	return target, os.WriteFile(target, content, 0o644)
+}
+
+// uniqueName names an artifact after its checksum when another file has its name, e.g. scan-1a2b3c4d.xml.
+func uniqueName(target string, file File) string {
+	ext := filepath.Ext(target)
+	return strings.TrimSuffix(target, ext) + "-" + file.SHA256[:8] + ext
+}
+
+// bundledName names the configuration of a bundle when the engagement has its own, e.g. swarm.bundle.json.
+func bundledName(target string, _ File) string {
+	ext := filepath.Ext(target)
+	return strings.TrimSuffix(target, ext) + ".bundle" + ext
+}
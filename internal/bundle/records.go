@@ -0,0 +1,402 @@
+package bundle
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/vault"
+)
+
+// the rows of the database as they're bundled, apart from the tables so that a bundle stays readable by a later
+// version. timestamps are kept as the database has them.
+
+type Session struct {
+	ID               string  `json:"id"`
+	ParentSessionID  string  `json:"parent_session_id,omitempty"`
+	ForkedFrom       string  `json:"forked_from,omitempty"`
+	SummaryMessageID string  `json:"summary_message_id,omitempty"`
+	Title            string  `json:"title"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+	CreatedAt        int64   `json:"created_at"`
+	UpdatedAt        int64   `json:"updated_at"`
+}
+
+type Message struct {
+	ID         string          `json:"id"`
+	SessionID  string          `json:"session_id"`
+	Role       string          `json:"role"`
+	Parts      json.RawMessage `json:"parts"`
+	Model      string          `json:"model,omitempty"`
+	CreatedAt  int64           `json:"created_at"`
+	UpdatedAt  int64           `json:"updated_at"`
+	FinishedAt int64           `json:"finished_at,omitempty"`
+}
+
+type Host struct {
+	ID        string `json:"id"`
+	Address   string `json:"address"`
+	Hostname  string `json:"hostname,omitempty"`
+	OS        string `json:"os,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Source    string `json:"source,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type Port struct {
+	HostID    string `json:"host_id"`
+	Port      int64  `json:"port"`
+	Protocol  string `json:"protocol"`
+	State     string `json:"state,omitempty"`
+	Service   string `json:"service,omitempty"`
+	Product   string `json:"product,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Source    string `json:"source,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type Finding struct {
+	ID          string          `json:"id"`
+	Fingerprint string          `json:"fingerprint"`
+	HostID      string          `json:"host_id,omitempty"`
+	Port        int64           `json:"port,omitempty"`
+	Protocol    string          `json:"protocol,omitempty"`
+	Location    string          `json:"location,omitempty"`
+	Title       string          `json:"title"`
+	Severity    string          `json:"severity"`
+	Description string          `json:"description,omitempty"`
+	Evidence    string          `json:"evidence,omitempty"`
+	Remediation string          `json:"remediation,omitempty"`
+	References  json.RawMessage `json:"references"`
+	Source      string          `json:"source,omitempty"`
+	CVSSVector  string          `json:"cvss_vector,omitempty"`
+	CVSSScore   float64         `json:"cvss_score,omitempty"`
+	CreatedAt   int64           `json:"created_at"`
+	UpdatedAt   int64           `json:"updated_at"`
+}
+
+type ReportSection struct {
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	Author    string `json:"author,omitempty"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type table struct {
+	name    string
+	rows    int
+	content []byte
+}
+
+// dump reads every table that goes into a bundle.
+func dump(ctx context.Context, q db.Querier) ([]table, error) {
+	var tables []table
+	add := func(name string, rows int, content []byte, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to export the %s: %w", name, err)
+		}
+		tables = append(tables, table{name: name, rows: rows, content: content})
+		return nil
+	}
+
+	dbSessions, err := q.ListAllSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, len(dbSessions))
+	for i, s := range dbSessions {
+		sessions[i] = Session{
+			ID:               s.ID,
+			ParentSessionID:  s.ParentSessionID.String,
+			ForkedFrom:       s.ForkedFrom.String,
+			SummaryMessageID: s.SummaryMessageID.String,
+			Title:            s.Title,
+			PromptTokens:     s.PromptTokens,
+			CompletionTokens: s.CompletionTokens,
+			Cost:             s.Cost,
+			CreatedAt:        s.CreatedAt,
+			UpdatedAt:        s.UpdatedAt,
+		}
+	}
+	content, err := writeNDJSON(sessions)
+	if err := add("sessions", len(sessions), content, err); err != nil {
+		return nil, err
+	}
+
+	dbMessages, err := q.ListAllMessages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, len(dbMessages))
+	for i, m := range dbMessages {
+		parts, err := withholdCredentials([]byte(m.Parts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to export message %s: %w", m.ID, err)
+		}
+		messages[i] = Message{
+			ID:         m.ID,
+			SessionID:  m.SessionID,
+			Role:       m.Role,
+			Parts:      json.RawMessage(parts),
+			Model:      m.Model.String,
+			CreatedAt:  m.CreatedAt,
+			UpdatedAt:  m.UpdatedAt,
+			FinishedAt: m.FinishedAt.Int64,
+		}
+	}
+	content, err = writeNDJSON(messages)
+	if err := add("messages", len(messages), content, err); err != nil {
+		return nil, err
+	}
+
+	dbHosts, err := q.ListHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]Host, len(dbHosts))
+	for i, h := range dbHosts {
+		hosts[i] = Host{ID: h.ID, Address: h.Address, Hostname: h.Hostname, OS: h.Os, Status: h.Status, Source: h.Source, CreatedAt: h.CreatedAt, UpdatedAt: h.UpdatedAt}
+	}
+	content, err = writeNDJSON(hosts)
+	if err := add("hosts", len(hosts), content, err); err != nil {
+		return nil, err
+	}
+
+	dbPorts, err := q.ListAllPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ports := make([]Port, len(dbPorts))
+	for i, p := range dbPorts {
+		ports[i] = Port{HostID: p.HostID, Port: p.Port, Protocol: p.Protocol, State: p.State, Service: p.Service, Product: p.Product, Version: p.Version, Source: p.Source, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt}
+	}
+	content, err = writeNDJSON(ports)
+	if err := add("ports", len(ports), content, err); err != nil {
+		return nil, err
+	}
+
+	dbFindings, err := q.ListFindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]Finding, len(dbFindings))
+	for i, f := range dbFindings {
+		findings[i] = Finding{
+			ID:          f.ID,
+			Fingerprint: f.Fingerprint,
+			HostID:      f.HostID.String,
+			Port:        f.Port,
+			Protocol:    f.Protocol,
+			Location:    f.Location,
+			Title:       f.Title,
+			Severity:    f.Severity,
+			Description: vault.Disown(f.Description),
+			Evidence:    vault.Disown(f.Evidence),
+			Remediation: f.Remediation,
+			References:  json.RawMessage(f.Refs),
+			Source:      f.Source,
+			CVSSVector:  f.CvssVector,
+			CVSSScore:   f.CvssScore,
+			CreatedAt:   f.CreatedAt,
+			UpdatedAt:   f.UpdatedAt,
+		}
+	}
+	content, err = writeNDJSON(findings)
+	if err := add("findings", len(findings), content, err); err != nil {
+		return nil, err
+	}
+
+	dbSections, err := q.ListReportSections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]ReportSection, len(dbSections))
+	for i, s := range dbSections {
+		sections[i] = ReportSection{Name: s.Name, Content: s.Content, Author: s.Author, UpdatedAt: s.UpdatedAt}
+	}
+	content, err = writeNDJSON(sections)
+	if err := add("report_sections", len(sections), content, err); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// load merges the tables of an extracted bundle into the database. the sessions, messages and findings already there
+// are kept: their IDs are UUIDs or the IDs of tool calls, so a row with the ID of one in the bundle is the same row,
+// imported before. the hosts are merged by address and the findings on them are fingerprinted again against the host
+// they were merged into, so that a finding both testers recorded is kept once.
+func load(ctx context.Context, q db.Querier, dir string, result *Result) error {
+	sessions, err := readNDJSON[Session](filepath.Join(dir, dataDir, "sessions.ndjson"))
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		n, err := q.ImportSession(ctx, db.ImportSessionParams{
+			ID:               s.ID,
+			ParentSessionID:  nullString(s.ParentSessionID),
+			Title:            s.Title,
+			PromptTokens:     s.PromptTokens,
+			CompletionTokens: s.CompletionTokens,
+			SummaryMessageID: nullString(s.SummaryMessageID),
+			Cost:             s.Cost,
+			CreatedAt:        s.CreatedAt,
+			UpdatedAt:        s.UpdatedAt,
+			ForkedFrom:       nullString(s.ForkedFrom),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import session %s: %w", s.ID, err)
+		}
+		result.Sessions.add(n)
+	}
+
+	messages, err := readNDJSON[Message](filepath.Join(dir, dataDir, "messages.ndjson"))
+	if err != nil {
+		return err
+	}
+	for _, m := range messages {
+		parts, err := withholdCredentials(m.Parts)
+		if err != nil {
+			return fmt.Errorf("failed to import message %s: %w", m.ID, err)
+		}
+		// NOTE: the handles in the history refer to the vault of the installation it comes from, not to this one.
+		n, err := q.ImportMessage(ctx, db.ImportMessageParams{
+			ID:         m.ID,
+			SessionID:  m.SessionID,
+			Role:       m.Role,
+			Parts:      vault.Disown(string(parts)),
+			Model:      nullString(m.Model),
+			CreatedAt:  m.CreatedAt,
+			UpdatedAt:  m.UpdatedAt,
+			FinishedAt: sql.NullInt64{Int64: m.FinishedAt, Valid: m.FinishedAt != 0},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import message %s: %w", m.ID, err)
+		}
+		// NOTE: the imported messages don't go through the message service, they are indexed for search here.
+		if n > 0 {
+			if err := q.IndexMessage(ctx, m.ID); err != nil {
+				return fmt.Errorf("failed to index message %s: %w", m.ID, err)
+			}
+		}
+		result.Messages.add(n)
+	}
+
+	hosts, err := readNDJSON[Host](filepath.Join(dir, dataDir, "hosts.ndjson"))
+	if err != nil {
+		return err
+	}
+	// NOTE: the host IDs of the bundle are mapped onto the hosts of the same address already in the inventory.
+	hostIDs := make(map[string]string, len(hosts))
+	for _, h := range hosts {
+		existing, err := q.GetHostByAddress(ctx, h.Address)
+		switch {
+		case err == nil:
+			hostIDs[h.ID] = existing.ID
+			result.Hosts.Skipped++
+			continue
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+		recorded, err := q.UpsertHost(ctx, db.UpsertHostParams{ID: h.ID, Address: h.Address, Hostname: h.Hostname, Os: h.OS, Status: h.Status, Source: h.Source})
+		if err != nil {
+			return fmt.Errorf("failed to import host %s: %w", h.Address, err)
+		}
+		hostIDs[h.ID] = recorded.ID
+		result.Hosts.Imported++
+	}
+
+	ports, err := readNDJSON[Port](filepath.Join(dir, dataDir, "ports.ndjson"))
+	if err != nil {
+		return err
+	}
+	for _, p := range ports {
+		hostID, ok := hostIDs[p.HostID]
+		if !ok {
+			continue
+		}
+		if _, err := q.UpsertPort(ctx, db.UpsertPortParams{
+			ID:       uuid.New().String(),
+			HostID:   hostID,
+			Port:     p.Port,
+			Protocol: p.Protocol,
+			State:    p.State,
+			Service:  p.Service,
+			Product:  p.Product,
+			Version:  p.Version,
+			Source:   p.Source,
+		}); err != nil {
+			return fmt.Errorf("failed to import port %d/%s: %w", p.Port, p.Protocol, err)
+		}
+		result.Ports.Imported++
+	}
+
+	findings, err := readNDJSON[Finding](filepath.Join(dir, dataDir, "findings.ndjson"))
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		hostID := hostIDs[f.HostID]
+		refs := string(f.References)
+		if refs == "" || refs == "null" {
+			refs = "[]"
+		}
+		n, err := q.ImportFinding(ctx, db.ImportFindingParams{
+			ID: f.ID,
+			Fingerprint: finding.Fingerprint(finding.Finding{
+				Source:   f.Source,
+				HostID:   hostID,
+				Port:     f.Port,
+				Protocol: f.Protocol,
+				Location: f.Location,
+				Title:    f.Title,
+			}),
+			HostID:      nullString(hostID),
+			Port:        f.Port,
+			Protocol:    f.Protocol,
+			Location:    f.Location,
+			Title:       f.Title,
+			Severity:    f.Severity,
+			Description: vault.Disown(f.Description),
+			Evidence:    vault.Disown(f.Evidence),
+			Remediation: f.Remediation,
+			Refs:        refs,
+			Source:      f.Source,
+			CvssVector:  f.CVSSVector,
+			CvssScore:   f.CVSSScore,
+			CreatedAt:   f.CreatedAt,
+			UpdatedAt:   f.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to import finding %q: %w", f.Title, err)
+		}
+		result.Findings.add(n)
+	}
+
+	sections, err := readNDJSON[ReportSection](filepath.Join(dir, dataDir, "report_sections.ndjson"))
+	if err != nil {
+		return err
+	}
+	for _, s := range sections {
+		// NOTE: the newer of two versions of a section wins.
+		n, err := q.ImportReportSection(ctx, db.ImportReportSectionParams{Name: s.Name, Content: s.Content, Author: s.Author, UpdatedAt: s.UpdatedAt})
+		if err != nil {
+			return fmt.Errorf("failed to import report section %s: %w", s.Name, err)
+		}
+		result.ReportSections.add(n)
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
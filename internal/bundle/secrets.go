@@ -0,0 +1,106 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/tools"
+)
+
+// secretKey matches the keys of swarm.json whose values are secrets.
+var secretKey = regexp.MustCompile(`(?i)(api_?key|token|secret|password|passphrase|credential)`)
+
+// StripSecrets blanks the API keys, tokens, passwords, headers and environment values of a swarm.json.
+func StripSecrets(config []byte) ([]byte, error) {
+	var value any
+	if err := json.Unmarshal(config, &value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(strip(value)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// strip walks a decoded swarm.json, the keys are kept so that the next tester sees what to fill in.
+func strip(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			switch {
+			case strings.EqualFold(key, "headers"):
+				if headers, ok := item.(map[string]any); ok {
+					for name := range headers {
+						headers[name] = ""
+					}
+					continue
+				}
+			case strings.EqualFold(key, "env"):
+				// NOTE: env of an MCP server holds KEY=VALUE pairs, the names stay.
+				if env, ok := item.([]any); ok {
+					for i, pair := range env {
+						if pair, ok := pair.(string); ok {
+							name, _, _ := strings.Cut(pair, "=")
+							env[i] = name + "="
+						}
+					}
+					continue
+				}
+			case secretKey.MatchString(key):
+				if _, ok := item.(string); ok {
+					value[key] = ""
+					continue
+				}
+			}
+			value[key] = strip(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = strip(item)
+		}
+	}
+	return value
+}
+
+// withholdCredentials withholds the secrets in the tool calls among the parts of a message, the ones handed to
+// store_credential before the agents kept the handles in their place. the vault isn't bundled and neither are the
+// secrets that went into it.
+func withholdCredentials(parts []byte) ([]byte, error) {
+	var wrapped []struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(parts, &wrapped); err != nil {
+		return nil, err
+	}
+	withheld := false
+	for i, part := range wrapped {
+		if part.Type != "tool_call" {
+			continue
+		}
+		var call message.ToolCall
+		if err := json.Unmarshal(part.Data, &call); err != nil {
+			return nil, err
+		}
+		input := tools.MaskInput(call.Name, call.Input)
+		if input == call.Input {
+			continue
+		}
+		call.Input = input
+		data, err := json.Marshal(call)
+		if err != nil {
+			return nil, err
+		}
+		wrapped[i].Data, withheld = data, true
+	}
+	if !withheld {
+		return parts, nil
+	}
+	return json.Marshal(wrapped)
+}
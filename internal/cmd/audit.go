@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/audit"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/db"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Verify and export the audit log of the tool executions",
+	Long: `Every tool the agents run, and every command with it, is appended to a hash-chained audit log along with its
+arguments, the executor and container it ran in, the agent and session behind it, when it started and finished, its
+exit code and the sha256 of its output. each entry hashes the one before it, so an entry edited, inserted or removed
+after the fact breaks the chain.`,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify [export.json]",
+	Short: "Check the audit log, or an export of it, for tampering",
+	Long: `Check the hash chain of the audit log of the engagement in the working directory. given an export, check its
+signature and the chain of its entries instead, and print the fingerprint of the key that signed it to compare with
+the one the export was handed over with.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var export audit.Export
+			if err := json.Unmarshal(data, &export); err != nil {
+				return fmt.Errorf("%s isn't an audit log export: %w", args[0], err)
+			}
+			if err := export.Verify(); err != nil {
+				return fmt.Errorf("%s failed verification: %w", args[0], err)
+			}
+			publicKey, _ := export.Key()
+			fmt.Printf("%s: %d entries, the chain and the signature are intact\nsigned by the key with fingerprint %s\n", args[0], len(export.Entries), audit.Fingerprint(publicKey))
+			return nil
+		}
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		count, err := audit.NewService(db.New(conn)).Verify(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("the audit log failed verification: %w", err)
+		}
+		fmt.Printf("%d entries, the chain is intact\n", count)
+		return nil
+	},
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a signed copy of the audit log to hand to the client",
+	Long: `Export the entries of the audit log started within --from and --to as JSON, signed with the ed25519 key of the
+engagement which is created in its data directory on first use. --from and --to take RFC 3339 timestamps, local times
+like "2025-10-18 14:00" or dates, a date given to --to includes the whole day. the client checks the export with
+tandem audit verify <file>, or with any ed25519 implementation, against the fingerprint printed here. the entries are
+chained in the order the calls finished, so the calls that finished among the exported ones are exported along with
+them, even when they started before --from, e.g. the subagent call that the commands of a task belong to.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+		from, _, err := parseAuditTime(fromFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		to, dateOnly, err := parseAuditTime(toFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		entries, err := audit.NewService(db.New(conn)).List(cmd.Context(), from, to)
+		if err != nil {
+			return fmt.Errorf("failed to read the audit log: %w", err)
+		}
+		key, err := audit.LoadKey(config.DataDir())
+		if err != nil {
+			return err
+		}
+		export, err := audit.NewExport(entries, config.Get().Engagement, from, to, key)
+		if err != nil {
+			return fmt.Errorf("failed to sign the export: %w", err)
+		}
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fingerprint := audit.Fingerprint(key.Public().(ed25519.PublicKey))
+		if output == "" {
+			fmt.Println(string(data))
+			fmt.Fprintf(os.Stderr, "%d entries signed by the key with fingerprint %s\n", len(entries), fingerprint)
+			return nil
+		}
+		if err := os.WriteFile(output, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%d entries exported to %s\nsigned by the key with fingerprint %s\n", len(entries), output, fingerprint)
+		return nil
+	},
+}
+
+// parseAuditTime reads the bounds of an export, it tells whether only a date was given.
+func parseAuditTime(value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, false, nil
+		}
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is neither an RFC 3339 timestamp, a local time nor a date", value)
+	}
+	return t, true, nil
+}
+
+func init() {
+	auditExportCmd.Flags().String("from", "", "Export the entries started at or after this time")
+	auditExportCmd.Flags().String("to", "", "Export the entries started before this time")
+	auditExportCmd.Flags().StringP("output", "o", "", "File to write the export to, stdout when it's not given")
+	auditCmd.AddCommand(auditVerifyCmd, auditExportCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/bundle"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/db"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Hand an engagement over to another tester in a single archive",
+	Long: `A bundle holds the sessions and their messages, the hosts and ports of the inventory, the findings, the report
+sections, the files of the evidence directory, the rules of engagement and the swarm.json of the engagement with its
+API keys, tokens, passwords, headers and environment values blanked. a manifest lists every file of it with its
+sha256, along with the version of the bundle and of the database it was exported from.
+
+the audit log and the vault aren't bundled: the hash chain of the audit log can't be merged into another one, hand it
+over with tandem audit export, and the credentials of the vault are encrypted with the passphrase of this
+installation. the secrets handed to store_credential are withheld from the messages, and the credential handles in an
+imported history are rewritten to {{foreign-cred:N}}, the terminal refuses to run commands with them.`,
+}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Pack the engagement into a bundle",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		engagement := config.EngagementID()
+		if output == "" {
+			output = fmt.Sprintf("%s-%s.tandem.tgz", engagement, time.Now().Format("20060102-150405"))
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		manifest, err := bundle.Export(cmd.Context(), f, conn, bundlePaths(), engagement)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+			return err
+		}
+		fmt.Printf("%s: %d sessions, %d messages, %d hosts, %d findings and %d artifacts\n", output,
+			manifest.Counts["sessions"], manifest.Counts["messages"], manifest.Counts["hosts"], manifest.Counts["findings"], manifest.Counts["artifacts"])
+		return nil
+	},
+}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Merge a bundle into the engagement in the working directory",
+	Long: `Check a bundle against the checksums of its manifest and merge it into the engagement in the working
+directory, nothing is imported from a damaged bundle. what the engagement already has is kept: sessions, messages and
+findings already imported are skipped, hosts are merged by address, and of two versions of a report section the newer
+wins. an artifact of the same name but different content is imported under a name suffixed with its checksum, and
+a swarm.json or rules of engagement different from the engagement's own are set aside as swarm.bundle.json or
+RoE.bundle.md to compare.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		manifest, result, err := bundle.Import(cmd.Context(), f, conn, bundlePaths())
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", args[0], err)
+		}
+		fmt.Printf("imported %s, exported %s by tandem %s\n", args[0], manifest.CreatedAt, manifest.TandemVersion)
+		for _, line := range []struct {
+			name  string
+			count bundle.Count
+		}{
+			{"sessions", result.Sessions},
+			{"messages", result.Messages},
+			{"hosts", result.Hosts},
+			{"ports", result.Ports},
+			{"findings", result.Findings},
+			{"report sections", result.ReportSections},
+			{"artifacts", result.Artifacts},
+		} {
+			fmt.Printf("  %-16s %d imported, %d already there\n", line.name, line.count.Imported, line.count.Skipped)
+		}
+		for _, path := range result.SetAside {
+			fmt.Printf("the engagement has its own, the bundled one was written to %s\n", path)
+		}
+		return nil
+	},
+}
+
+func bundlePaths() bundle.Paths {
+	return bundle.Paths{
+		EvidenceDir: config.EvidenceDir(),
+		ConfigFile:  config.LocalConfigFile(),
+		RoEFile:     config.RoEFile(),
+	}
+}
+
+func init() {
+	bundleExportCmd.Flags().StringP("output", "o", "", "File to write the bundle to, named after the engagement in the working directory by default")
+	bundleCmd.AddCommand(bundleExportCmd, bundleImportCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
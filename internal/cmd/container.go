@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/docker"
+)
+
+var containerCmd = &cobra.Command{
+	Use:   "container",
+	Short: "Manage the kali container of the current engagement",
+}
+
+var containerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of the engagement's image and container",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newContainerManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		status, err := manager.Status(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		imageState := "missing"
+		if status.ImageExists {
+			imageState = "available"
+		}
+		fmt.Printf("engagement: %s\n", status.EngagementID)
+		fmt.Printf("image:      %s (%s)\n", status.Image, imageState)
+		fmt.Printf("container:  %s\n", status.Name)
+		if status.ContainerID == "" {
+			fmt.Println("state:      not created")
+		} else {
+			fmt.Printf("id:         %.12s\n", status.ContainerID)
+			fmt.Printf("state:      %s\n", status.State)
+			fmt.Printf("created:    %s\n", status.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Printf("workspace:  %s -> %s\n", status.BindMount, docker.WorkspaceDir)
+		fmt.Printf("loot:       %s -> %s\n", manager.LootVolume(), docker.LootDir)
+
+		isolated, err := docker.NewPool(manager).List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(isolated) > 0 {
+			fmt.Println("isolated:")
+			for _, summary := range isolated {
+				fmt.Printf("  %.12s  %-8s  %s\n", summary.ID, summary.State, summary.Labels[docker.LabelPoolKey])
+			}
+		}
+		return nil
+	},
+}
+
+var containerRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the image from the KaliDockerfile and recreate the engagement container",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newContainerManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		containerID, err := manager.Rebuild(cmd.Context(), os.Stdout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("container %s (%.12s) is running\n", manager.ContainerName(), containerID)
+		return nil
+	},
+}
+
+var containerShellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Open an interactive shell in the engagement container",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newContainerManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		shell, _ := cmd.Flags().GetString("shell")
+		return manager.Shell(cmd.Context(), shell)
+	},
+}
+
+var containerDestroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Remove the engagement container and its isolated containers, the workspace on the host is kept",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newContainerManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := manager.Destroy(cmd.Context()); err != nil {
+			return err
+		}
+		if err := docker.NewPool(manager).ReleaseAll(cmd.Context()); err != nil {
+			return err
+		}
+		fmt.Printf("container %s removed\n", manager.ContainerName())
+		return nil
+	},
+}
+
+func newContainerManager(cmd *cobra.Command) (*docker.Manager, error) {
+	if err := loadConfig(cmd); err != nil {
+		return nil, err
+	}
+	if cmd.Context() == nil {
+		cmd.SetContext(context.Background())
+	}
+
+	cli, err := docker.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return docker.NewManager(cli), nil
+}
+
+func init() {
+	containerShellCmd.Flags().StringP("shell", "s", "/bin/bash", "Shell to run inside the container")
+
+	containerCmd.AddCommand(
+		containerStatusCmd,
+		containerRebuildCmd,
+		containerShellCmd,
+		containerDestroyCmd,
+	)
+	rootCmd.AddCommand(containerCmd)
+}
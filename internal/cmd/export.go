@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/export"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/report"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the data of the engagement for other tools",
+}
+
+var exportFindingsCmd = &cobra.Command{
+	Use:   "findings",
+	Short: "Export the findings as SARIF, JSON, CSV or NDJSON",
+	Long: `Export the findings of the engagement in the working directory from the most severe down, each with the asset
+it affects, its CVSS vector and score, its CWE and CVE IDs, its evidence along with the files of the evidence
+directory it refers to and their sha256, and its remediation.
+
+the json format is a document and the ndjson format one finding per line, both following the JSON schema printed by
+--schema. sarif is a SARIF 2.1.0 log with a rule per title and a result per finding, its security-severity is the
+CVSS score. csv has a row per finding, with the lists in its cells separated by semicolons.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		minSeverity, _ := cmd.Flags().GetString("min-severity")
+		schema, _ := cmd.Flags().GetBool("schema")
+		if schema {
+			_, err := os.Stdout.Write(export.Schema)
+			return err
+		}
+		if !slices.Contains(export.Formats, export.Format(format)) {
+			return fmt.Errorf("unknown format %q, use sarif, json, csv or ndjson", format)
+		}
+		minimum := finding.Severity(minSeverity)
+		if minimum != "" && minimum.Rank() < 0 {
+			return fmt.Errorf("unknown severity %q, use info, low, medium, high or critical", minSeverity)
+		}
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx := cmd.Context()
+		q := db.New(conn)
+		findings, err := finding.NewService(q).List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list the findings: %w", err)
+		}
+		findings = slices.DeleteFunc(findings, func(f finding.Finding) bool { return f.Severity.Rank() < minimum.Rank() })
+		hosts, err := inventory.NewService(q).ListHosts(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list the hosts: %w", err)
+		}
+		evidence, err := report.CollectEvidence(config.EvidenceDir())
+		if err != nil {
+			return fmt.Errorf("failed to read the evidence: %w", err)
+		}
+
+		document := export.NewDocument(export.Normalize(findings, hosts, evidence), config.Get().Engagement, time.Now())
+		var buf bytes.Buffer
+		if err := export.Write(&buf, export.Format(format), document); err != nil {
+			return err
+		}
+		if output == "" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%d findings exported to %s\n", len(document.Findings), output)
+		return nil
+	},
+}
+
+func init() {
+	exportFindingsCmd.Flags().StringP("format", "f", string(export.FormatJSON), "Format to export: sarif, json, csv or ndjson")
+	exportFindingsCmd.Flags().StringP("output", "o", "", "File to write the export to, stdout when it's not given")
+	exportFindingsCmd.Flags().String("min-severity", "", "Leave out the findings below this severity")
+	exportFindingsCmd.Flags().Bool("schema", false, "Print the JSON schema of the json and ndjson formats")
+	exportCmd.AddCommand(exportFindingsCmd)
+	rootCmd.AddCommand(exportCmd)
+}
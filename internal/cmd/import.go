@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/importer"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>...",
+	Short: "Import nessus, burp, nuclei, masscan, gobuster or nmap output into the engagement's inventory and findings",
+	Long: `Import the output of scanners run outside of tandem. the hosts and ports go into the asset inventory, the issues
+into the findings, and the orchestrator gets told about the new data on its next run.
+
+the format is detected from the file, pass --format when it can't be. gobuster's dir and vhost output only lists
+paths and names, so pass the URL it was run against with --target.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		format, _ := cmd.Flags().GetString("format")
+		target, _ := cmd.Flags().GetString("target")
+
+		q := db.New(conn)
+		imports := importer.NewService(q, inventory.NewService(q), finding.NewService(q))
+		opts := importer.Options{
+			Format: importer.Format(format),
+			Target: target,
+		}
+		for _, path := range args {
+			imported, err := imports.Import(cmd.Context(), path, opts)
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", path, err)
+			}
+			fmt.Printf("%s (%s): %d hosts, %d ports, %d findings\n", path, imported.Format, imported.Hosts, imported.Ports, imported.Findings)
+		}
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringP("format", "f", "", "Format of the files: nessus, burp, nuclei, masscan, gobuster or nmap")
+	importCmd.Flags().StringP("target", "t", "", "URL gobuster was run against")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/app"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/logging"
+	"github.com/yyovil/tandem/internal/mcpserver"
+	"github.com/yyovil/tandem/internal/tools"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Model Context Protocol integration",
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the engagement as an MCP server over stdio",
+	Long: `Serve the engagement in the working directory as an MCP server over stdin and stdout, so that an MCP client can
+drive it. the client gets the subagent tool to hand tasks to the agents of the swarm, the terminal tool which refuses
+commands naming targets outside of the scope, and the sessions and findings as tools and as resources.
+
+the subagents run with the config, executors and credential vault of the engagement just like they do from the TUI.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// NOTE: stdout carries the protocol, anything else printing to it would break the client's parser.
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		tools.InitMCPTools(ctx)
+		defer tools.CloseMCPServers()
+
+		app, err := app.New(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to create the app: %w", err)
+		}
+		// NOTE: ctx is gone by the time an interrupt stops the server.
+		defer app.Shutdown(context.WithoutCancel(ctx))
+		logging.Info("serving the engagement over mcp")
+		return mcpserver.New(app).Serve(ctx, os.Stdin, stdout)
+	},
+}
+
+func init() {
+	mcpCmd.AddCommand(mcpServeCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
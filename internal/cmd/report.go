@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/audit"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/report"
+	"github.com/yyovil/tandem/internal/session"
+)
+
+// maxAppendixInput bounds the arguments of a tool without a command shown in the appendix.
+const maxAppendixInput = 200
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Render the report of the engagement as markdown and standalone HTML",
+	Long: `Render the report of the engagement in the working directory: the executive summary, scope, methodology and
+attack narrative written by the reporter agent, the findings from the most severe down with their evidence and
+remediation, and appendices of the commands in the audit log, the evidence collected and the sessions.
+
+the report is rendered with the go templates report.md.tmpl and report.html.tmpl. to change the layout, copy the
+built-in ones with --dump-templates into the templates directory of the config, .tandem/templates by default, and
+edit them there.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formats, _ := cmd.Flags().GetStringSlice("format")
+		output, _ := cmd.Flags().GetString("output")
+		templates, _ := cmd.Flags().GetString("templates")
+		dump, _ := cmd.Flags().GetBool("dump-templates")
+		for _, format := range formats {
+			if !slices.Contains(report.Formats, report.Format(format)) {
+				return fmt.Errorf("unknown format %q, use md or html", format)
+			}
+		}
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		if templates == "" {
+			templates = config.ResolvePath(config.Get().Report.Templates)
+		}
+		if dump {
+			return dumpReportTemplates(templates)
+		}
+
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx := cmd.Context()
+		q := db.New(conn)
+		data, err := report.Collect(ctx, report.Sources{
+			Sections:  report.NewService(q),
+			Findings:  finding.NewService(q),
+			Sessions:  session.NewService(q),
+			Inventory: inventory.NewService(q),
+		})
+		if err != nil {
+			return err
+		}
+		entries, err := audit.NewService(q).List(ctx, time.Time{}, time.Time{})
+		if err != nil {
+			return fmt.Errorf("failed to read the audit log: %w", err)
+		}
+		for _, entry := range entries {
+			data.Commands = append(data.Commands, reportCommand(entry))
+		}
+
+		if output == "" {
+			output = filepath.Join(config.DataDir(), "reports")
+		}
+		if err := os.MkdirAll(output, 0o755); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("report-%s-%s", data.Engagement, data.GeneratedAt.Format("20060102-150405"))
+		for _, format := range formats {
+			var buf bytes.Buffer
+			if err := report.Render(&buf, report.Format(format), data, templates); err != nil {
+				return err
+			}
+			path := filepath.Join(output, name+"."+format)
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				return err
+			}
+			fmt.Println(path)
+		}
+		if missing := missingSections(data); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "not written yet: %s. ask the reporter agent to write them\n", strings.Join(missing, ", "))
+		}
+		return nil
+	},
+}
+
+func reportCommand(entry audit.Entry) report.Command {
+	command := entry.Command
+	if command == "" {
+		command = strings.Join(strings.Fields(entry.Input), " ")
+		if len(command) > maxAppendixInput {
+			command = command[:maxAppendixInput] + "..."
+		}
+	}
+	return report.Command{
+		Started:  time.UnixMilli(entry.StartedAt),
+		Agent:    entry.Agent,
+		Tool:     entry.Tool,
+		Command:  command,
+		ExitCode: entry.ExitCode,
+		IsError:  entry.IsError,
+	}
+}
+
+func missingSections(data report.Data) []string {
+	var missing []string
+	for _, section := range data.Sections {
+		// NOTE: the attack narrative and the conclusion are left out of the report when they aren't written.
+		if section.Content == "" && section.Name != report.SectionAttackNarrative && section.Name != report.SectionConclusion {
+			missing = append(missing, string(section.Name))
+		}
+	}
+	return missing
+}
+
+// dumpReportTemplates copies the built-in templates into dir, the ones already there are kept.
+func dumpReportTemplates(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, format := range report.Formats {
+		path := filepath.Join(dir, report.TemplateName(format))
+		if _, err := os.Stat(path); err == nil {
+			fmt.Printf("%s already exists, skipped\n", path)
+			continue
+		}
+		content, err := report.BuiltinTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+func init() {
+	reportCmd.Flags().StringSliceP("format", "f", []string{string(report.FormatMarkdown), string(report.FormatHTML)}, "Formats to render: md, html or both")
+	reportCmd.Flags().StringP("output", "o", "", "Directory to write the report to, the reports directory in the data directory by default")
+	reportCmd.Flags().String("templates", "", "Directory of the templates overriding the built-in ones, report.templates of the config by default")
+	reportCmd.Flags().Bool("dump-templates", false, "Copy the built-in templates into the templates directory to customize them")
+	rootCmd.AddCommand(reportCmd)
+}
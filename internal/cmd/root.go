@@ -16,6 +16,7 @@ import (
 	"github.com/yyovil/tandem/internal/format"
 	"github.com/yyovil/tandem/internal/logging"
 	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/tools"
 	"github.com/yyovil/tandem/internal/tui"
 	"github.com/yyovil/tandem/internal/version"
 )
@@ -35,8 +36,6 @@ var rootCmd = cobra.Command{
 		}
 
 		// Load the config
-		debug, _ := cmd.Flags().GetBool("debug")
-		cwd, _ := cmd.Flags().GetString("cwd")
 		prompt, _ := cmd.Flags().GetString("prompt")
 		outputFormat, _ := cmd.Flags().GetString("output-format")
 		quiet, _ := cmd.Flags().GetBool("quiet")
@@ -46,21 +45,7 @@ var rootCmd = cobra.Command{
 			return fmt.Errorf("invalid format option: %s\n%s", outputFormat, format.GetHelpText())
 		}
 
-		if cwd != "" {
-			err := os.Chdir(cwd)
-			if err != nil {
-				return fmt.Errorf("failed to change directory: %v", err)
-			}
-		}
-		if cwd == "" {
-			c, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("failed to get current working directory: %v", err)
-			}
-			cwd = c
-		}
-		_, err := config.Load(cwd, debug)
-		if err != nil {
+		if err := loadConfig(cmd); err != nil {
 			return err
 		}
 
@@ -74,14 +59,16 @@ var rootCmd = cobra.Command{
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		// Initialize MCP tools early for both modes, the agents pick theirs up by name
+		tools.InitMCPTools(ctx)
+		defer tools.CloseMCPServers()
+
 		app, err := app.New(ctx, conn)
 		if err != nil {
 			logging.Error("Failed to create app: %v", err)
 			return err
 		}
-
-		// Initialize MCP tools early for both modes
-		// initMCPTools(ctx, app)
+		defer app.Shutdown(ctx)
 
 		// Non-interactive mode
 		if prompt != "" {
@@ -165,6 +152,8 @@ func setupSubscriptions(app *app.App, parentCtx context.Context) (chan tea.Msg,
 	setupSubscriber(ctx, &wg, "sessions", app.Sessions.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "messages", app.Messages.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "orchestrator", app.Orchestrator.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "vault", app.Vault.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "findings", app.Findings.Subscribe, ch)
 
 	cleanupFunc := func() {
 		logging.Info("Cancelling all subscriptions")
@@ -229,6 +218,28 @@ func setupSubscriber[T any](
 	}()
 }
 
+// loadConfig changes into the --cwd directory and loads the swarm config from it.
+func loadConfig(cmd *cobra.Command) error {
+	debug, _ := cmd.Flags().GetBool("debug")
+	cwd, _ := cmd.Flags().GetString("cwd")
+
+	if cwd != "" {
+		err := os.Chdir(cwd)
+		if err != nil {
+			return fmt.Errorf("failed to change directory: %v", err)
+		}
+	}
+	if cwd == "" {
+		c, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		cwd = c
+	}
+	_, err := config.Load(cwd, debug)
+	return err
+}
+
 // attemptTUIRecovery tries to recover the TUI after a panic
 func attemptTUIRecovery(program *tea.Program) {
 	logging.Info("Attempting to recover TUI after panic")
@@ -248,8 +259,8 @@ func Execute() {
 func init() {
 	rootCmd.Flags().BoolP("help", "h", false, "Help")
 	rootCmd.Flags().BoolP("version", "v", false, "Version")
-	rootCmd.Flags().BoolP("debug", "d", false, "Debug")
-	rootCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+	rootCmd.PersistentFlags().BoolP("debug", "d", false, "Debug")
+	rootCmd.PersistentFlags().StringP("cwd", "c", "", "Current working directory")
 	rootCmd.Flags().StringP("prompt", "p", "", "Prompt to run in non-interactive mode")
 
 	// Add format flag with validation logic
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/message"
+	"golang.org/x/term"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the messages, tool calls and tool outputs of all sessions",
+	Long: `Search the messages of all sessions, the sessions of the subagents included, along with the inputs of the
+tool calls and the outputs of the tools, best match first. every word of the query has to match, words are matched
+regardless of their endings, "quoted phrases" match as a whole, a word ending in * matches as a prefix and OR
+matches either side of it, e.g.
+
+  tandem search "anonymous login" ftp
+  tandem search 10.10.10.5 smb*
+  tandem search sqlmap OR "sql injection"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		results, err := message.NewService(db.New(conn)).Search(cmd.Context(), strings.Join(args, " "), message.SearchOptions{Limit: limit})
+		if err != nil {
+			return fmt.Errorf("failed to search: %w", err)
+		}
+
+		if asJSON {
+			for i := range results {
+				results[i].Snippet = highlight(results[i].Snippet, "", "")
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(results)
+		}
+		if len(results) == 0 {
+			fmt.Println("no matches")
+			return nil
+		}
+		open, close := "[", "]"
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			open, close = "\x1b[1m", "\x1b[0m"
+		}
+		for _, r := range results {
+			fmt.Printf("%s  %s  %s\n", r.SessionID, time.Unix(r.CreatedAt, 0).Format("2006-01-02 15:04"), r.SessionTitle)
+			fmt.Printf("  %s: %s\n", searchSource(r), highlight(r.Snippet, open, close))
+		}
+		return nil
+	},
+}
+
+// searchSource names what a search result matched, e.g. assistant, or the output of nmap.
+func searchSource(r message.SearchResult) string {
+	switch r.Kind {
+	case "tool_call":
+		return r.Tool + " call"
+	case "tool_result":
+		return r.Tool + " output"
+	}
+	return string(r.Role)
+}
+
+// highlight puts the matches of a snippet between open and close, and folds it onto a single line.
+func highlight(snippet, open, close string) string {
+	snippet = strings.NewReplacer(message.MatchStart, open, message.MatchEnd, close).Replace(snippet)
+	return strings.Join(strings.Fields(snippet), " ")
+}
+
+func init() {
+	searchCmd.Flags().IntP("limit", "n", 20, "Number of matches to show")
+	searchCmd.Flags().Bool("json", false, "Print the matches as JSON")
+	rootCmd.AddCommand(searchCmd)
+}
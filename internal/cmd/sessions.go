@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/report"
+	"github.com/yyovil/tandem/internal/session"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List, fork and export the sessions of the engagement",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the sessions, the latest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		sessions, err := session.NewService(db.New(conn)).List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		for _, s := range sessions {
+			fork := ""
+			if s.ForkedFrom != "" {
+				fork = fmt.Sprintf("  (forked from %s)", s.ForkedFrom)
+			}
+			fmt.Printf("%s  %s  %4d messages  $%.2f  %s%s\n", s.ID, time.Unix(s.CreatedAt, 0).Format("2006-01-02 15:04"), s.MessageCount, s.Cost, s.Title, fork)
+		}
+		return nil
+	},
+}
+
+var sessionsForkCmd = &cobra.Command{
+	Use:   "fork <id>",
+	Short: "Start a new session with a copy of the history of a session",
+	Long: `Start a new session with a copy of the history of a session up to and including one of its messages, to try
+another path without touching the original. the whole history is copied unless --at names the message to fork at, the
+IDs of the messages are listed by tandem search --json. the new session is listed by tandem sessions list along with
+the session it was forked from.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		at, _ := cmd.Flags().GetString("at")
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		q := db.New(conn)
+		fork, err := session.Fork(cmd.Context(), session.NewService(q), message.NewService(q), args[0], at)
+		if err != nil {
+			return fmt.Errorf("failed to fork session %s: %w", args[0], err)
+		}
+		fmt.Printf("%s  %d messages  %s\n", fork.ID, fork.MessageCount, fork.Title)
+		return nil
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export the transcript of a session as markdown and standalone HTML",
+	Long: `Export the transcript of a session along with the sessions of the subagents it handed tasks to, and theirs in
+turn: every message with its time and model, the tool calls with their arguments and their outputs, and the tokens and
+cost of each session. the outputs are collapsed in the HTML transcript. the reasoning of the models is left out unless
+--reasoning is given. the IDs of the sessions are listed by tandem sessions list.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formats, _ := cmd.Flags().GetStringSlice("format")
+		output, _ := cmd.Flags().GetString("output")
+		reasoning, _ := cmd.Flags().GetBool("reasoning")
+		var selected []report.Format
+		for _, format := range formats {
+			if !slices.Contains(report.Formats, report.Format(format)) {
+				return fmt.Errorf("unknown format %q, use md or html", format)
+			}
+			selected = append(selected, report.Format(format))
+		}
+
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		q := db.New(conn)
+		transcript, err := report.CollectTranscript(cmd.Context(), session.NewService(q), message.NewService(q), args[0], reasoning)
+		if err != nil {
+			return fmt.Errorf("failed to read session %s: %w", args[0], err)
+		}
+		transcript.Engagement = config.Get().Engagement
+		if output == "" {
+			output = filepath.Join(config.DataDir(), "transcripts")
+		}
+		paths, err := report.WriteTranscript(transcript, selected, output, config.ResolvePath(config.Get().Report.Templates))
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		return err
+	},
+}
+
+func init() {
+	sessionsExportCmd.Flags().StringSliceP("format", "f", []string{string(report.FormatMarkdown), string(report.FormatHTML)}, "Formats to export: md, html or both")
+	sessionsExportCmd.Flags().StringP("output", "o", "", "Directory to write the transcripts to, the transcripts directory in the data directory by default")
+	sessionsExportCmd.Flags().Bool("reasoning", false, "Keep the reasoning of the models")
+	sessionsForkCmd.Flags().String("at", "", "ID of the message to fork at, the last message of the session by default")
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsForkCmd, sessionsExportCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
@@ -1,14 +1,20 @@
 package config
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/yyovil/tandem/internal/logging"
@@ -22,7 +28,12 @@ const (
 	defaultDataDirectory     = ".tandem/data"
 	defaultContextPath       = ".tandem/RoE.md"
 	configFileName           = "swarm"
+	defaultContainerImage    = "kali:headless"
+	defaultNVDDirectory      = ".tandem/data/nvd"
+	defaultExploitDB         = "/usr/share/exploitdb"
+	defaultReportTemplates   = ".tandem/templates"
 	MaxTokensFallbackDefault = 4096
+	defaultRedactionEntropy  = 4.5
 )
 
 var (
@@ -39,6 +50,17 @@ type Config struct {
 	Agents      map[AgentName]Agent               `json:"agents,omitempty"`
 	Debug       bool                              `json:"debug,omitempty"`
 	AutoCompact bool                              `json:"autoCompact,omitempty"`
+	Engagement  string                            `json:"engagement,omitempty"`
+	Container   Container                         `json:"container"`
+	Executors   map[string]Executor               `json:"executors,omitempty"`
+	Scope       Scope                             `json:"scope"`
+	HTTP        HTTP                              `json:"http"`
+	Research    Research                          `json:"research"`
+	MCPServers  map[string]MCPServer              `json:"mcpServers,omitempty"`
+	ScriptTools map[string]ScriptTool             `json:"scriptTools,omitempty"`
+	Redaction   Redaction                         `json:"redaction"`
+	Schedule    Schedule                          `json:"schedule"`
+	Report      Report                            `json:"report"`
 }
 
 // Global configuration instance
@@ -50,6 +72,164 @@ type Data struct {
 	Directory string `json:"directory,omitempty"`
 }
 
+// Container defines the kali container the agents execute their commands in.
+type Container struct {
+	Image      string         `json:"image,omitempty"`
+	Dockerfile string         `json:"dockerfile,omitempty"`
+	Privileged bool           `json:"privileged,omitempty"`
+	Isolation  IsolationLevel `json:"isolation,omitempty"`
+	CPUs       float64        `json:"cpus,omitempty"`
+	MemoryMB   int64          `json:"memoryMB,omitempty"`
+}
+
+// IsolationLevel decides which agents share an execution container.
+type IsolationLevel string
+
+const (
+	// IsolationShared runs every agent in the single engagement container.
+	IsolationShared IsolationLevel = "shared"
+	// IsolationAgent gives each agent a container of its own, reused across its tasks.
+	IsolationAgent IsolationLevel = "agent"
+	// IsolationSession gives each task session a container of its own, removed once the task is done.
+	IsolationSession IsolationLevel = "session"
+)
+
+// ExecutorType is the kind of backend the terminal tool runs commands on.
+type ExecutorType string
+
+const (
+	ExecutorDocker ExecutorType = "docker"
+	ExecutorPodman ExecutorType = "podman"
+	ExecutorLocal  ExecutorType = "local"
+	ExecutorSSH    ExecutorType = "ssh"
+)
+
+// Executor defines a named execution backend that agents can be pointed at.
+type Executor struct {
+	Type ExecutorType `json:"type"`
+	// Host is the daemon address for docker and podman, e.g. unix:///run/podman/podman.sock, and host:port for ssh.
+	Host string `json:"host,omitempty"`
+	// User, IdentityFile, KnownHosts and InsecureIgnoreHostKey only apply to ssh.
+	User                  string `json:"user,omitempty"`
+	IdentityFile          string `json:"identityFile,omitempty"`
+	KnownHosts            string `json:"knownHosts,omitempty"`
+	InsecureIgnoreHostKey bool   `json:"insecureIgnoreHostKey,omitempty"`
+	// WorkingDir is where the commands run for the local and ssh executors.
+	WorkingDir string `json:"workingDir,omitempty"`
+	Shell      string `json:"shell,omitempty"`
+}
+
+// Scope lists the targets the rules of engagement allow. entries are IPs, CIDRs, hostnames or *.domain wildcards,
+// an exclusion always wins over an inclusion.
+type Scope struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Schedule holds the testing windows of the rules of engagement, the tools only run within them.
+type Schedule struct {
+	// Timezone is the IANA name of the timezone the windows and blackouts are in, e.g. Europe/London, the local
+	// timezone when it's empty.
+	Timezone string `json:"timezone,omitempty"`
+	// Windows are the recurring hours testing is allowed in, any time is when there are none.
+	Windows []TimeWindow `json:"windows,omitempty"`
+	// Blackouts are periods testing isn't allowed in, even within a window.
+	Blackouts []Blackout `json:"blackouts,omitempty"`
+	// OnClosed decides what a tool call outside the windows does.
+	OnClosed ScheduleAction `json:"onClosed,omitempty"`
+}
+
+// TimeWindow is a recurring window, an end before the start runs past midnight.
+type TimeWindow struct {
+	Days  []string `json:"days,omitempty"` // mon to sun, every day when it's empty
+	Start string   `json:"start"`          // 15:04
+	End   string   `json:"end"`            // 15:04
+}
+
+// Blackout is a one-off period testing isn't allowed in, its bounds are like 2025-10-24 18:00 in the schedule's timezone.
+type Blackout struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ScheduleAction is what happens to a tool call outside the testing windows.
+type ScheduleAction string
+
+const (
+	// ScheduleWait pauses the agent until the next window opens and resumes it then.
+	ScheduleWait ScheduleAction = "wait"
+	// ScheduleNotify refuses the tool call and notifies the operator once the next window opens.
+	ScheduleNotify ScheduleAction = "notify"
+)
+
+// HTTP configures the http_request tool.
+type HTTP struct {
+	// Proxy is the URL of an intercepting proxy, e.g. http://127.0.0.1:8080 for burp, requests go through it when asked to.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// Research points the cve_lookup and exploit_search tools at locally mirrored vulnerability data.
+type Research struct {
+	// NVD is a directory of NVD JSON feeds, 1.1 or 2.0, gzipped or not.
+	NVD string `json:"nvd,omitempty"`
+	// ExploitDB is an exploitdb checkout or its files_exploits.csv.
+	ExploitDB string `json:"exploitdb,omitempty"`
+}
+
+// Report holds the settings of tandem report.
+type Report struct {
+	// Templates is a directory of report.md.tmpl and report.html.tmpl files overriding the built-in templates.
+	Templates string `json:"templates,omitempty"`
+}
+
+// MCPType is how tandem talks to an MCP server.
+type MCPType string
+
+const (
+	MCPStdio MCPType = "stdio"
+	MCPHTTP  MCPType = "http"
+	MCPSSE   MCPType = "sse"
+)
+
+// MCPServer is an external Model Context Protocol server whose tools are handed to the agents that list them.
+type MCPServer struct {
+	Type MCPType `json:"type,omitempty"`
+	// Command, Args and Env start a stdio server, Env holds KEY=VALUE pairs on top of the environment of tandem.
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	// URL and Headers reach an http or sse server.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ScriptTool is a tool declared in swarm.json, its command template runs on the executor of the agent calling it.
+type ScriptTool struct {
+	Description string `json:"description"`
+	// Parameters is the JSON schema of the arguments, an object whose properties the command refers to as {{name}}.
+	Parameters map[string]any `json:"parameters,omitempty"`
+	// Command is a shell command like enum4linux-ng -A {{target}}.
+	Command string `json:"command"`
+	Timeout int    `json:"timeout,omitempty"` // seconds
+}
+
+// Redaction decides which values are replaced with placeholders before the conversation is sent to the providers.
+type Redaction struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Detectors picks the built-in detectors by name, all of them when it's empty.
+	Detectors []string `json:"detectors,omitempty"`
+	// Patterns are detectors of their own, a regex per name. when it has a group named value only that is redacted.
+	Patterns map[string]string `json:"patterns,omitempty"`
+	// Entropy redacts the long tokens more random than it in bits per character, 0 turns it off.
+	Entropy          float64 `json:"entropy,omitempty"`
+	EntropyMinLength int     `json:"entropyMinLength,omitempty"`
+	// Allow holds regexes of values that are never redacted, e.g. the accounts the client handed over for testing.
+	Allow []string `json:"allow,omitempty"`
+	// Deny holds values that are always redacted, e.g. the name of the client.
+	Deny []string `json:"deny,omitempty"`
+}
+
 // Provider defines configuration for an LLM provider.
 type Provider struct {
 	APIKey   string `json:"apiKey"`
@@ -82,6 +262,7 @@ type Agent struct {
 	ReasoningEffort string         `json:"reasoningEffort,omitempty"` // For openai models low,medium,high
 	Instructions    []string       `json:"instructions"`
 	Tools           []string       `json:"tools,omitempty"`
+	Executor        string         `json:"executor,omitempty"`
 }
 
 // Get returns the current configuration.
@@ -120,6 +301,9 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	if err := viper.Unmarshal(cfg); err != nil {
 		return cfg, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := loadScriptToolParameters(workingDir); err != nil {
+		return cfg, err
+	}
 
 	defaultLevel := slog.LevelInfo
 	if cfg.Debug {
@@ -291,6 +475,109 @@ func WorkingDirectory() string {
 	return cfg.WorkingDir
 }
 
+// EngagementID returns the ID of the current engagement. unless configured explicitly, it is derived from the working directory so that every engagement directory gets its own container.
+func EngagementID() string {
+	if cfg == nil {
+		panic("config not loaded")
+	}
+	if cfg.Engagement != "" {
+		return cfg.Engagement
+	}
+	sum := sha256.Sum256([]byte(cfg.WorkingDir))
+	return fmt.Sprintf("%s-%s", sanitizeName(filepath.Base(cfg.WorkingDir)), hex.EncodeToString(sum[:])[:8])
+}
+
+// ExecutorFor returns the name and the definition of the executor the agent runs its commands on.
+// an agent can name an executor defined under executors or one of the executor types, it defaults to docker.
+func ExecutorFor(agentName AgentName) (string, Executor) {
+	if cfg == nil {
+		panic("config not loaded")
+	}
+	name := cfg.Agents[agentName].Executor
+	if name == "" {
+		name = string(ExecutorDocker)
+	}
+	if executor, ok := cfg.Executors[name]; ok {
+		return name, executor
+	}
+	return name, Executor{Type: ExecutorType(name)}
+}
+
+// BindMountPath returns the absolute host path that is bind mounted as the workspace of the container.
+func BindMountPath() string {
+	if cfg == nil {
+		panic("config not loaded")
+	}
+	bindMount := cfg.Data.BindMount
+	if bindMount == "" {
+		return cfg.WorkingDir
+	}
+	if !filepath.IsAbs(bindMount) {
+		bindMount = filepath.Join(cfg.WorkingDir, bindMount)
+	}
+	return filepath.Clean(bindMount)
+}
+
+// LocalConfigFile is the swarm.json of the engagement, the one in the .tandem directory of the working directory.
+func LocalConfigFile() string {
+	return filepath.Join(WorkingDirectory(), "."+appName, configFileName+".json")
+}
+
+// RoEFile is the absolute path of the rules of engagement, .tandem/RoE.md unless configured otherwise.
+func RoEFile() string {
+	return ResolvePath(cmp.Or(Get().RoEPath, defaultContextPath))
+}
+
+// EvidenceDir returns the absolute host path where the artifacts pulled out of the containers are kept.
+func EvidenceDir() string {
+	return filepath.Join(DataDir(), "evidence")
+}
+
+// DataDir is the absolute path of the data directory of the engagement.
+func DataDir() string {
+	if cfg == nil {
+		panic("config not loaded")
+	}
+	dataDir := cfg.Data.Directory
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(cfg.WorkingDir, dataDir)
+	}
+	return dataDir
+}
+
+// ResolvePath makes a path from the config absolute, relative paths are taken from the working directory.
+func ResolvePath(p string) string {
+	if cfg == nil {
+		panic("config not loaded")
+	}
+	if strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[2:])
+		}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(cfg.WorkingDir, p)
+	}
+	return filepath.Clean(p)
+}
+
+// sanitizeName keeps a string usable as a part of a container name.
+func sanitizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+	if sb.Len() == 0 {
+		return appName
+	}
+	return sb.String()
+}
+
 func configureViper() {
 	viper.SetConfigName(configFileName)
 	viper.SetConfigType("json")
@@ -306,6 +593,13 @@ func setDefaults(debug bool) {
 	viper.SetDefault("data.directory", defaultDataDirectory)
 	viper.SetDefault("contextPaths", defaultContextPath)
 	viper.SetDefault("autoCompact", true)
+	viper.SetDefault("container.image", defaultContainerImage)
+	viper.SetDefault("container.privileged", true)
+	viper.SetDefault("container.isolation", IsolationShared)
+	viper.SetDefault("research.nvd", defaultNVDDirectory)
+	viper.SetDefault("research.exploitdb", defaultExploitDB)
+	viper.SetDefault("redaction.entropy", defaultRedactionEntropy)
+	viper.SetDefault("report.templates", defaultReportTemplates)
 
 	// Set default shell from environment or fallback to /bin/bash
 	shellPath := os.Getenv("SHELL")
@@ -351,6 +645,37 @@ func mergeLocalConfig(workingDir string) {
 	}
 }
 
+// loadScriptToolParameters reads the parameter schemas of the script tools straight from the config files. viper
+// lowercases the keys of maps, which would turn minLength into minlength and rename the parameters.
+func loadScriptToolParameters(workingDir string) error {
+	files := []string{viper.ConfigFileUsed(), filepath.Join(workingDir, fmt.Sprintf(".%s", appName), configFileName+".json")}
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var raw struct {
+			ScriptTools map[string]struct {
+				Parameters map[string]any `json:"parameters"`
+			} `json:"scriptTools"`
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		for name, tool := range raw.ScriptTools {
+			name = strings.ToLower(name)
+			if definition, ok := cfg.ScriptTools[name]; ok {
+				definition.Parameters = tool.Parameters
+				cfg.ScriptTools[name] = definition
+			}
+		}
+	}
+	return nil
+}
+
 // TODO: update this for the swarm config.
 // setProviderDefaults configures LLM provider defaults based on provider provided by
 // environment variables and configuration file.
@@ -464,6 +789,115 @@ func Validate() error {
 		return fmt.Errorf("config not loaded")
 	}
 
+	// Validate container isolation
+	switch cfg.Container.Isolation {
+	case IsolationShared, IsolationAgent, IsolationSession:
+	case "":
+		cfg.Container.Isolation = IsolationShared
+	default:
+		logging.Warn("unknown container isolation, falling back to shared", "isolation", cfg.Container.Isolation)
+		cfg.Container.Isolation = IsolationShared
+	}
+
+	// Validate executors
+	for name, agent := range cfg.Agents {
+		if agent.Executor == "" {
+			continue
+		}
+		if _, ok := cfg.Executors[agent.Executor]; ok {
+			continue
+		}
+		switch ExecutorType(agent.Executor) {
+		case ExecutorDocker, ExecutorPodman, ExecutorLocal:
+		default:
+			logging.Warn("unknown executor configured, reverting to docker", "agent", name, "executor", agent.Executor)
+			agent.Executor = ""
+			cfg.Agents[name] = agent
+		}
+	}
+	for name, executor := range cfg.Executors {
+		switch executor.Type {
+		case ExecutorDocker, ExecutorPodman, ExecutorLocal:
+		case ExecutorSSH:
+			if executor.Host == "" {
+				return fmt.Errorf("executor %s: host is required for ssh", name)
+			}
+		default:
+			return fmt.Errorf("executor %s: unknown type %q", name, executor.Type)
+		}
+	}
+
+	// Validate the MCP servers
+	for name, server := range cfg.MCPServers {
+		if server.Type == "" {
+			server.Type = MCPStdio
+			if server.URL != "" {
+				server.Type = MCPHTTP
+			}
+		}
+		switch server.Type {
+		case MCPStdio:
+			if server.Command == "" {
+				return fmt.Errorf("mcp server %s: command is required for stdio", name)
+			}
+		case MCPHTTP, MCPSSE:
+			if u, err := url.Parse(server.URL); err != nil || u.Host == "" {
+				return fmt.Errorf("mcp server %s: url %q isn't a URL", name, server.URL)
+			}
+		default:
+			return fmt.Errorf("mcp server %s: unknown type %q", name, server.Type)
+		}
+		// NOTE: the server name prefixes the names of its tools, the providers only take these characters in them.
+		if strings.Trim(name, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") != "" {
+			return fmt.Errorf("mcp server %s: only letters, digits, - and _ are allowed in the name", name)
+		}
+		cfg.MCPServers[name] = server
+	}
+
+	// Validate the script tools
+	for name, tool := range cfg.ScriptTools {
+		if strings.TrimSpace(tool.Command) == "" {
+			return fmt.Errorf("script tool %s: command is required", name)
+		}
+		if strings.Trim(name, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") != "" {
+			return fmt.Errorf("script tool %s: only letters, digits, - and _ are allowed in the name", name)
+		}
+		if schemaType, ok := tool.Parameters["type"]; ok && schemaType != "object" {
+			return fmt.Errorf("script tool %s: the parameters have to be an object schema", name)
+		}
+	}
+
+	// Validate the schedule, the windows themselves are parsed along with it when the app starts
+	switch cfg.Schedule.OnClosed {
+	case "":
+		cfg.Schedule.OnClosed = ScheduleWait
+	case ScheduleWait, ScheduleNotify:
+	default:
+		return fmt.Errorf("schedule.onClosed: unknown action %q, expected wait or notify", cfg.Schedule.OnClosed)
+	}
+	if _, err := time.LoadLocation(cfg.Schedule.Timezone); err != nil {
+		return fmt.Errorf("schedule.timezone: %w", err)
+	}
+
+	// Validate the redaction rules
+	for name, pattern := range cfg.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("redaction pattern %s: %w", name, err)
+		}
+	}
+	for _, allowed := range cfg.Redaction.Allow {
+		if _, err := regexp.Compile(allowed); err != nil {
+			return fmt.Errorf("redaction allow rule %q: %w", allowed, err)
+		}
+	}
+
+	// Validate the http proxy
+	if cfg.HTTP.Proxy != "" {
+		if proxy, err := url.Parse(cfg.HTTP.Proxy); err != nil || proxy.Host == "" {
+			return fmt.Errorf("http.proxy %q isn't a URL", cfg.HTTP.Proxy)
+		}
+	}
+
 	// Validate agent models
 	for name, agent := range cfg.Agents {
 		if err := validateAgent(cfg, name, agent); err != nil {
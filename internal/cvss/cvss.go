@@ -0,0 +1,205 @@
+// Package cvss parses CVSS v3.0, v3.1 and v4.0 vectors and scores them the way the FIRST specifications do, so that
+// the severity of a finding follows from what it is rather than from how an agent describes it.
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+type Version string
+
+const (
+	V3_0 Version = "3.0"
+	V3_1 Version = "3.1"
+	V4_0 Version = "4.0"
+)
+
+// Rating is the qualitative severity of a score, the same for all versions.
+type Rating string
+
+const (
+	RatingNone     Rating = "None"
+	RatingLow      Rating = "Low"
+	RatingMedium   Rating = "Medium"
+	RatingHigh     Rating = "High"
+	RatingCritical Rating = "Critical"
+)
+
+// RatingOf maps a score onto its qualitative severity.
+func RatingOf(score float64) Rating {
+	switch {
+	case score >= 9:
+		return RatingCritical
+	case score >= 7:
+		return RatingHigh
+	case score >= 4:
+		return RatingMedium
+	case score > 0:
+		return RatingLow
+	}
+	return RatingNone
+}
+
+// notDefined is the value of the optional metrics left out of a vector.
+const notDefined = "X"
+
+type group int
+
+const (
+	groupBase group = iota
+	groupTemporal
+	groupEnvironmental
+	groupSupplemental
+)
+
+type metric struct {
+	name   string
+	values []string
+	group  group
+}
+
+// Vector is a parsed vector string. the zero value isn't a valid vector.
+type Vector struct {
+	Version Version
+	metrics map[string]string
+}
+
+// Parse reads a vector string like CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H. every base metric must be there, the
+// others are optional, and no metric may be repeated.
+func Parse(vector string) (Vector, error) {
+	vector = strings.TrimSpace(vector)
+	prefix, rest, ok := strings.Cut(vector, "/")
+	if !ok || !strings.HasPrefix(prefix, "CVSS:") {
+		return Vector{}, fmt.Errorf("%q doesn't start with CVSS:3.1/ or CVSS:4.0/", vector)
+	}
+	version := Version(strings.TrimPrefix(prefix, "CVSS:"))
+	definitions, ok := metricsOf(version)
+	if !ok {
+		return Vector{}, fmt.Errorf("unsupported CVSS version %q, use 3.1 or 4.0", version)
+	}
+
+	v := Vector{Version: version, metrics: make(map[string]string)}
+	for _, part := range strings.Split(rest, "/") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return Vector{}, fmt.Errorf("%q isn't a metric like AV:N", part)
+		}
+		i := slices.IndexFunc(definitions, func(m metric) bool { return m.name == name })
+		if i < 0 {
+			return Vector{}, fmt.Errorf("unknown CVSS v%s metric %q", version, name)
+		}
+		if _, repeated := v.metrics[name]; repeated {
+			return Vector{}, fmt.Errorf("metric %s is given twice", name)
+		}
+		if !slices.Contains(definitions[i].values, value) {
+			return Vector{}, fmt.Errorf("%q isn't a value of %s, use one of %s", value, name, strings.Join(definitions[i].values, ", "))
+		}
+		v.metrics[name] = value
+	}
+	var missing []string
+	for _, m := range definitions {
+		if _, ok := v.metrics[m.name]; m.group == groupBase && !ok {
+			missing = append(missing, m.name)
+		}
+	}
+	if len(missing) > 0 {
+		return Vector{}, fmt.Errorf("the base metrics %s are missing", strings.Join(missing, ", "))
+	}
+	return v, nil
+}
+
+// Validate reports whether a vector string parses.
+func Validate(vector string) error {
+	_, err := Parse(vector)
+	return err
+}
+
+// String returns the vector in the order of the specification, without the metrics left undefined.
+func (v Vector) String() string {
+	definitions, _ := metricsOf(v.Version)
+	parts := []string{"CVSS:" + string(v.Version)}
+	for _, m := range definitions {
+		if value, ok := v.metrics[m.name]; ok && value != notDefined {
+			parts = append(parts, m.name+":"+value)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Get returns the value of a metric, X when it isn't given.
+func (v Vector) Get(name string) string {
+	if value, ok := v.metrics[name]; ok {
+		return value
+	}
+	return notDefined
+}
+
+// defines reports whether any metric of a group has a value other than X.
+func (v Vector) defines(g group) bool {
+	definitions, _ := metricsOf(v.Version)
+	for _, m := range definitions {
+		if m.group == g && v.Get(m.name) != notDefined {
+			return true
+		}
+	}
+	return false
+}
+
+// BaseScore scores the base metrics alone.
+func (v Vector) BaseScore() float64 {
+	if v.Version == V4_0 {
+		return v.score4(groupBase)
+	}
+	return v.base3()
+}
+
+// TemporalScore adds the temporal metrics of v3, or the threat metrics of v4, to the base score.
+func (v Vector) TemporalScore() float64 {
+	if v.Version == V4_0 {
+		return v.score4(groupTemporal)
+	}
+	return v.temporal3()
+}
+
+// EnvironmentalScore scores the vector with all of its metrics.
+func (v Vector) EnvironmentalScore() float64 {
+	if v.Version == V4_0 {
+		return v.score4(groupEnvironmental)
+	}
+	return v.environmental3()
+}
+
+// Score is the most specific score of the vector: environmental when it has environmental metrics, temporal or
+// threat when it has those, otherwise base.
+func (v Vector) Score() float64 {
+	switch {
+	case v.defines(groupEnvironmental):
+		return v.EnvironmentalScore()
+	case v.defines(groupTemporal):
+		return v.TemporalScore()
+	}
+	return v.BaseScore()
+}
+
+// Rating is the qualitative severity of the score of the vector.
+func (v Vector) Rating() Rating {
+	return RatingOf(v.Score())
+}
+
+func metricsOf(version Version) ([]metric, bool) {
+	switch version {
+	case V3_0, V3_1:
+		return metrics3, true
+	case V4_0:
+		return metrics4, true
+	}
+	return nil, false
+}
+
+// round1 rounds to one decimal, the way the v4 calculator does.
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
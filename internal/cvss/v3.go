@@ -0,0 +1,132 @@
+package cvss
+
+import "math"
+
+var metrics3 = []metric{
+	{"AV", []string{"N", "A", "L", "P"}, groupBase},
+	{"AC", []string{"L", "H"}, groupBase},
+	{"PR", []string{"N", "L", "H"}, groupBase},
+	{"UI", []string{"N", "R"}, groupBase},
+	{"S", []string{"U", "C"}, groupBase},
+	{"C", []string{"H", "L", "N"}, groupBase},
+	{"I", []string{"H", "L", "N"}, groupBase},
+	{"A", []string{"H", "L", "N"}, groupBase},
+	{"E", []string{"X", "H", "F", "P", "U"}, groupTemporal},
+	{"RL", []string{"X", "U", "W", "T", "O"}, groupTemporal},
+	{"RC", []string{"X", "C", "R", "U"}, groupTemporal},
+	{"CR", []string{"X", "H", "M", "L"}, groupEnvironmental},
+	{"IR", []string{"X", "H", "M", "L"}, groupEnvironmental},
+	{"AR", []string{"X", "H", "M", "L"}, groupEnvironmental},
+	{"MAV", []string{"X", "N", "A", "L", "P"}, groupEnvironmental},
+	{"MAC", []string{"X", "L", "H"}, groupEnvironmental},
+	{"MPR", []string{"X", "N", "L", "H"}, groupEnvironmental},
+	{"MUI", []string{"X", "N", "R"}, groupEnvironmental},
+	{"MS", []string{"X", "U", "C"}, groupEnvironmental},
+	{"MC", []string{"X", "H", "L", "N"}, groupEnvironmental},
+	{"MI", []string{"X", "H", "L", "N"}, groupEnvironmental},
+	{"MA", []string{"X", "H", "L", "N"}, groupEnvironmental},
+}
+
+// weights3 are the weights of the v3.1 specification, section 7.4. the privileges required weigh more when the
+// scope changes, see privileges3.
+var weights3 = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	"E":  {"X": 1, "H": 1, "F": 0.97, "P": 0.94, "U": 0.91},
+	"RL": {"X": 1, "U": 1, "W": 0.97, "T": 0.96, "O": 0.95},
+	"RC": {"X": 1, "C": 1, "R": 0.96, "U": 0.92},
+	"CR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+	"IR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+	"AR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+}
+
+func privileges3(value string, scopeChanged bool) float64 {
+	switch value {
+	case "N":
+		return 0.85
+	case "L":
+		if scopeChanged {
+			return 0.68
+		}
+		return 0.62
+	}
+	if scopeChanged {
+		return 0.5
+	}
+	return 0.27
+}
+
+func (v Vector) base3() float64 {
+	changed := v.Get("S") == "C"
+	iss := 1 - (1-weights3["C"][v.Get("C")])*(1-weights3["I"][v.Get("I")])*(1-weights3["A"][v.Get("A")])
+	var impact float64
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	exploitability := 8.22 * weights3["AV"][v.Get("AV")] * weights3["AC"][v.Get("AC")] * privileges3(v.Get("PR"), changed) * weights3["UI"][v.Get("UI")]
+	if impact <= 0 {
+		return 0
+	}
+	if changed {
+		return roundup(math.Min(1.08*(impact+exploitability), 10))
+	}
+	return roundup(math.Min(impact+exploitability, 10))
+}
+
+func (v Vector) temporal3() float64 {
+	return roundup(v.base3() * v.temporalFactor3())
+}
+
+func (v Vector) temporalFactor3() float64 {
+	return weights3["E"][v.Get("E")] * weights3["RL"][v.Get("RL")] * weights3["RC"][v.Get("RC")]
+}
+
+// modified returns the value of the modified metric of a base metric, the base metric when it isn't modified.
+func (v Vector) modified(name string) string {
+	if value := v.Get("M" + name); value != notDefined {
+		return value
+	}
+	return v.Get(name)
+}
+
+func (v Vector) environmental3() float64 {
+	changed := v.modified("S") == "C"
+	miss := math.Min(1-
+		(1-weights3["CR"][v.Get("CR")]*weights3["C"][v.modified("C")])*
+			(1-weights3["IR"][v.Get("IR")]*weights3["I"][v.modified("I")])*
+			(1-weights3["AR"][v.Get("AR")]*weights3["A"][v.modified("A")]), 0.915)
+	var impact float64
+	switch {
+	// NOTE: v3.1 changed the modified impact of a changed scope, v3.0 uses the formula of the base score.
+	case changed && v.Version == V3_0:
+		impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss-0.02, 15)
+	case changed:
+		impact = 7.52*(miss-0.029) - 3.25*math.Pow(miss*0.9731-0.02, 13)
+	default:
+		impact = 6.42 * miss
+	}
+	exploitability := 8.22 * weights3["AV"][v.modified("AV")] * weights3["AC"][v.modified("AC")] * privileges3(v.modified("PR"), changed) * weights3["UI"][v.modified("UI")]
+	if impact <= 0 {
+		return 0
+	}
+	if changed {
+		return roundup(roundup(math.Min(1.08*(impact+exploitability), 10)) * v.temporalFactor3())
+	}
+	return roundup(roundup(math.Min(impact+exploitability, 10)) * v.temporalFactor3())
+}
+
+// roundup is the Roundup of the v3.1 specification, appendix A: the smallest number with one decimal that is equal
+// to or higher than its input, working around the floating point errors of a plain ceil.
+func roundup(x float64) float64 {
+	scaled := int64(math.Round(x * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
@@ -0,0 +1,320 @@
+package cvss
+
+import (
+	"math"
+	"strings"
+)
+
+var metrics4 = []metric{
+	{"AV", []string{"N", "A", "L", "P"}, groupBase},
+	{"AC", []string{"L", "H"}, groupBase},
+	{"AT", []string{"N", "P"}, groupBase},
+	{"PR", []string{"N", "L", "H"}, groupBase},
+	{"UI", []string{"N", "P", "A"}, groupBase},
+	{"VC", []string{"H", "L", "N"}, groupBase},
+	{"VI", []string{"H", "L", "N"}, groupBase},
+	{"VA", []string{"H", "L", "N"}, groupBase},
+	{"SC", []string{"H", "L", "N"}, groupBase},
+	{"SI", []string{"H", "L", "N"}, groupBase},
+	{"SA", []string{"H", "L", "N"}, groupBase},
+	{"E", []string{"X", "A", "P", "U"}, groupTemporal},
+	{"CR", []string{"X", "H", "M", "L"}, groupEnvironmental},
+	{"IR", []string{"X", "H", "M", "L"}, groupEnvironmental},
+	{"AR", []string{"X", "H", "M", "L"}, groupEnvironmental},
+	{"MAV", []string{"X", "N", "A", "L", "P"}, groupEnvironmental},
+	{"MAC", []string{"X", "L", "H"}, groupEnvironmental},
+	{"MAT", []string{"X", "N", "P"}, groupEnvironmental},
+	{"MPR", []string{"X", "N", "L", "H"}, groupEnvironmental},
+	{"MUI", []string{"X", "N", "P", "A"}, groupEnvironmental},
+	{"MVC", []string{"X", "H", "L", "N"}, groupEnvironmental},
+	{"MVI", []string{"X", "H", "L", "N"}, groupEnvironmental},
+	{"MVA", []string{"X", "H", "L", "N"}, groupEnvironmental},
+	{"MSC", []string{"X", "H", "L", "N"}, groupEnvironmental},
+	{"MSI", []string{"X", "S", "H", "L", "N"}, groupEnvironmental},
+	{"MSA", []string{"X", "S", "H", "L", "N"}, groupEnvironmental},
+	{"S", []string{"X", "N", "P"}, groupSupplemental},
+	{"AU", []string{"X", "N", "Y"}, groupSupplemental},
+	{"R", []string{"X", "A", "U", "I"}, groupSupplemental},
+	{"V", []string{"X", "D", "C"}, groupSupplemental},
+	{"RE", []string{"X", "L", "M", "H"}, groupSupplemental},
+	{"U", []string{"X", "Clear", "Green", "Amber", "Red"}, groupSupplemental},
+}
+
+// levels4 are the severity distances of the metric values used to place a vector within its macrovector, a lower
+// level is more severe.
+var levels4 = map[string]map[string]float64{
+	"AV": {"N": 0, "A": 0.1, "L": 0.2, "P": 0.3},
+	"PR": {"N": 0, "L": 0.1, "H": 0.2},
+	"UI": {"N": 0, "P": 0.1, "A": 0.2},
+	"AC": {"L": 0, "H": 0.1},
+	"AT": {"N": 0, "P": 0.1},
+	"VC": {"H": 0, "L": 0.1, "N": 0.2},
+	"VI": {"H": 0, "L": 0.1, "N": 0.2},
+	"VA": {"H": 0, "L": 0.1, "N": 0.2},
+	"SC": {"H": 0.1, "L": 0.2, "N": 0.3},
+	"SI": {"S": 0, "H": 0.1, "L": 0.2, "N": 0.3},
+	"SA": {"S": 0, "H": 0.1, "L": 0.2, "N": 0.3},
+	"CR": {"H": 0, "M": 0.1, "L": 0.2},
+	"IR": {"H": 0, "M": 0.1, "L": 0.2},
+	"AR": {"H": 0, "M": 0.1, "L": 0.2},
+}
+
+// maxComposed are the most severe vectors of each value of the equivalence classes, eq3 is keyed by eq3 and eq6.
+var maxComposed = struct {
+	eq1, eq2, eq4, eq5 map[int][]string
+	eq3                map[int]map[int][]string
+}{
+	eq1: map[int][]string{
+		0: {"AV:N/PR:N/UI:N"},
+		1: {"AV:A/PR:N/UI:N", "AV:N/PR:L/UI:N", "AV:N/PR:N/UI:P"},
+		2: {"AV:P/PR:N/UI:N", "AV:A/PR:L/UI:P"},
+	},
+	eq2: map[int][]string{
+		0: {"AC:L/AT:N"},
+		1: {"AC:H/AT:N", "AC:L/AT:P"},
+	},
+	eq3: map[int]map[int][]string{
+		0: {
+			0: {"VC:H/VI:H/VA:H/CR:H/IR:H/AR:H"},
+			1: {"VC:H/VI:H/VA:L/CR:M/IR:M/AR:H", "VC:H/VI:H/VA:H/CR:M/IR:M/AR:M"},
+		},
+		1: {
+			0: {"VC:L/VI:H/VA:H/CR:H/IR:H/AR:H", "VC:H/VI:L/VA:H/CR:H/IR:H/AR:H"},
+			1: {"VC:L/VI:H/VA:L/CR:H/IR:M/AR:H", "VC:L/VI:H/VA:H/CR:H/IR:M/AR:M", "VC:H/VI:L/VA:H/CR:M/IR:H/AR:M", "VC:H/VI:L/VA:L/CR:M/IR:H/AR:H", "VC:L/VI:L/VA:H/CR:H/IR:H/AR:M"},
+		},
+		2: {
+			1: {"VC:L/VI:L/VA:L/CR:H/IR:H/AR:H"},
+		},
+	},
+	eq4: map[int][]string{
+		0: {"SC:H/SI:S/SA:S"},
+		1: {"SC:H/SI:H/SA:H"},
+		2: {"SC:L/SI:L/SA:L"},
+	},
+	eq5: map[int][]string{
+		0: {"E:A"},
+		1: {"E:P"},
+		2: {"E:U"},
+	},
+}
+
+// maxSeverity is the depth of each equivalence class in steps of 0.1, i.e. how far the least severe vector of a
+// macrovector is from its most severe one.
+var maxSeverity = struct {
+	eq1, eq2, eq4 map[int]float64
+	eq3eq6        map[int]map[int]float64
+}{
+	eq1:    map[int]float64{0: 1, 1: 4, 2: 5},
+	eq2:    map[int]float64{0: 1, 1: 2},
+	eq3eq6: map[int]map[int]float64{0: {0: 7, 1: 6}, 1: {0: 8, 1: 8}, 2: {1: 10}},
+	eq4:    map[int]float64{0: 6, 1: 5, 2: 4},
+}
+
+// effective4 returns the value of a metric that goes into the score: the modified metric wins over the base one,
+// and the threat and security requirements left undefined assume the worst case. metrics of the groups beyond the
+// one scored are treated as undefined.
+func (v Vector) effective4(name string, upto group) string {
+	get := func(name string, g group) string {
+		if g > upto {
+			return notDefined
+		}
+		return v.Get(name)
+	}
+	switch name {
+	case "E":
+		if value := get("E", groupTemporal); value != notDefined {
+			return value
+		}
+		return "A"
+	case "CR", "IR", "AR":
+		if value := get(name, groupEnvironmental); value != notDefined {
+			return value
+		}
+		return "H"
+	}
+	if value := get("M"+name, groupEnvironmental); value != notDefined {
+		return value
+	}
+	return v.Get(name)
+}
+
+// macrovector returns the equivalence classes eq1 to eq6 of the vector, section 8.2 of the v4.0 specification.
+func (v Vector) macrovector(upto group) [6]int {
+	m := func(name string) string { return v.effective4(name, upto) }
+	var eq [6]int
+
+	switch {
+	case m("AV") == "N" && m("PR") == "N" && m("UI") == "N":
+		eq[0] = 0
+	case (m("AV") == "N" || m("PR") == "N" || m("UI") == "N") && m("AV") != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+
+	if m("AC") == "L" && m("AT") == "N" {
+		eq[1] = 0
+	} else {
+		eq[1] = 1
+	}
+
+	switch {
+	case m("VC") == "H" && m("VI") == "H":
+		eq[2] = 0
+	case m("VC") == "H" || m("VI") == "H" || m("VA") == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+
+	switch {
+	case m("SI") == "S" || m("SA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+
+	switch m("E") {
+	case "A":
+		eq[4] = 0
+	case "P":
+		eq[4] = 1
+	default:
+		eq[4] = 2
+	}
+
+	if (m("CR") == "H" && m("VC") == "H") || (m("IR") == "H" && m("VI") == "H") || (m("AR") == "H" && m("VA") == "H") {
+		eq[5] = 0
+	} else {
+		eq[5] = 1
+	}
+	return eq
+}
+
+// score4 scores the vector with the metrics up to a group, section 8.3 of the v4.0 specification: the score of the
+// macrovector, lowered by how far the vector is from the most severe vector of the macrovector relative to the
+// distance to the next lower macrovectors.
+func (v Vector) score4(upto group) float64 {
+	m := func(name string) string { return v.effective4(name, upto) }
+	if m("VC") == "N" && m("VI") == "N" && m("VA") == "N" && m("SC") == "N" && m("SI") == "N" && m("SA") == "N" {
+		return 0
+	}
+
+	eq := v.macrovector(upto)
+	value := lookup4(eq)
+
+	lower := func(i int) float64 {
+		next := eq
+		next[i]++
+		return lookup4(next)
+	}
+	lowerEQ1, lowerEQ2, lowerEQ4, lowerEQ5 := lower(0), lower(1), lower(3), lower(4)
+
+	// NOTE: eq3 and eq6 are scored together, their lower macrovectors depend on both.
+	var lowerEQ3EQ6 float64
+	switch eq3, eq6 := eq[2], eq[5]; {
+	case eq3 == 0 && eq6 == 0:
+		left, right := eq, eq
+		left[5]++
+		right[2]++
+		lowerEQ3EQ6 = math.Max(nanAsLowest(lookup4(left)), nanAsLowest(lookup4(right)))
+		if math.IsInf(lowerEQ3EQ6, -1) {
+			lowerEQ3EQ6 = math.NaN()
+		}
+	case eq3 == 1 && eq6 == 0:
+		next := eq
+		next[5]++
+		lowerEQ3EQ6 = lookup4(next)
+	default:
+		next := eq
+		next[2]++
+		lowerEQ3EQ6 = lookup4(next)
+	}
+
+	// the first of the most severe vectors of the macrovector the vector isn't more severe than in any metric.
+	var distances map[string]float64
+	for _, eq1 := range maxComposed.eq1[eq[0]] {
+		for _, eq2 := range maxComposed.eq2[eq[1]] {
+			for _, eq3eq6 := range maxComposed.eq3[eq[2]][eq[5]] {
+				for _, eq4 := range maxComposed.eq4[eq[3]] {
+					for _, eq5 := range maxComposed.eq5[eq[4]] {
+						if distances != nil {
+							continue
+						}
+						candidate := parseMetrics(strings.Join([]string{eq1, eq2, eq3eq6, eq4, eq5}, "/"))
+						current := make(map[string]float64, len(levels4))
+						valid := true
+						for name, level := range levels4 {
+							current[name] = level[m(name)] - level[candidate[name]]
+							if current[name] < 0 {
+								valid = false
+							}
+						}
+						if valid {
+							distances = current
+						}
+					}
+				}
+			}
+		}
+	}
+	if distances == nil {
+		distances = map[string]float64{}
+	}
+
+	const step = 0.1
+	classes := []struct {
+		lower    float64
+		distance float64
+		depth    float64
+	}{
+		{lowerEQ1, distances["AV"] + distances["PR"] + distances["UI"], maxSeverity.eq1[eq[0]] * step},
+		{lowerEQ2, distances["AC"] + distances["AT"], maxSeverity.eq2[eq[1]] * step},
+		{lowerEQ3EQ6, distances["VC"] + distances["VI"] + distances["VA"] + distances["CR"] + distances["IR"] + distances["AR"], maxSeverity.eq3eq6[eq[2]][eq[5]] * step},
+		{lowerEQ4, distances["SC"] + distances["SI"] + distances["SA"], maxSeverity.eq4[eq[3]] * step},
+		// NOTE: the exploit maturity has a single value per macrovector, it never moves a vector within one.
+		{lowerEQ5, 0, step},
+	}
+	var existing int
+	var total float64
+	for _, class := range classes {
+		if math.IsNaN(class.lower) {
+			continue
+		}
+		existing++
+		total += (value - class.lower) * (class.distance / class.depth)
+	}
+	if existing > 0 {
+		value -= total / float64(existing)
+	}
+	return round1(math.Min(math.Max(value, 0), 10))
+}
+
+func lookup4(eq [6]int) float64 {
+	key := [6]byte{}
+	for i, class := range eq {
+		key[i] = byte('0' + class)
+	}
+	if score, ok := macrovectorScores[string(key[:])]; ok {
+		return score
+	}
+	return math.NaN()
+}
+
+func nanAsLowest(x float64) float64 {
+	if math.IsNaN(x) {
+		return math.Inf(-1)
+	}
+	return x
+}
+
+func parseMetrics(vector string) map[string]string {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		name, value, _ := strings.Cut(part, ":")
+		metrics[name] = value
+	}
+	return metrics
+}
@@ -24,44 +24,161 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.createAuditEntryStmt, err = db.PrepareContext(ctx, createAuditEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAuditEntry: %w", err)
+	}
+	if q.createCredentialStmt, err = db.PrepareContext(ctx, createCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCredential: %w", err)
+	}
+	if q.createImportStmt, err = db.PrepareContext(ctx, createImport); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateImport: %w", err)
+	}
 	if q.createMessageStmt, err = db.PrepareContext(ctx, createMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMessage: %w", err)
 	}
 	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
 	}
+	if q.createVaultStmt, err = db.PrepareContext(ctx, createVault); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateVault: %w", err)
+	}
+	if q.deleteFindingStmt, err = db.PrepareContext(ctx, deleteFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFinding: %w", err)
+	}
 	if q.deleteMessageStmt, err = db.PrepareContext(ctx, deleteMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMessage: %w", err)
 	}
+	if q.deleteMessageSearchStmt, err = db.PrepareContext(ctx, deleteMessageSearch); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMessageSearch: %w", err)
+	}
 	if q.deleteSessionStmt, err = db.PrepareContext(ctx, deleteSession); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSession: %w", err)
 	}
 	if q.deleteSessionMessagesStmt, err = db.PrepareContext(ctx, deleteSessionMessages); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteSessionMessages: %w", err)
 	}
+	if q.getCredentialStmt, err = db.PrepareContext(ctx, getCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCredential: %w", err)
+	}
+	if q.getFindingStmt, err = db.PrepareContext(ctx, getFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFinding: %w", err)
+	}
+	if q.getHostByAddressStmt, err = db.PrepareContext(ctx, getHostByAddress); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHostByAddress: %w", err)
+	}
+	if q.getLastAuditEntryStmt, err = db.PrepareContext(ctx, getLastAuditEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastAuditEntry: %w", err)
+	}
 	if q.getMessageStmt, err = db.PrepareContext(ctx, getMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMessage: %w", err)
 	}
 	if q.getSessionByIDStmt, err = db.PrepareContext(ctx, getSessionByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSessionByID: %w", err)
 	}
+	if q.getVaultStmt, err = db.PrepareContext(ctx, getVault); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVault: %w", err)
+	}
+	if q.importFindingStmt, err = db.PrepareContext(ctx, importFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportFinding: %w", err)
+	}
+	if q.importMessageStmt, err = db.PrepareContext(ctx, importMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportMessage: %w", err)
+	}
+	if q.importReportSectionStmt, err = db.PrepareContext(ctx, importReportSection); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportReportSection: %w", err)
+	}
+	if q.importSessionStmt, err = db.PrepareContext(ctx, importSession); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportSession: %w", err)
+	}
+	if q.indexMessageStmt, err = db.PrepareContext(ctx, indexMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query IndexMessage: %w", err)
+	}
+	if q.insertMessageSearchStmt, err = db.PrepareContext(ctx, insertMessageSearch); err != nil {
+		return nil, fmt.Errorf("error preparing query InsertMessageSearch: %w", err)
+	}
+	if q.listAllMessagesStmt, err = db.PrepareContext(ctx, listAllMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAllMessages: %w", err)
+	}
+	if q.listAllPortsStmt, err = db.PrepareContext(ctx, listAllPorts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAllPorts: %w", err)
+	}
+	if q.listAllSessionsStmt, err = db.PrepareContext(ctx, listAllSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAllSessions: %w", err)
+	}
+	if q.listAuditEntriesStmt, err = db.PrepareContext(ctx, listAuditEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditEntries: %w", err)
+	}
+	if q.listAuditEntriesBetweenStmt, err = db.PrepareContext(ctx, listAuditEntriesBetween); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditEntriesBetween: %w", err)
+	}
+	if q.listCredentialsStmt, err = db.PrepareContext(ctx, listCredentials); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCredentials: %w", err)
+	}
+	if q.listFindingsStmt, err = db.PrepareContext(ctx, listFindings); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFindings: %w", err)
+	}
+	if q.listHostsStmt, err = db.PrepareContext(ctx, listHosts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListHosts: %w", err)
+	}
 	if q.listMessagesBySessionStmt, err = db.PrepareContext(ctx, listMessagesBySession); err != nil {
 		return nil, fmt.Errorf("error preparing query ListMessagesBySession: %w", err)
 	}
+	if q.listPendingImportsStmt, err = db.PrepareContext(ctx, listPendingImports); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPendingImports: %w", err)
+	}
+	if q.listPortsByHostStmt, err = db.PrepareContext(ctx, listPortsByHost); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPortsByHost: %w", err)
+	}
+	if q.listReportSectionsStmt, err = db.PrepareContext(ctx, listReportSections); err != nil {
+		return nil, fmt.Errorf("error preparing query ListReportSections: %w", err)
+	}
 	if q.listSessionsStmt, err = db.PrepareContext(ctx, listSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListSessions: %w", err)
 	}
+	if q.markImportNotifiedStmt, err = db.PrepareContext(ctx, markImportNotified); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkImportNotified: %w", err)
+	}
+	if q.searchMessagesStmt, err = db.PrepareContext(ctx, searchMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchMessages: %w", err)
+	}
 	if q.updateMessageStmt, err = db.PrepareContext(ctx, updateMessage); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateMessage: %w", err)
 	}
 	if q.updateSessionStmt, err = db.PrepareContext(ctx, updateSession); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateSession: %w", err)
 	}
+	if q.upsertFindingStmt, err = db.PrepareContext(ctx, upsertFinding); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertFinding: %w", err)
+	}
+	if q.upsertHostStmt, err = db.PrepareContext(ctx, upsertHost); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertHost: %w", err)
+	}
+	if q.upsertPortStmt, err = db.PrepareContext(ctx, upsertPort); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPort: %w", err)
+	}
+	if q.upsertReportSectionStmt, err = db.PrepareContext(ctx, upsertReportSection); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertReportSection: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.createAuditEntryStmt != nil {
+		if cerr := q.createAuditEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAuditEntryStmt: %w", cerr)
+		}
+	}
+	if q.createCredentialStmt != nil {
+		if cerr := q.createCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCredentialStmt: %w", cerr)
+		}
+	}
+	if q.createImportStmt != nil {
+		if cerr := q.createImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createImportStmt: %w", cerr)
+		}
+	}
 	if q.createMessageStmt != nil {
 		if cerr := q.createMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createMessageStmt: %w", cerr)
@@ -72,11 +189,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
 		}
 	}
+	if q.createVaultStmt != nil {
+		if cerr := q.createVaultStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createVaultStmt: %w", cerr)
+		}
+	}
+	if q.deleteFindingStmt != nil {
+		if cerr := q.deleteFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFindingStmt: %w", cerr)
+		}
+	}
 	if q.deleteMessageStmt != nil {
 		if cerr := q.deleteMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteMessageStmt: %w", cerr)
 		}
 	}
+	if q.deleteMessageSearchStmt != nil {
+		if cerr := q.deleteMessageSearchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMessageSearchStmt: %w", cerr)
+		}
+	}
 	if q.deleteSessionStmt != nil {
 		if cerr := q.deleteSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteSessionStmt: %w", cerr)
@@ -87,6 +219,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteSessionMessagesStmt: %w", cerr)
 		}
 	}
+	if q.getCredentialStmt != nil {
+		if cerr := q.getCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCredentialStmt: %w", cerr)
+		}
+	}
+	if q.getFindingStmt != nil {
+		if cerr := q.getFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFindingStmt: %w", cerr)
+		}
+	}
+	if q.getHostByAddressStmt != nil {
+		if cerr := q.getHostByAddressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHostByAddressStmt: %w", cerr)
+		}
+	}
+	if q.getLastAuditEntryStmt != nil {
+		if cerr := q.getLastAuditEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastAuditEntryStmt: %w", cerr)
+		}
+	}
 	if q.getMessageStmt != nil {
 		if cerr := q.getMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMessageStmt: %w", cerr)
@@ -97,16 +249,116 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getSessionByIDStmt: %w", cerr)
 		}
 	}
+	if q.getVaultStmt != nil {
+		if cerr := q.getVaultStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVaultStmt: %w", cerr)
+		}
+	}
+	if q.importFindingStmt != nil {
+		if cerr := q.importFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importFindingStmt: %w", cerr)
+		}
+	}
+	if q.importMessageStmt != nil {
+		if cerr := q.importMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importMessageStmt: %w", cerr)
+		}
+	}
+	if q.importReportSectionStmt != nil {
+		if cerr := q.importReportSectionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importReportSectionStmt: %w", cerr)
+		}
+	}
+	if q.importSessionStmt != nil {
+		if cerr := q.importSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importSessionStmt: %w", cerr)
+		}
+	}
+	if q.indexMessageStmt != nil {
+		if cerr := q.indexMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing indexMessageStmt: %w", cerr)
+		}
+	}
+	if q.insertMessageSearchStmt != nil {
+		if cerr := q.insertMessageSearchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing insertMessageSearchStmt: %w", cerr)
+		}
+	}
+	if q.listAllMessagesStmt != nil {
+		if cerr := q.listAllMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAllMessagesStmt: %w", cerr)
+		}
+	}
+	if q.listAllPortsStmt != nil {
+		if cerr := q.listAllPortsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAllPortsStmt: %w", cerr)
+		}
+	}
+	if q.listAllSessionsStmt != nil {
+		if cerr := q.listAllSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAllSessionsStmt: %w", cerr)
+		}
+	}
+	if q.listAuditEntriesStmt != nil {
+		if cerr := q.listAuditEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listAuditEntriesBetweenStmt != nil {
+		if cerr := q.listAuditEntriesBetweenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditEntriesBetweenStmt: %w", cerr)
+		}
+	}
+	if q.listCredentialsStmt != nil {
+		if cerr := q.listCredentialsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCredentialsStmt: %w", cerr)
+		}
+	}
+	if q.listFindingsStmt != nil {
+		if cerr := q.listFindingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFindingsStmt: %w", cerr)
+		}
+	}
+	if q.listHostsStmt != nil {
+		if cerr := q.listHostsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listHostsStmt: %w", cerr)
+		}
+	}
 	if q.listMessagesBySessionStmt != nil {
 		if cerr := q.listMessagesBySessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listMessagesBySessionStmt: %w", cerr)
 		}
 	}
+	if q.listPendingImportsStmt != nil {
+		if cerr := q.listPendingImportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPendingImportsStmt: %w", cerr)
+		}
+	}
+	if q.listPortsByHostStmt != nil {
+		if cerr := q.listPortsByHostStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPortsByHostStmt: %w", cerr)
+		}
+	}
+	if q.listReportSectionsStmt != nil {
+		if cerr := q.listReportSectionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listReportSectionsStmt: %w", cerr)
+		}
+	}
 	if q.listSessionsStmt != nil {
 		if cerr := q.listSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listSessionsStmt: %w", cerr)
 		}
 	}
+	if q.markImportNotifiedStmt != nil {
+		if cerr := q.markImportNotifiedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markImportNotifiedStmt: %w", cerr)
+		}
+	}
+	if q.searchMessagesStmt != nil {
+		if cerr := q.searchMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchMessagesStmt: %w", cerr)
+		}
+	}
 	if q.updateMessageStmt != nil {
 		if cerr := q.updateMessageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateMessageStmt: %w", cerr)
@@ -117,6 +369,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateSessionStmt: %w", cerr)
 		}
 	}
+	if q.upsertFindingStmt != nil {
+		if cerr := q.upsertFindingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertFindingStmt: %w", cerr)
+		}
+	}
+	if q.upsertHostStmt != nil {
+		if cerr := q.upsertHostStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertHostStmt: %w", cerr)
+		}
+	}
+	if q.upsertPortStmt != nil {
+		if cerr := q.upsertPortStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPortStmt: %w", cerr)
+		}
+	}
+	if q.upsertReportSectionStmt != nil {
+		if cerr := q.upsertReportSectionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertReportSectionStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -154,35 +426,103 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                        DBTX
-	tx                        *sql.Tx
-	createMessageStmt         *sql.Stmt
-	createSessionStmt         *sql.Stmt
-	deleteMessageStmt         *sql.Stmt
-	deleteSessionStmt         *sql.Stmt
-	deleteSessionMessagesStmt *sql.Stmt
-	getMessageStmt            *sql.Stmt
-	getSessionByIDStmt        *sql.Stmt
-	listMessagesBySessionStmt *sql.Stmt
-	listSessionsStmt          *sql.Stmt
-	updateMessageStmt         *sql.Stmt
-	updateSessionStmt         *sql.Stmt
+	db                          DBTX
+	tx                          *sql.Tx
+	createAuditEntryStmt        *sql.Stmt
+	createCredentialStmt        *sql.Stmt
+	createImportStmt            *sql.Stmt
+	createMessageStmt           *sql.Stmt
+	createSessionStmt           *sql.Stmt
+	createVaultStmt             *sql.Stmt
+	deleteFindingStmt           *sql.Stmt
+	deleteMessageStmt           *sql.Stmt
+	deleteMessageSearchStmt     *sql.Stmt
+	deleteSessionStmt           *sql.Stmt
+	deleteSessionMessagesStmt   *sql.Stmt
+	getCredentialStmt           *sql.Stmt
+	getFindingStmt              *sql.Stmt
+	getHostByAddressStmt        *sql.Stmt
+	getLastAuditEntryStmt       *sql.Stmt
+	getMessageStmt              *sql.Stmt
+	getSessionByIDStmt          *sql.Stmt
+	getVaultStmt                *sql.Stmt
+	importFindingStmt           *sql.Stmt
+	importMessageStmt           *sql.Stmt
+	importReportSectionStmt     *sql.Stmt
+	importSessionStmt           *sql.Stmt
+	indexMessageStmt            *sql.Stmt
+	insertMessageSearchStmt     *sql.Stmt
+	listAllMessagesStmt         *sql.Stmt
+	listAllPortsStmt            *sql.Stmt
+	listAllSessionsStmt         *sql.Stmt
+	listAuditEntriesStmt        *sql.Stmt
+	listAuditEntriesBetweenStmt *sql.Stmt
+	listCredentialsStmt         *sql.Stmt
+	listFindingsStmt            *sql.Stmt
+	listHostsStmt               *sql.Stmt
+	listMessagesBySessionStmt   *sql.Stmt
+	listPendingImportsStmt      *sql.Stmt
+	listPortsByHostStmt         *sql.Stmt
+	listReportSectionsStmt      *sql.Stmt
+	listSessionsStmt            *sql.Stmt
+	markImportNotifiedStmt      *sql.Stmt
+	searchMessagesStmt          *sql.Stmt
+	updateMessageStmt           *sql.Stmt
+	updateSessionStmt           *sql.Stmt
+	upsertFindingStmt           *sql.Stmt
+	upsertHostStmt              *sql.Stmt
+	upsertPortStmt              *sql.Stmt
+	upsertReportSectionStmt     *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                        tx,
-		tx:                        tx,
-		createMessageStmt:         q.createMessageStmt,
-		createSessionStmt:         q.createSessionStmt,
-		deleteMessageStmt:         q.deleteMessageStmt,
-		deleteSessionStmt:         q.deleteSessionStmt,
-		deleteSessionMessagesStmt: q.deleteSessionMessagesStmt,
-		getMessageStmt:            q.getMessageStmt,
-		getSessionByIDStmt:        q.getSessionByIDStmt,
-		listMessagesBySessionStmt: q.listMessagesBySessionStmt,
-		listSessionsStmt:          q.listSessionsStmt,
-		updateMessageStmt:         q.updateMessageStmt,
-		updateSessionStmt:         q.updateSessionStmt,
+		db:                          tx,
+		tx:                          tx,
+		createAuditEntryStmt:        q.createAuditEntryStmt,
+		createCredentialStmt:        q.createCredentialStmt,
+		createImportStmt:            q.createImportStmt,
+		createMessageStmt:           q.createMessageStmt,
+		createSessionStmt:           q.createSessionStmt,
+		createVaultStmt:             q.createVaultStmt,
+		deleteFindingStmt:           q.deleteFindingStmt,
+		deleteMessageStmt:           q.deleteMessageStmt,
+		deleteMessageSearchStmt:     q.deleteMessageSearchStmt,
+		deleteSessionStmt:           q.deleteSessionStmt,
+		deleteSessionMessagesStmt:   q.deleteSessionMessagesStmt,
+		getCredentialStmt:           q.getCredentialStmt,
+		getFindingStmt:              q.getFindingStmt,
+		getHostByAddressStmt:        q.getHostByAddressStmt,
+		getLastAuditEntryStmt:       q.getLastAuditEntryStmt,
+		getMessageStmt:              q.getMessageStmt,
+		getSessionByIDStmt:          q.getSessionByIDStmt,
+		getVaultStmt:                q.getVaultStmt,
+		importFindingStmt:           q.importFindingStmt,
+		importMessageStmt:           q.importMessageStmt,
+		importReportSectionStmt:     q.importReportSectionStmt,
+		importSessionStmt:           q.importSessionStmt,
+		indexMessageStmt:            q.indexMessageStmt,
+		insertMessageSearchStmt:     q.insertMessageSearchStmt,
+		listAllMessagesStmt:         q.listAllMessagesStmt,
+		listAllPortsStmt:            q.listAllPortsStmt,
+		listAllSessionsStmt:         q.listAllSessionsStmt,
+		listAuditEntriesStmt:        q.listAuditEntriesStmt,
+		listAuditEntriesBetweenStmt: q.listAuditEntriesBetweenStmt,
+		listCredentialsStmt:         q.listCredentialsStmt,
+		listFindingsStmt:            q.listFindingsStmt,
+		listHostsStmt:               q.listHostsStmt,
+		listMessagesBySessionStmt:   q.listMessagesBySessionStmt,
+		listPendingImportsStmt:      q.listPendingImportsStmt,
+		listPortsByHostStmt:         q.listPortsByHostStmt,
+		listReportSectionsStmt:      q.listReportSectionsStmt,
+		listSessionsStmt:            q.listSessionsStmt,
+		markImportNotifiedStmt:      q.markImportNotifiedStmt,
+		searchMessagesStmt:          q.searchMessagesStmt,
+		updateMessageStmt:           q.updateMessageStmt,
+		updateSessionStmt:           q.updateSessionStmt,
+		upsertFindingStmt:           q.upsertFindingStmt,
+		upsertHostStmt:              q.upsertHostStmt,
+		upsertPortStmt:              q.upsertPortStmt,
+		upsertReportSectionStmt:     q.upsertReportSectionStmt,
 	}
 }
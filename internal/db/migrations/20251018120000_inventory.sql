@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Hosts
+CREATE TABLE IF NOT EXISTS hosts (
+    id TEXT PRIMARY KEY,
+    address TEXT NOT NULL UNIQUE,
+    hostname TEXT NOT NULL DEFAULT '',
+    os TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL   -- Unix timestamp in milliseconds
+);
+
+CREATE TRIGGER IF NOT EXISTS update_hosts_updated_at
+AFTER UPDATE ON hosts
+BEGIN
+UPDATE hosts SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+
+-- Ports
+CREATE TABLE IF NOT EXISTS ports (
+    id TEXT PRIMARY KEY,
+    host_id TEXT NOT NULL,
+    port INTEGER NOT NULL,
+    protocol TEXT NOT NULL,
+    state TEXT NOT NULL DEFAULT '',
+    service TEXT NOT NULL DEFAULT '',
+    product TEXT NOT NULL DEFAULT '',
+    version TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    UNIQUE (host_id, port, protocol),
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ports_host_id ON ports (host_id);
+
+CREATE TRIGGER IF NOT EXISTS update_ports_updated_at
+AFTER UPDATE ON ports
+BEGIN
+UPDATE ports SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_hosts_updated_at;
+DROP TRIGGER IF EXISTS update_ports_updated_at;
+
+DROP TABLE IF EXISTS ports;
+DROP TABLE IF EXISTS hosts;
+-- +goose StatementEnd
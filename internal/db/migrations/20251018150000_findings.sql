@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Findings
+CREATE TABLE IF NOT EXISTS findings (
+    id TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL UNIQUE,
+    host_id TEXT,
+    port INTEGER NOT NULL DEFAULT 0,
+    protocol TEXT NOT NULL DEFAULT '',
+    location TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'info',
+    description TEXT NOT NULL DEFAULT '',
+    evidence TEXT NOT NULL DEFAULT '',
+    remediation TEXT NOT NULL DEFAULT '',
+    refs TEXT NOT NULL DEFAULT '[]',
+    source TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    updated_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_findings_host_id ON findings (host_id);
+
+CREATE TRIGGER IF NOT EXISTS update_findings_updated_at
+AFTER UPDATE ON findings
+BEGIN
+UPDATE findings SET updated_at = strftime('%s', 'now')
+WHERE id = new.id;
+END;
+
+-- Imports of scanner output, kept around to notify the orchestrator
+CREATE TABLE IF NOT EXISTS imports (
+    id TEXT PRIMARY KEY,
+    path TEXT NOT NULL,
+    format TEXT NOT NULL,
+    hosts INTEGER NOT NULL DEFAULT 0,
+    ports INTEGER NOT NULL DEFAULT 0,
+    findings INTEGER NOT NULL DEFAULT 0,
+    notified_at INTEGER,  -- Unix timestamp in milliseconds
+    created_at INTEGER NOT NULL   -- Unix timestamp in milliseconds
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_findings_updated_at;
+
+DROP TABLE IF EXISTS imports;
+DROP TABLE IF EXISTS findings;
+-- +goose StatementEnd
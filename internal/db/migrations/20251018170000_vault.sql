@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The key of the credential vault is derived from a passphrase, only its salt and a verifier are kept
+CREATE TABLE IF NOT EXISTS vault (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    salt BLOB NOT NULL,
+    verifier BLOB NOT NULL,
+    created_at INTEGER NOT NULL  -- Unix timestamp in milliseconds
+);
+
+-- Credentials discovered during the engagement, the id is the handle the agents refer to them by
+CREATE TABLE IF NOT EXISTS credentials (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL DEFAULT 'password',
+    username TEXT NOT NULL DEFAULT '',
+    target TEXT NOT NULL DEFAULT '',
+    secret BLOB NOT NULL,  -- nonce followed by the sealed secret
+    notes TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL  -- Unix timestamp in milliseconds
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS credentials;
+DROP TABLE IF EXISTS vault;
+-- +goose StatementEnd
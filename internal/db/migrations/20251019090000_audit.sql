@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every tool execution of the engagement, each entry hashes the one before it so that any edit breaks the chain
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL DEFAULT '',
+    agent TEXT NOT NULL DEFAULT '',
+    tool TEXT NOT NULL,
+    tool_call_id TEXT NOT NULL DEFAULT '',
+    input TEXT NOT NULL DEFAULT '',
+    command TEXT NOT NULL DEFAULT '',
+    executor TEXT NOT NULL DEFAULT '',
+    container_id TEXT NOT NULL DEFAULT '',
+    exit_code INTEGER,
+    is_error BOOLEAN NOT NULL DEFAULT FALSE,
+    output_sha256 TEXT NOT NULL,
+    started_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    finished_at INTEGER NOT NULL,  -- Unix timestamp in milliseconds
+    prev_hash TEXT NOT NULL UNIQUE,  -- a second writer appending to the same entry fails instead of forking the chain
+    hash TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_started_at ON audit_log (started_at);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'the audit log is append only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'the audit log is append only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP INDEX IF EXISTS idx_audit_log_started_at;
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Narrative sections of the report written by the reporter agent or the operator, one per name
+CREATE TABLE IF NOT EXISTS report_sections (
+    name TEXT PRIMARY KEY,
+    content TEXT NOT NULL,
+    author TEXT NOT NULL DEFAULT '',
+    updated_at INTEGER NOT NULL  -- Unix timestamp in seconds
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS report_sections;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The CVSS vector of a finding and its score, the severity follows from the score when there's a vector
+ALTER TABLE findings ADD COLUMN cvss_vector TEXT NOT NULL DEFAULT '';
+ALTER TABLE findings ADD COLUMN cvss_score REAL NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE findings DROP COLUMN cvss_score;
+ALTER TABLE findings DROP COLUMN cvss_vector;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The searchable text of every message, one row per text, tool call input and tool result, kept by the message service
+CREATE VIRTUAL TABLE IF NOT EXISTS message_search USING fts5 (
+    message_id UNINDEXED,
+    session_id UNINDEXED,
+    kind UNINDEXED,  -- text, tool_call or tool_result
+    tool UNINDEXED,
+    content,
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO message_search (message_id, session_id, kind, tool, content)
+SELECT m.id, m.session_id, 'text', '', json_extract(p.value, '$.data.text')
+FROM messages m, json_each(m.parts) p
+WHERE json_extract(p.value, '$.type') = 'text'
+  AND coalesce(json_extract(p.value, '$.data.text'), '') != ''
+UNION ALL
+SELECT m.id, m.session_id, 'tool_call', json_extract(p.value, '$.data.name'), json_extract(p.value, '$.data.input')
+FROM messages m, json_each(m.parts) p
+WHERE json_extract(p.value, '$.type') = 'tool_call'
+  AND coalesce(json_extract(p.value, '$.data.input'), '') != ''
+UNION ALL
+SELECT m.id, m.session_id, 'tool_result', coalesce(nullif(json_extract(p.value, '$.data.name'), ''), (
+    SELECT json_extract(c.value, '$.data.name')
+    FROM messages cm, json_each(cm.parts) c
+    WHERE cm.session_id = m.session_id
+      AND json_extract(c.value, '$.type') = 'tool_call'
+      AND json_extract(c.value, '$.data.id') = json_extract(p.value, '$.data.tool_call_id')
+), ''), json_extract(p.value, '$.data.content')
+FROM messages m, json_each(m.parts) p
+WHERE json_extract(p.value, '$.type') = 'tool_result'
+  AND coalesce(json_extract(p.value, '$.data.content'), '') != '';
+
+-- Rows go away with their messages, including the ones a deleted session takes along
+CREATE TRIGGER IF NOT EXISTS message_search_delete
+AFTER DELETE ON messages
+BEGIN
+    DELETE FROM message_search WHERE message_id = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS message_search_delete;
+DROP TABLE IF EXISTS message_search;
+-- +goose StatementEnd
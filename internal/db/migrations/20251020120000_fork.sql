@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The session a session was forked from, the fork starts with a copy of its history up to the message it was forked at
+ALTER TABLE sessions ADD COLUMN forked_from TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN forked_from;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The calls to store_credential carry the secrets handed to the vault, they are kept out of the search index
+DELETE FROM message_search
+WHERE kind = 'tool_call'
+  AND tool = 'store_credential';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
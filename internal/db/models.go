@@ -8,6 +8,78 @@ import (
 	"database/sql"
 )
 
+type AuditLog struct {
+	ID           int64         `json:"id"`
+	SessionID    string        `json:"session_id"`
+	Agent        string        `json:"agent"`
+	Tool         string        `json:"tool"`
+	ToolCallID   string        `json:"tool_call_id"`
+	Input        string        `json:"input"`
+	Command      string        `json:"command"`
+	Executor     string        `json:"executor"`
+	ContainerID  string        `json:"container_id"`
+	ExitCode     sql.NullInt64 `json:"exit_code"`
+	IsError      bool          `json:"is_error"`
+	OutputSha256 string        `json:"output_sha256"`
+	StartedAt    int64         `json:"started_at"`
+	FinishedAt   int64         `json:"finished_at"`
+	PrevHash     string        `json:"prev_hash"`
+	Hash         string        `json:"hash"`
+}
+
+type Credential struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	Username  string `json:"username"`
+	Target    string `json:"target"`
+	Secret    []byte `json:"secret"`
+	Notes     string `json:"notes"`
+	Source    string `json:"source"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type Finding struct {
+	ID          string         `json:"id"`
+	Fingerprint string         `json:"fingerprint"`
+	HostID      sql.NullString `json:"host_id"`
+	Port        int64          `json:"port"`
+	Protocol    string         `json:"protocol"`
+	Location    string         `json:"location"`
+	Title       string         `json:"title"`
+	Severity    string         `json:"severity"`
+	Description string         `json:"description"`
+	Evidence    string         `json:"evidence"`
+	Remediation string         `json:"remediation"`
+	Refs        string         `json:"refs"`
+	Source      string         `json:"source"`
+	CreatedAt   int64          `json:"created_at"`
+	UpdatedAt   int64          `json:"updated_at"`
+	CvssVector  string         `json:"cvss_vector"`
+	CvssScore   float64        `json:"cvss_score"`
+}
+
+type Host struct {
+	ID        string `json:"id"`
+	Address   string `json:"address"`
+	Hostname  string `json:"hostname"`
+	Os        string `json:"os"`
+	Status    string `json:"status"`
+	Source    string `json:"source"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type Import struct {
+	ID         string        `json:"id"`
+	Path       string        `json:"path"`
+	Format     string        `json:"format"`
+	Hosts      int64         `json:"hosts"`
+	Ports      int64         `json:"ports"`
+	Findings   int64         `json:"findings"`
+	NotifiedAt sql.NullInt64 `json:"notified_at"`
+	CreatedAt  int64         `json:"created_at"`
+}
+
 type Message struct {
 	ID         string         `json:"id"`
 	SessionID  string         `json:"session_id"`
@@ -19,6 +91,27 @@ type Message struct {
 	FinishedAt sql.NullInt64  `json:"finished_at"`
 }
 
+type Port struct {
+	ID        string `json:"id"`
+	HostID    string `json:"host_id"`
+	Port      int64  `json:"port"`
+	Protocol  string `json:"protocol"`
+	State     string `json:"state"`
+	Service   string `json:"service"`
+	Product   string `json:"product"`
+	Version   string `json:"version"`
+	Source    string `json:"source"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type ReportSection struct {
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	Author    string `json:"author"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
 type Session struct {
 	ID               string         `json:"id"`
 	SummaryMessageID sql.NullString `json:"summary_message_id"`
@@ -30,4 +123,12 @@ type Session struct {
 	Cost             float64        `json:"cost"`
 	UpdatedAt        int64          `json:"updated_at"`
 	CreatedAt        int64          `json:"created_at"`
+	ForkedFrom       sql.NullString `json:"forked_from"`
+}
+
+type Vault struct {
+	ID        int64  `json:"id"`
+	Salt      []byte `json:"salt"`
+	Verifier  []byte `json:"verifier"`
+	CreatedAt int64  `json:"created_at"`
 }
@@ -9,17 +9,51 @@ import (
 )
 
 type Querier interface {
+	CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) (AuditLog, error)
+	CreateCredential(ctx context.Context, arg CreateCredentialParams) (Credential, error)
+	CreateImport(ctx context.Context, arg CreateImportParams) (Import, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateVault(ctx context.Context, arg CreateVaultParams) error
+	DeleteFinding(ctx context.Context, id string) error
 	DeleteMessage(ctx context.Context, id string) error
+	DeleteMessageSearch(ctx context.Context, messageID string) error
 	DeleteSession(ctx context.Context, id string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	GetCredential(ctx context.Context, id int64) (Credential, error)
+	GetFinding(ctx context.Context, id string) (Finding, error)
+	GetHostByAddress(ctx context.Context, address string) (Host, error)
+	GetLastAuditEntry(ctx context.Context) (AuditLog, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
 	GetSessionByID(ctx context.Context, id string) (Session, error)
+	GetVault(ctx context.Context) (Vault, error)
+	ImportFinding(ctx context.Context, arg ImportFindingParams) (int64, error)
+	ImportMessage(ctx context.Context, arg ImportMessageParams) (int64, error)
+	ImportReportSection(ctx context.Context, arg ImportReportSectionParams) (int64, error)
+	ImportSession(ctx context.Context, arg ImportSessionParams) (int64, error)
+	IndexMessage(ctx context.Context, id string) error
+	InsertMessageSearch(ctx context.Context, arg InsertMessageSearchParams) error
+	ListAllMessages(ctx context.Context) ([]Message, error)
+	ListAllPorts(ctx context.Context) ([]Port, error)
+	ListAllSessions(ctx context.Context) ([]Session, error)
+	ListAuditEntries(ctx context.Context) ([]AuditLog, error)
+	ListAuditEntriesBetween(ctx context.Context, arg ListAuditEntriesBetweenParams) ([]AuditLog, error)
+	ListCredentials(ctx context.Context) ([]Credential, error)
+	ListFindings(ctx context.Context) ([]Finding, error)
+	ListHosts(ctx context.Context) ([]Host, error)
 	ListMessagesBySession(ctx context.Context, sessionID string) ([]Message, error)
+	ListPendingImports(ctx context.Context) ([]Import, error)
+	ListPortsByHost(ctx context.Context, hostID string) ([]Port, error)
+	ListReportSections(ctx context.Context) ([]ReportSection, error)
 	ListSessions(ctx context.Context) ([]Session, error)
+	MarkImportNotified(ctx context.Context, id string) error
+	SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error)
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) error
 	UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error)
+	UpsertFinding(ctx context.Context, arg UpsertFindingParams) (Finding, error)
+	UpsertHost(ctx context.Context, arg UpsertHostParams) (Host, error)
+	UpsertPort(ctx context.Context, arg UpsertPortParams) (Port, error)
+	UpsertReportSection(ctx context.Context, arg UpsertReportSectionParams) (ReportSection, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -19,6 +19,7 @@ INSERT INTO sessions (
     prompt_tokens,
     completion_tokens,
     cost,
+    forked_from,
     summary_message_id,
     updated_at,
     created_at
@@ -30,10 +31,11 @@ INSERT INTO sessions (
     ?,
     ?,
     ?,
+    ?,
     null,
     strftime('%s', 'now'),
     strftime('%s', 'now')
-) RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+) RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, forked_from
 `
 
 type CreateSessionParams struct {
@@ -44,6 +46,7 @@ type CreateSessionParams struct {
 	PromptTokens     int64          `json:"prompt_tokens"`
 	CompletionTokens int64          `json:"completion_tokens"`
 	Cost             float64        `json:"cost"`
+	ForkedFrom       sql.NullString `json:"forked_from"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -55,6 +58,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.PromptTokens,
 		arg.CompletionTokens,
 		arg.Cost,
+		arg.ForkedFrom,
 	)
 	var i Session
 	err := row.Scan(
@@ -68,6 +72,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.Cost,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.ForkedFrom,
 	)
 	return i, err
 }
@@ -83,7 +88,7 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 }
 
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, forked_from
 FROM sessions
 WHERE id = ? LIMIT 1
 `
@@ -102,12 +107,13 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.Cost,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.ForkedFrom,
 	)
 	return i, err
 }
 
 const listSessions = `-- name: ListSessions :many
-SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+SELECT id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, forked_from
 FROM sessions
 WHERE parent_session_id is NULL
 ORDER BY created_at DESC
@@ -133,6 +139,7 @@ func (q *Queries) ListSessions(ctx context.Context) ([]Session, error) {
 			&i.Cost,
 			&i.UpdatedAt,
 			&i.CreatedAt,
+			&i.ForkedFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -156,7 +163,7 @@ SET
     summary_message_id = ?,
     cost = ?
 WHERE id = ?
-RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at
+RETURNING id, summary_message_id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, forked_from
 `
 
 type UpdateSessionParams struct {
@@ -189,6 +196,7 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.Cost,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.ForkedFrom,
 	)
 	return i, err
 }
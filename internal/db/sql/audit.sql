@@ -0,0 +1,42 @@
+-- name: CreateAuditEntry :one
+INSERT INTO audit_log (
+    session_id,
+    agent,
+    tool,
+    tool_call_id,
+    input,
+    command,
+    executor,
+    container_id,
+    exit_code,
+    is_error,
+    output_sha256,
+    started_at,
+    finished_at,
+    prev_hash,
+    hash
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetLastAuditEntry :one
+SELECT *
+FROM audit_log
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListAuditEntries :many
+SELECT *
+FROM audit_log
+ORDER BY id ASC;
+
+-- name: ListAuditEntriesBetween :many
+SELECT *
+FROM audit_log
+WHERE id BETWEEN (
+    SELECT min(id) FROM audit_log WHERE started_at >= sqlc.arg(from) AND started_at < sqlc.arg(to)
+) AND (
+    SELECT max(id) FROM audit_log WHERE started_at >= sqlc.arg(from) AND started_at < sqlc.arg(to)
+)
+ORDER BY id ASC;
@@ -0,0 +1,86 @@
+-- name: ListAllSessions :many
+SELECT *
+FROM sessions
+ORDER BY created_at ASC;
+
+-- name: ListAllMessages :many
+SELECT *
+FROM messages
+ORDER BY created_at ASC;
+
+-- name: ListAllPorts :many
+SELECT *
+FROM ports
+ORDER BY host_id ASC, protocol ASC, port ASC;
+
+-- name: ImportSession :execrows
+INSERT INTO sessions (
+    id,
+    parent_session_id,
+    title,
+    message_count,
+    prompt_tokens,
+    completion_tokens,
+    summary_message_id,
+    cost,
+    created_at,
+    updated_at,
+    forked_from
+) VALUES (
+    ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: ImportMessage :execrows
+INSERT INTO messages (
+    id,
+    session_id,
+    role,
+    parts,
+    model,
+    created_at,
+    updated_at,
+    finished_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: ImportFinding :execrows
+INSERT INTO findings (
+    id,
+    fingerprint,
+    host_id,
+    port,
+    protocol,
+    location,
+    title,
+    severity,
+    description,
+    evidence,
+    remediation,
+    refs,
+    source,
+    cvss_vector,
+    cvss_score,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: ImportReportSection :execrows
+INSERT INTO report_sections (
+    name,
+    content,
+    author,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?
+)
+ON CONFLICT (name) DO UPDATE SET
+    content = excluded.content,
+    author = excluded.author,
+    updated_at = excluded.updated_at
+WHERE excluded.updated_at > report_sections.updated_at;
@@ -0,0 +1,45 @@
+-- name: UpsertFinding :one
+INSERT INTO findings (
+    id,
+    fingerprint,
+    host_id,
+    port,
+    protocol,
+    location,
+    title,
+    severity,
+    description,
+    evidence,
+    remediation,
+    refs,
+    source,
+    cvss_vector,
+    cvss_score,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (fingerprint) DO UPDATE SET
+    severity = excluded.severity,
+    description = excluded.description,
+    evidence = excluded.evidence,
+    remediation = excluded.remediation,
+    refs = excluded.refs,
+    cvss_vector = excluded.cvss_vector,
+    cvss_score = excluded.cvss_score
+RETURNING *;
+
+-- name: GetFinding :one
+SELECT *
+FROM findings
+WHERE id = ? LIMIT 1;
+
+-- name: ListFindings :many
+SELECT *
+FROM findings
+ORDER BY created_at ASC;
+
+-- name: DeleteFinding :exec
+DELETE FROM findings
+WHERE id = ?;
@@ -0,0 +1,24 @@
+-- name: CreateImport :one
+INSERT INTO imports (
+    id,
+    path,
+    format,
+    hosts,
+    ports,
+    findings,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: ListPendingImports :many
+SELECT *
+FROM imports
+WHERE notified_at IS NULL
+ORDER BY created_at ASC;
+
+-- name: MarkImportNotified :exec
+UPDATE imports
+SET notified_at = strftime('%s', 'now')
+WHERE id = ?;
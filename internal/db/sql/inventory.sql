@@ -0,0 +1,59 @@
+-- name: UpsertHost :one
+INSERT INTO hosts (
+    id,
+    address,
+    hostname,
+    os,
+    status,
+    source,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (address) DO UPDATE SET
+    hostname = CASE WHEN excluded.hostname != '' THEN excluded.hostname ELSE hosts.hostname END,
+    os = CASE WHEN excluded.os != '' THEN excluded.os ELSE hosts.os END,
+    status = CASE WHEN excluded.status != '' THEN excluded.status ELSE hosts.status END,
+    source = excluded.source
+RETURNING *;
+
+-- name: GetHostByAddress :one
+SELECT *
+FROM hosts
+WHERE address = ? LIMIT 1;
+
+-- name: ListHosts :many
+SELECT *
+FROM hosts
+ORDER BY address ASC;
+
+-- name: UpsertPort :one
+INSERT INTO ports (
+    id,
+    host_id,
+    port,
+    protocol,
+    state,
+    service,
+    product,
+    version,
+    source,
+    created_at,
+    updated_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+)
+ON CONFLICT (host_id, port, protocol) DO UPDATE SET
+    state = CASE WHEN excluded.state != '' THEN excluded.state ELSE ports.state END,
+    service = CASE WHEN excluded.service != '' THEN excluded.service ELSE ports.service END,
+    product = CASE WHEN excluded.product != '' THEN excluded.product ELSE ports.product END,
+    version = CASE WHEN excluded.version != '' THEN excluded.version ELSE ports.version END,
+    source = excluded.source
+RETURNING *;
+
+-- name: ListPortsByHost :many
+SELECT *
+FROM ports
+WHERE host_id = ?
+ORDER BY protocol ASC, port ASC;
@@ -0,0 +1,19 @@
+-- name: UpsertReportSection :one
+INSERT INTO report_sections (
+    name,
+    content,
+    author,
+    updated_at
+) VALUES (
+    ?, ?, ?, strftime('%s', 'now')
+)
+ON CONFLICT (name) DO UPDATE SET
+    content = excluded.content,
+    author = excluded.author,
+    updated_at = excluded.updated_at
+RETURNING *;
+
+-- name: ListReportSections :many
+SELECT *
+FROM report_sections
+ORDER BY name ASC;
@@ -0,0 +1,59 @@
+-- name: InsertMessageSearch :exec
+INSERT INTO message_search (
+    message_id,
+    session_id,
+    kind,
+    tool,
+    content
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: DeleteMessageSearch :exec
+DELETE FROM message_search
+WHERE message_id = ?;
+
+-- name: SearchMessages :many
+SELECT
+    message_search.message_id,
+    message_search.session_id,
+    sessions.parent_session_id,
+    sessions.title AS session_title,
+    messages.role,
+    message_search.kind,
+    message_search.tool,
+    snippet(message_search, 4, sqlc.arg(open), sqlc.arg(close), '…', sqlc.arg(tokens)) AS snippet,
+    messages.created_at
+FROM message_search
+JOIN messages ON messages.id = message_search.message_id
+JOIN sessions ON sessions.id = message_search.session_id
+WHERE message_search MATCH sqlc.arg(query)
+ORDER BY bm25(message_search)
+LIMIT sqlc.arg(limit);
+
+-- name: IndexMessage :exec
+INSERT INTO message_search (message_id, session_id, kind, tool, content)
+SELECT m.id, m.session_id, 'text', '', json_extract(p.value, '$.data.text')
+FROM messages m, json_each(m.parts) p
+WHERE m.id = sqlc.arg(id)
+  AND json_extract(p.value, '$.type') = 'text'
+  AND coalesce(json_extract(p.value, '$.data.text'), '') != ''
+UNION ALL
+SELECT m.id, m.session_id, 'tool_call', json_extract(p.value, '$.data.name'), json_extract(p.value, '$.data.input')
+FROM messages m, json_each(m.parts) p
+WHERE m.id = sqlc.arg(id)
+  AND json_extract(p.value, '$.type') = 'tool_call'
+  AND json_extract(p.value, '$.data.name') != 'store_credential'
+  AND coalesce(json_extract(p.value, '$.data.input'), '') != ''
+UNION ALL
+SELECT m.id, m.session_id, 'tool_result', coalesce(nullif(json_extract(p.value, '$.data.name'), ''), (
+    SELECT json_extract(c.value, '$.data.name')
+    FROM messages cm, json_each(cm.parts) c
+    WHERE cm.session_id = m.session_id
+      AND json_extract(c.value, '$.type') = 'tool_call'
+      AND json_extract(c.value, '$.data.id') = json_extract(p.value, '$.data.tool_call_id')
+), ''), json_extract(p.value, '$.data.content')
+FROM messages m, json_each(m.parts) p
+WHERE m.id = sqlc.arg(id)
+  AND json_extract(p.value, '$.type') = 'tool_result'
+  AND coalesce(json_extract(p.value, '$.data.content'), '') != '';
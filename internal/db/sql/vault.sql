@@ -0,0 +1,38 @@
+-- name: GetVault :one
+SELECT *
+FROM vault
+WHERE id = 1 LIMIT 1;
+
+-- name: CreateVault :exec
+INSERT INTO vault (
+    id,
+    salt,
+    verifier,
+    created_at
+) VALUES (
+    1, ?, ?, strftime('%s', 'now')
+);
+
+-- name: CreateCredential :one
+INSERT INTO credentials (
+    kind,
+    username,
+    target,
+    secret,
+    notes,
+    source,
+    created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, strftime('%s', 'now')
+)
+RETURNING *;
+
+-- name: GetCredential :one
+SELECT *
+FROM credentials
+WHERE id = ? LIMIT 1;
+
+-- name: ListCredentials :many
+SELECT *
+FROM credentials
+ORDER BY id ASC;
@@ -0,0 +1,402 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	_ "embed"
+
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+const (
+	// WorkspaceDir is where the bind mount lands inside the container. matches the WORKDIR of the KaliDockerfile.
+	WorkspaceDir = "/home/pentests"
+
+	// LootDir is the volume shared by every container of an engagement, isolated or not.
+	LootDir = "/loot"
+
+	LabelManaged    = "tandem.managed"
+	LabelEngagement = "tandem.engagement"
+	LabelPoolKey    = "tandem.pool.key"
+)
+
+//go:embed KaliDockerfile
+var kaliDockerfile []byte
+
+// Status describes the engagement container.
+type Status struct {
+	ImageExists  bool
+	ContainerID  string
+	Name         string
+	Image        string
+	State        container.ContainerState
+	EngagementID string
+	BindMount    string
+	CreatedAt    time.Time
+}
+
+// Manager owns the lifecycle of the engagement container: building its image, creating, starting and destroying it.
+type Manager struct {
+	client       *client.Client
+	image        string
+	dockerfile   string
+	privileged   bool
+	engagementID string
+	bindMount    string
+	resources    container.Resources
+
+	// NOTE: guards against two agents racing to create the same container.
+	mu sync.Mutex
+}
+
+func NewManager(cli *client.Client) *Manager {
+	cfg := config.Get()
+	return &Manager{
+		client:       cli,
+		image:        cfg.Container.Image,
+		dockerfile:   cfg.Container.Dockerfile,
+		privileged:   cfg.Container.Privileged,
+		engagementID: config.EngagementID(),
+		bindMount:    config.BindMountPath(),
+		resources: container.Resources{
+			NanoCPUs: int64(cfg.Container.CPUs * 1e9),
+			Memory:   cfg.Container.MemoryMB * 1024 * 1024,
+		},
+	}
+}
+
+// NewClient creates a docker client from the environment.
+func NewClient() (*client.Client, error) {
+	return NewClientForHost("")
+}
+
+// NewClientForHost creates a client for the docker compatible daemon listening at host, e.g. unix:///run/podman/podman.sock.
+// an empty host falls back to the environment.
+func NewClientForHost(host string) (*client.Client, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	return cli, nil
+}
+
+// PodmanHost returns the address of the podman socket, rootless if the user runtime dir has one.
+func PodmanHost() string {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		socket := filepath.Join(runtimeDir, "podman", "podman.sock")
+		if _, err := os.Stat(socket); err == nil {
+			return "unix://" + socket
+		}
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// Client returns the docker client used by the manager.
+func (m *Manager) Client() *client.Client {
+	return m.client
+}
+
+// LootVolume is the name of the volume shared by all the containers of the current engagement.
+func (m *Manager) LootVolume() string {
+	return fmt.Sprintf("tandem-%s-loot", m.engagementID)
+}
+
+// ContainerName is the name of the container for the current engagement.
+func (m *Manager) ContainerName() string {
+	return fmt.Sprintf("tandem-%s", m.engagementID)
+}
+
+// EnsureImage builds the image from the KaliDockerfile if it isn't available locally.
+func (m *Manager) EnsureImage(ctx context.Context, out io.Writer) error {
+	_, err := m.client.ImageInspect(ctx, m.image)
+	if err == nil {
+		return nil
+	}
+	if !client.IsErrNotFound(err) {
+		return fmt.Errorf("failed to inspect image %s: %w", m.image, err)
+	}
+	logging.Info("image not found, building it", "image", m.image)
+	return m.BuildImage(ctx, out, false)
+}
+
+// BuildImage builds the image from the configured Dockerfile or the embedded KaliDockerfile.
+func (m *Manager) BuildImage(ctx context.Context, out io.Writer, noCache bool) error {
+	dockerfile := kaliDockerfile
+	if m.dockerfile != "" {
+		content, err := os.ReadFile(m.dockerfile)
+		if err != nil {
+			return fmt.Errorf("failed to read dockerfile %s: %w", m.dockerfile, err)
+		}
+		dockerfile = content
+	}
+
+	buildContext, err := tarDockerfile(dockerfile)
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.client.ImageBuild(ctx, buildContext, build.ImageBuildOptions{
+		Tags:        []string{m.image},
+		Dockerfile:  "Dockerfile",
+		NoCache:     noCache,
+		Remove:      true,
+		ForceRemove: true,
+		PullParent:  noCache,
+		Labels:      map[string]string{LabelManaged: "true"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build image %s: %w", m.image, err)
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		out = io.Discard
+	}
+	fd, isTerminal := terminalFd(out)
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, out, fd, isTerminal, nil); err != nil {
+		return fmt.Errorf("failed to build image %s: %w", m.image, err)
+	}
+	return nil
+}
+
+// EnsureContainer returns the ID of a running container for the current engagement, creating and starting it when needed.
+func (m *Manager) EnsureContainer(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	summary, err := m.find(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	containerID := ""
+	state := container.StateCreated
+	if summary != nil {
+		containerID = summary.ID
+		state = summary.State
+	} else {
+		if err := m.EnsureImage(ctx, nil); err != nil {
+			return "", err
+		}
+		containerID, err = m.create(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := m.start(ctx, containerID, state); err != nil {
+		return "", err
+	}
+	return containerID, nil
+}
+
+// Status reports on the image and the container of the current engagement.
+func (m *Manager) Status(ctx context.Context) (Status, error) {
+	status := Status{
+		Image:        m.image,
+		Name:         m.ContainerName(),
+		EngagementID: m.engagementID,
+		BindMount:    m.bindMount,
+	}
+
+	if _, err := m.client.ImageInspect(ctx, m.image); err == nil {
+		status.ImageExists = true
+	} else if !client.IsErrNotFound(err) {
+		return status, fmt.Errorf("failed to inspect image %s: %w", m.image, err)
+	}
+
+	summary, err := m.find(ctx)
+	if err != nil {
+		return status, err
+	}
+	if summary != nil {
+		status.ContainerID = summary.ID
+		status.State = summary.State
+		status.CreatedAt = time.Unix(summary.Created, 0)
+	}
+	return status, nil
+}
+
+// Destroy force removes the container of the current engagement. the bind mounted workspace stays on the host.
+func (m *Manager) Destroy(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	summary, err := m.find(ctx)
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		return nil
+	}
+	if err := m.client.ContainerRemove(ctx, summary.ID, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", summary.ID, err)
+	}
+	return nil
+}
+
+// Rebuild destroys the container, rebuilds the image from scratch and creates a fresh container.
+func (m *Manager) Rebuild(ctx context.Context, out io.Writer) (string, error) {
+	if err := m.Destroy(ctx); err != nil {
+		return "", err
+	}
+	if err := m.BuildImage(ctx, out, true); err != nil {
+		return "", err
+	}
+	return m.EnsureContainer(ctx)
+}
+
+func (m *Manager) find(ctx context.Context) (*container.Summary, error) {
+	summaries, err := m.client.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", LabelEngagement, m.engagementID)),
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	// NOTE: the isolated containers of the pool carry the engagement label as well.
+	summaries = slices.DeleteFunc(summaries, func(summary container.Summary) bool {
+		_, pooled := summary.Labels[LabelPoolKey]
+		return pooled
+	})
+
+	// NOTE: prefer a running container if somehow there are more than one.
+	var found *container.Summary
+	for i := range summaries {
+		if summaries[i].State == container.StateRunning {
+			return &summaries[i], nil
+		}
+		if found == nil {
+			found = &summaries[i]
+		}
+	}
+	return found, nil
+}
+
+func (m *Manager) create(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(m.bindMount, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create the workspace %s: %w", m.bindMount, err)
+	}
+
+	return m.createContainer(ctx, m.ContainerName(), nil, []mount.Mount{
+		{
+			Type:   mount.TypeBind,
+			Source: m.bindMount,
+			Target: WorkspaceDir,
+		},
+	})
+}
+
+// createContainer creates a container of the engagement. every container gets the loot volume mounted on top of the given mounts.
+func (m *Manager) createContainer(ctx context.Context, name string, labels map[string]string, mounts []mount.Mount) (string, error) {
+	containerLabels := map[string]string{
+		LabelManaged:    "true",
+		LabelEngagement: m.engagementID,
+	}
+	maps.Copy(containerLabels, labels)
+
+	mounts = append(mounts, mount.Mount{
+		Type:   mount.TypeVolume,
+		Source: m.LootVolume(),
+		Target: LootDir,
+	})
+
+	resp, err := m.client.ContainerCreate(ctx,
+		&container.Config{
+			Image:      m.image,
+			Hostname:   name,
+			WorkingDir: WorkspaceDir,
+			Labels:     containerLabels,
+		},
+		&container.HostConfig{
+			Privileged: m.privileged,
+			Init:       boolPtr(true),
+			Mounts:     mounts,
+			Resources:  m.resources,
+		},
+		nil, nil, name,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container %s: %w", name, err)
+	}
+	for _, warning := range resp.Warnings {
+		logging.Warn("container create warning", "warning", warning)
+	}
+	logging.Info("created engagement container", "name", name, "id", resp.ID)
+	return resp.ID, nil
+}
+
+// start gets a docker container to container.StateRunning.
+func (m *Manager) start(ctx context.Context, containerID string, state container.ContainerState) error {
+	switch state {
+	case container.StateExited, container.StateCreated:
+		if err := m.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+			return fmt.Errorf("failed to start container %s: %w", containerID, err)
+		}
+	case container.StatePaused:
+		if err := m.client.ContainerUnpause(ctx, containerID); err != nil {
+			return fmt.Errorf("failed to unpause container %s: %w", containerID, err)
+		}
+	}
+	return nil
+}
+
+// tarDockerfile packs the dockerfile as the only file of a build context.
+func tarDockerfile(dockerfile []byte) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    "Dockerfile",
+		Mode:    0o644,
+		Size:    int64(len(dockerfile)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create build context: %w", err)
+	}
+	if _, err := tw.Write(dockerfile); err != nil {
+		return nil, fmt.Errorf("failed to create build context: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create build context: %w", err)
+	}
+	return &buf, nil
+}
+
+func terminalFd(out io.Writer) (uintptr, bool) {
+	if f, ok := out.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return f.Fd(), true
+		}
+	}
+	return 0, false
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
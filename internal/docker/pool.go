@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// Pool hands out isolated containers of the engagement, one per key. a key is either an agent name or a task session ID.
+// the pooled containers are cloned from the engagement image and only share the loot volume with each other.
+type Pool struct {
+	manager *Manager
+
+	mu   sync.Mutex
+	keys map[string]*keyLock
+}
+
+// keyLock is the lock of a key, it's dropped once nobody holds it or waits for it.
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func NewPool(manager *Manager) *Pool {
+	return &Pool{
+		manager: manager,
+		keys:    make(map[string]*keyLock),
+	}
+}
+
+// Acquire returns a running container for the key, creating it when needed.
+func (p *Pool) Acquire(ctx context.Context, key string) (string, error) {
+	defer p.lock(key)()
+
+	summary, err := p.find(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if summary != nil {
+		if err := p.manager.start(ctx, summary.ID, summary.State); err != nil {
+			return "", err
+		}
+		return summary.ID, nil
+	}
+
+	if err := p.manager.EnsureImage(ctx, nil); err != nil {
+		return "", err
+	}
+
+	containerID, err := p.manager.createContainer(ctx, p.containerName(key), map[string]string{LabelPoolKey: key}, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := p.manager.start(ctx, containerID, container.StateCreated); err != nil {
+		return "", err
+	}
+	return containerID, nil
+}
+
+// Release force removes the container of the key, if any.
+func (p *Pool) Release(ctx context.Context, key string) error {
+	defer p.lock(key)()
+
+	summary, err := p.find(ctx, key)
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		return nil
+	}
+	if err := p.manager.client.ContainerRemove(ctx, summary.ID, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", summary.ID, err)
+	}
+	logging.Info("released isolated container", "key", key, "id", summary.ID)
+	return nil
+}
+
+// List returns every pooled container of the engagement.
+func (p *Pool) List(ctx context.Context) ([]container.Summary, error) {
+	summaries, err := p.manager.client.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", LabelEngagement, p.manager.engagementID)),
+			filters.Arg("label", LabelPoolKey),
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return summaries, nil
+}
+
+// ReleaseAll removes every pooled container of the engagement.
+func (p *Pool) ReleaseAll(ctx context.Context) error {
+	summaries, err := p.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if err := p.Release(ctx, summary.Labels[LabelPoolKey]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pool) find(ctx context.Context, key string) (*container.Summary, error) {
+	summaries, err := p.manager.client.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=%s", LabelEngagement, p.manager.engagementID)),
+			filters.Arg("label", fmt.Sprintf("%s=%s", LabelPoolKey, key)),
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	if len(summaries) == 0 {
+		return nil, nil
+	}
+	return &summaries[0], nil
+}
+
+// lock serialises the creation and removal of the container of a key, it returns the unlock.
+// NOTE: the lock of a key is counted rather than dropped on release, a caller waiting on it would otherwise hold a lock
+// that the next caller doesn't see.
+func (p *Pool) lock(key string) func() {
+	p.mu.Lock()
+	lock, ok := p.keys[key]
+	if !ok {
+		lock = &keyLock{}
+		p.keys[key] = lock
+	}
+	lock.refs++
+	p.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(p.keys, key)
+		}
+	}
+}
+
+func (p *Pool) containerName(key string) string {
+	// NOTE: docker container names only allow [a-zA-Z0-9_.-].
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, key)
+	return fmt.Sprintf("%s-%s", p.manager.ContainerName(), key)
+}
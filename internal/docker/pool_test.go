@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolLock(t *testing.T) {
+	pool := NewPool(nil)
+
+	unlock := pool.lock("session-1")
+	acquired := make(chan struct{})
+	go func() {
+		defer pool.lock("session-1")()
+		close(acquired)
+	}()
+	// NOTE: the waiter has to have counted itself in before the holder lets go.
+	for {
+		pool.mu.Lock()
+		refs := pool.keys["session-1"].refs
+		pool.mu.Unlock()
+		if refs == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	unlock()
+	<-acquired
+
+	var wg sync.WaitGroup
+	held := 0
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer pool.lock("session-1")()
+			held++
+		}()
+	}
+	wg.Wait()
+	if held != 50 {
+		t.Fatalf("expected the lock to serialise every holder, got %d", held)
+	}
+	if len(pool.keys) != 0 {
+		t.Fatalf("expected the locks to be dropped once released, got %v", pool.keys)
+	}
+}
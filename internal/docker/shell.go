@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/docker/api/types/container"
+	"golang.org/x/term"
+)
+
+// Shell attaches the operator's terminal to an interactive shell inside the engagement container.
+func (m *Manager) Shell(ctx context.Context, shell string) error {
+	containerID, err := m.EnsureContainer(ctx)
+	if err != nil {
+		return err
+	}
+
+	execResp, err := m.client.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          true,
+		WorkingDir:   WorkspaceDir,
+		Cmd:          []string{shell},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attachResp, err := m.client.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{Tty: true})
+	if err != nil {
+		return fmt.Errorf("failed to attach exec: %w", err)
+	}
+	defer attachResp.Close()
+
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		state, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("failed to put the terminal in raw mode: %w", err)
+		}
+		defer term.Restore(stdinFd, state)
+
+		if width, height, err := term.GetSize(stdinFd); err == nil {
+			_ = m.client.ContainerExecResize(ctx, execResp.ID, container.ResizeOptions{
+				Width:  uint(width),
+				Height: uint(height),
+			})
+		}
+	}
+
+	go func() {
+		_, _ = io.Copy(attachResp.Conn, os.Stdin)
+		_ = attachResp.CloseWrite()
+	}()
+
+	// NOTE: with a tty the output isn't multiplexed, so it can be copied as is.
+	if _, err := io.Copy(os.Stdout, attachResp.Reader); err != nil && err != io.EOF {
+		return fmt.Errorf("shell session ended: %w", err)
+	}
+	return nil
+}
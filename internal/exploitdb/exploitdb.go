@@ -0,0 +1,177 @@
+// Package exploitdb searches the CSV index of a local exploitdb checkout, the one searchsploit reads.
+package exploitdb
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IndexFile is the name of the exploit index at the root of an exploitdb checkout.
+const IndexFile = "files_exploits.csv"
+
+type Exploit struct {
+	ID          string   `json:"id"`
+	File        string   `json:"file"`
+	Path        string   `json:"path"`
+	Description string   `json:"description"`
+	Published   string   `json:"published"`
+	Author      string   `json:"author"`
+	Type        string   `json:"type"`
+	Platform    string   `json:"platform"`
+	Port        string   `json:"port,omitempty"`
+	Verified    bool     `json:"verified"`
+	Codes       []string `json:"codes,omitempty"`
+}
+
+// Index is the parsed exploit index along with the directory the exploit paths are relative to.
+type Index struct {
+	Root     string
+	Exploits []Exploit
+}
+
+// Query narrows a search down, every field that is set has to match.
+type Query struct {
+	// Terms all have to appear in the title, case insensitive, like searchsploit does.
+	Terms    []string
+	CVE      string
+	Platform string
+	Type     string
+}
+
+// Load reads the index of the checkout at path, which is either the checkout itself or its files_exploits.csv.
+func Load(path string) (*Index, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	file := path
+	if info.IsDir() {
+		file = filepath.Join(path, IndexFile)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	exploits, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	index := &Index{Root: filepath.Dir(file), Exploits: exploits}
+	for i := range index.Exploits {
+		index.Exploits[i].Path = filepath.Join(index.Root, filepath.FromSlash(index.Exploits[i].File))
+	}
+	return index, nil
+}
+
+// Parse reads an exploit index. the columns are looked up by their header, the index gained a few over the years.
+func Parse(r io.Reader) ([]Exploit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"id", "file", "description"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing the %s column", required)
+		}
+	}
+
+	var exploits []Exploit
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return exploits, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		published := field("date_published")
+		if published == "" {
+			published = field("date")
+		}
+		exploit := Exploit{
+			ID:          field("id"),
+			File:        field("file"),
+			Description: field("description"),
+			Published:   published,
+			Author:      field("author"),
+			Type:        field("type"),
+			Platform:    field("platform"),
+			Port:        field("port"),
+			Verified:    field("verified") == "1",
+		}
+		if exploit.Port == "0" {
+			exploit.Port = ""
+		}
+		for _, code := range strings.Split(field("codes"), ";") {
+			if code = strings.TrimSpace(code); code != "" {
+				exploit.Codes = append(exploit.Codes, code)
+			}
+		}
+		exploits = append(exploits, exploit)
+	}
+}
+
+// Search returns the exploits matching the query, newest first.
+func (index *Index) Search(query Query) []Exploit {
+	terms := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		if term = strings.ToLower(strings.TrimSpace(term)); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	cve := strings.ToUpper(strings.TrimSpace(query.CVE))
+
+	var matches []Exploit
+	for i := len(index.Exploits) - 1; i >= 0; i-- {
+		exploit := index.Exploits[i]
+		if query.Platform != "" && !strings.EqualFold(exploit.Platform, query.Platform) {
+			continue
+		}
+		if query.Type != "" && !strings.EqualFold(exploit.Type, query.Type) {
+			continue
+		}
+		if cve != "" && !hasCode(exploit, cve) {
+			continue
+		}
+		description := strings.ToLower(exploit.Description)
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(description, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, exploit)
+		}
+	}
+	return matches
+}
+
+func hasCode(exploit Exploit, code string) bool {
+	for _, known := range exploit.Codes {
+		if strings.EqualFold(known, code) {
+			return true
+		}
+	}
+	return false
+}
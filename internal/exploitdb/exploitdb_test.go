@@ -0,0 +1,31 @@
+package exploitdb
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	index, err := Load("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Exploits) != 4 {
+		t.Fatalf("expected 4 exploits, got %d", len(index.Exploits))
+	}
+	vsftpd := index.Exploits[0]
+	if vsftpd.Port != "21" || !vsftpd.Verified || vsftpd.Path != filepath.Join("testdata", "exploits", "unix", "remote", "49757.py") {
+		t.Fatalf("unexpected exploit: %+v", vsftpd)
+	}
+
+	apache := index.Search(Query{Terms: []string{"APACHE", "http"}})
+	if len(apache) != 2 || apache[0].ID != "50406" {
+		t.Fatalf("expected both httpd exploits, latest first, got %+v", apache)
+	}
+	if byCVE := index.Search(Query{CVE: "cve-2021-41773"}); len(byCVE) != 2 {
+		t.Fatalf("expected the exploits listing the CVE among their codes, got %+v", byCVE)
+	}
+	if filtered := index.Search(Query{Terms: []string{"apache"}, Platform: "java", Type: "remote"}); len(filtered) != 1 || filtered[0].ID != "50592" {
+		t.Fatalf("unexpected filtered results: %+v", filtered)
+	}
+}
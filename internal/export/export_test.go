@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/report"
+)
+
+func testDocument() Document {
+	findings := []finding.Finding{
+		{ID: "2", Title: "Server banner", Severity: finding.SeverityInfo, HostID: "h1", Port: 22, Protocol: "tcp", CreatedAt: 1760000000},
+		{
+			ID:          "1",
+			Fingerprint: "abc",
+			Title:       "SQL injection in login",
+			Severity:    finding.SeverityCritical,
+			HostID:      "h1",
+			Port:        443,
+			Protocol:    "tcp",
+			Location:    "https://10.10.10.5/login",
+			Description: "Fixed in CVE-2024-1234 too.",
+			Evidence:    "see 20251020-090000-call1/dump.txt",
+			References:  []string{"cwe-89", "CVE-2024-1234", "https://owasp.org/www-community/attacks/SQL_Injection"},
+			CVSSVector:  "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			CVSSScore:   9.8,
+			CreatedAt:   1760000100,
+		},
+	}
+	hosts := []inventory.Host{{ID: "h1", Address: "10.10.10.5", Hostname: "web01"}}
+	evidence := []report.Evidence{
+		{Path: "20251020-090000-call1/dump.txt", Size: 3, SHA256: strings.Repeat("a", 64)},
+		{Path: "other.png", Size: 1},
+	}
+	return NewDocument(Normalize(findings, hosts, evidence), "acme", time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC))
+}
+
+func TestNormalize(t *testing.T) {
+	findings := testDocument().Findings
+	if len(findings) != 2 || findings[0].ID != "1" {
+		t.Fatalf("expected the critical finding first, got %+v", findings)
+	}
+	f := findings[0]
+	if f.CVSS == nil || f.CVSS.Score != 9.8 || f.CVSS.Rating != "Critical" || f.CVSS.Version != "3.1" {
+		t.Errorf("unexpected CVSS %+v", f.CVSS)
+	}
+	if strings.Join(f.CWE, ",") != "CWE-89" || strings.Join(f.CVE, ",") != "CVE-2024-1234" || len(f.References) != 1 {
+		t.Errorf("expected the CWE and CVE IDs split from the references, got %v %v %v", f.CWE, f.CVE, f.References)
+	}
+	if f.Asset.Host != "10.10.10.5" || f.Asset.Hostname != "web01" || f.Asset.Port != 443 {
+		t.Errorf("unexpected asset %+v", f.Asset)
+	}
+	if len(f.Evidence.Files) != 1 || f.Evidence.Files[0].Path != "20251020-090000-call1/dump.txt" {
+		t.Errorf("expected the evidence file the finding names, got %+v", f.Evidence.Files)
+	}
+	if findings[1].CVSS != nil || findings[1].CWE == nil {
+		t.Errorf("expected no CVSS and empty lists for the banner, got %+v", findings[1])
+	}
+}
+
+func TestWrite(t *testing.T) {
+	document := testDocument()
+
+	var sarif bytes.Buffer
+	if err := Write(&sarif, FormatSARIF, document); err != nil {
+		t.Fatal(err)
+	}
+	var log SARIF
+	if err := json.Unmarshal(sarif.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	result := log.Runs[0].Results[0]
+	if result.RuleID != "sql-injection-in-login" || result.Level != "error" || result.Properties["security-severity"] != "9.8" {
+		t.Errorf("unexpected SARIF result %+v", result)
+	}
+	if result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "https://10.10.10.5/login" {
+		t.Errorf("expected the URL as the location, got %+v", result.Locations)
+	}
+	if level := log.Runs[0].Results[1].Level; level != "note" {
+		t.Errorf("expected info to map to note, got %s", level)
+	}
+
+	var ndjson bytes.Buffer
+	if err := Write(&ndjson, FormatNDJSON, document); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(ndjson.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], `{"schema_version":1,"id":"1"`) {
+		t.Errorf("expected a finding per line, got %q", ndjson.String())
+	}
+
+	var rows bytes.Buffer
+	if err := Write(&rows, FormatCSV, document); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&rows).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(records[1]) != len(CSVHeader) || records[1][4] != "9.8" || records[1][12] != "CWE-89" {
+		t.Errorf("unexpected CSV %q", records)
+	}
+}
@@ -0,0 +1,284 @@
+// Package export writes the findings of the engagement in the formats other tools read: a JSON document and NDJSON
+// following findings.schema.json, CSV for spreadsheets and SARIF 2.1.0 for code scanning dashboards and trackers.
+package export
+
+import (
+	"cmp"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yyovil/tandem/internal/cvss"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/report"
+	"github.com/yyovil/tandem/internal/version"
+)
+
+// SchemaVersion is the version of findings.schema.json. it changes only when a field is removed or its meaning
+// changes, new optional fields keep it.
+const SchemaVersion = 1
+
+// Schema is the JSON schema of the JSON and NDJSON exports.
+//
+//go:embed findings.schema.json
+var Schema []byte
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+	FormatSARIF  Format = "sarif"
+)
+
+var Formats = []Format{FormatSARIF, FormatJSON, FormatCSV, FormatNDJSON}
+
+// Document is the JSON export.
+type Document struct {
+	SchemaVersion int       `json:"schema_version"`
+	Engagement    string    `json:"engagement,omitempty"`
+	GeneratedAt   string    `json:"generated_at"` // RFC 3339
+	Tool          Tool      `json:"tool"`
+	Findings      []Finding `json:"findings"`
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Finding is the normalized finding, one line of the NDJSON export.
+type Finding struct {
+	SchemaVersion int      `json:"schema_version"`
+	ID            string   `json:"id"`
+	Fingerprint   string   `json:"fingerprint"`
+	Title         string   `json:"title"`
+	Severity      string   `json:"severity"`
+	CVSS          *CVSS    `json:"cvss"`
+	CWE           []string `json:"cwe"`
+	CVE           []string `json:"cve"`
+	References    []string `json:"references"`
+	Asset         Asset    `json:"asset"`
+	Description   string   `json:"description"`
+	Evidence      Evidence `json:"evidence"`
+	Remediation   string   `json:"remediation"`
+	Source        string   `json:"source"`
+	FirstSeen     string   `json:"first_seen"` // RFC 3339
+	LastSeen      string   `json:"last_seen"`  // RFC 3339
+}
+
+type CVSS struct {
+	Version string  `json:"version"`
+	Vector  string  `json:"vector"`
+	Score   float64 `json:"score"`
+	Rating  string  `json:"rating"`
+}
+
+// Asset is where the finding is, every field is empty when it isn't known.
+type Asset struct {
+	Host     string `json:"host"`
+	Hostname string `json:"hostname"`
+	Port     int64  `json:"port"`
+	Protocol string `json:"protocol"`
+	Location string `json:"location"`
+}
+
+type Evidence struct {
+	Text  string         `json:"text"`
+	Files []EvidenceFile `json:"files"`
+}
+
+// EvidenceFile is a file of the evidence directory the finding refers to.
+type EvidenceFile struct {
+	Path   string `json:"path"` // relative to the evidence directory
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+var (
+	cwePattern = regexp.MustCompile(`(?i)\bCWE-(\d+)\b`)
+	cvePattern = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+	cweID      = regexp.MustCompile(`(?i)^CWE-(\d+)$`)
+	cveID      = regexp.MustCompile(`(?i)^CVE-\d{4}-\d{4,}$`)
+)
+
+// Normalize turns the findings into their exported form, most severe first. the host of a finding is looked up in
+// hosts, and the files of evidence named in its evidence or description are referenced along with their checksums.
+func Normalize(findings []finding.Finding, hosts []inventory.Host, evidence []report.Evidence) []Finding {
+	byID := make(map[string]inventory.Host, len(hosts))
+	for _, host := range hosts {
+		byID[host.ID] = host
+	}
+	findings = slices.Clone(findings)
+	slices.SortStableFunc(findings, finding.Compare)
+
+	normalized := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		host := byID[f.HostID]
+		out := Finding{
+			SchemaVersion: SchemaVersion,
+			ID:            f.ID,
+			Fingerprint:   f.Fingerprint,
+			Title:         f.Title,
+			Severity:      string(f.Severity),
+			CWE:           []string{},
+			CVE:           []string{},
+			References:    []string{},
+			Asset: Asset{
+				Host:     host.Address,
+				Hostname: host.Hostname,
+				Port:     f.Port,
+				Protocol: f.Protocol,
+				Location: f.Location,
+			},
+			Description: f.Description,
+			Evidence:    Evidence{Text: f.Evidence, Files: []EvidenceFile{}},
+			Remediation: f.Remediation,
+			Source:      f.Source,
+			FirstSeen:   timestamp(f.CreatedAt),
+			LastSeen:    timestamp(cmp.Or(f.UpdatedAt, f.CreatedAt)),
+		}
+		if v, err := cvss.Parse(f.CVSSVector); err == nil {
+			out.CVSS = &CVSS{
+				Version: string(v.Version),
+				Vector:  v.String(),
+				Score:   f.CVSSScore,
+				Rating:  string(cvss.RatingOf(f.CVSSScore)),
+			}
+		}
+		for _, reference := range f.References {
+			reference = strings.TrimSpace(reference)
+			switch {
+			case reference == "":
+			case cweID.MatchString(reference):
+				out.CWE = appendUnique(out.CWE, "CWE-"+cweID.FindStringSubmatch(reference)[1])
+			case cveID.MatchString(reference):
+				out.CVE = appendUnique(out.CVE, strings.ToUpper(reference))
+			default:
+				out.References = appendUnique(out.References, reference)
+			}
+		}
+		// NOTE: the IDs mentioned in the prose count too, agents and scanners often put them there alone.
+		for _, text := range []string{f.Title, f.Description} {
+			for _, match := range cwePattern.FindAllStringSubmatch(text, -1) {
+				out.CWE = appendUnique(out.CWE, "CWE-"+match[1])
+			}
+			for _, match := range cvePattern.FindAllString(text, -1) {
+				out.CVE = appendUnique(out.CVE, strings.ToUpper(match))
+			}
+		}
+		for _, file := range evidence {
+			if mentions(f.Evidence, file.Path) || mentions(f.Description, file.Path) {
+				out.Evidence.Files = append(out.Evidence.Files, EvidenceFile{Path: file.Path, Size: file.Size, SHA256: file.SHA256})
+			}
+		}
+		normalized = append(normalized, out)
+	}
+	return normalized
+}
+
+// NewDocument wraps the findings in the JSON export.
+func NewDocument(findings []Finding, engagement string, generatedAt time.Time) Document {
+	return Document{
+		SchemaVersion: SchemaVersion,
+		Engagement:    engagement,
+		GeneratedAt:   generatedAt.UTC().Format(time.RFC3339),
+		Tool:          Tool{Name: "tandem", Version: version.Version},
+		Findings:      findings,
+	}
+}
+
+// Write writes the findings in a format.
+func Write(w io.Writer, format Format, document Document) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, f := range document.Findings {
+			if err := encoder.Encode(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		return writeCSV(w, document.Findings)
+	case FormatSARIF:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(NewSARIF(document))
+	}
+	return fmt.Errorf("unknown format %q, use one of sarif, json, csv or ndjson", format)
+}
+
+// CSVHeader is the header row of the CSV export, the lists in its cells are separated by semicolons.
+var CSVHeader = []string{
+	"id", "fingerprint", "title", "severity", "cvss_score", "cvss_vector", "cvss_version",
+	"host", "hostname", "port", "protocol", "location", "cwe", "cve", "references",
+	"description", "evidence", "evidence_files", "remediation", "source", "first_seen", "last_seen",
+}
+
+func writeCSV(w io.Writer, findings []Finding) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		var score, vector, cvssVersion, port string
+		if f.CVSS != nil {
+			score = strconv.FormatFloat(f.CVSS.Score, 'f', 1, 64)
+			vector, cvssVersion = f.CVSS.Vector, f.CVSS.Version
+		}
+		if f.Asset.Port > 0 {
+			port = strconv.FormatInt(f.Asset.Port, 10)
+		}
+		files := make([]string, len(f.Evidence.Files))
+		for i, file := range f.Evidence.Files {
+			files[i] = file.Path
+		}
+		if err := writer.Write([]string{
+			f.ID, f.Fingerprint, f.Title, f.Severity, score, vector, cvssVersion,
+			f.Asset.Host, f.Asset.Hostname, port, f.Asset.Protocol, f.Asset.Location,
+			strings.Join(f.CWE, ";"), strings.Join(f.CVE, ";"), strings.Join(f.References, ";"),
+			f.Description, f.Evidence.Text, strings.Join(files, ";"), f.Remediation, f.Source, f.FirstSeen, f.LastSeen,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// mentions reports whether the text names a file of evidence, by its path or by its name.
+func mentions(text, file string) bool {
+	if text == "" {
+		return false
+	}
+	return strings.Contains(text, file) || strings.Contains(text, path.Base(file))
+}
+
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func timestamp(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,235 @@
+package export
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SARIFSchema is the schema the SARIF export follows.
+const SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// SARIF is the subset of a SARIF 2.1.0 log the export fills in.
+type SARIF struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool       SARIFTool      `json:"tool"`
+	Results    []SARIFResult  `json:"results"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	ShortDescription SARIFMessage   `json:"shortDescription"`
+	FullDescription  *SARIFMessage  `json:"fullDescription,omitempty"`
+	Help             *SARIFMessage  `json:"help,omitempty"`
+	HelpURI          string         `json:"helpUri,omitempty"`
+	Properties       map[string]any `json:"properties"`
+}
+
+type SARIFMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]any    `json:"properties"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SARIFLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// securitySeverity stands in for the CVSS score of a finding without a vector, in the middle of the range of its
+// severity, so that dashboards ranking by security-severity rank it where it belongs.
+var securitySeverity = map[string]float64{
+	"critical": 9.5,
+	"high":     8.0,
+	"medium":   5.5,
+	"low":      2.0,
+	"info":     0,
+}
+
+// NewSARIF maps the export onto a SARIF log with a single run. the findings sharing a title share a rule, each finding
+// is a result of it.
+func NewSARIF(document Document) SARIF {
+	run := SARIFRun{
+		Tool: SARIFTool{Driver: SARIFDriver{
+			Name:           document.Tool.Name,
+			Version:        document.Tool.Version,
+			InformationURI: "https://github.com/yyovil/tandem",
+			Rules:          []SARIFRule{},
+		}},
+		Results: []SARIFResult{},
+	}
+	if document.Engagement != "" {
+		run.Properties = map[string]any{"engagement": document.Engagement}
+	}
+
+	rules := make(map[string]int)
+	for _, f := range document.Findings {
+		id := ruleID(f.Title)
+		index, ok := rules[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(id, f))
+		}
+
+		score, ok := securitySeverity[f.Severity]
+		if f.CVSS != nil {
+			score, ok = f.CVSS.Score, true
+		}
+		result := SARIFResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     level(f.Severity),
+			Message:   SARIFMessage{Text: message(f)},
+			Properties: map[string]any{
+				"id":       f.ID,
+				"severity": f.Severity,
+				"source":   f.Source,
+			},
+		}
+		if ok {
+			result.Properties["security-severity"] = strconv.FormatFloat(score, 'f', 1, 64)
+		}
+		if f.CVSS != nil {
+			result.Properties["cvss"] = f.CVSS
+		}
+		if f.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{"tandem/v1": f.Fingerprint}
+		}
+		if location, ok := sarifLocation(f.Asset); ok {
+			result.Locations = []SARIFLocation{location}
+		}
+		if f.Evidence.Text != "" {
+			result.Properties["evidence"] = f.Evidence.Text
+		}
+		if len(f.Evidence.Files) > 0 {
+			result.Properties["evidence_files"] = f.Evidence.Files
+		}
+		run.Results = append(run.Results, result)
+	}
+	return SARIF{Schema: SARIFSchema, Version: "2.1.0", Runs: []SARIFRun{run}}
+}
+
+func newSARIFRule(id string, f Finding) SARIFRule {
+	tags := []string{"security"}
+	tags = append(tags, f.CWE...)
+	tags = append(tags, f.CVE...)
+	rule := SARIFRule{
+		ID:               id,
+		Name:             f.Title,
+		ShortDescription: SARIFMessage{Text: f.Title},
+		Properties:       map[string]any{"tags": tags},
+	}
+	if f.Description != "" {
+		rule.FullDescription = &SARIFMessage{Text: f.Description}
+	}
+	if f.Remediation != "" {
+		rule.Help = &SARIFMessage{Text: f.Remediation, Markdown: f.Remediation}
+	}
+	for _, reference := range f.References {
+		if u, err := url.Parse(reference); err == nil && u.Scheme != "" && u.Host != "" {
+			rule.HelpURI = reference
+			break
+		}
+	}
+	return rule
+}
+
+// ruleID derives a stable rule ID from the title of a finding, e.g. sql-injection-in-the-login-form.
+func ruleID(title string) string {
+	id := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if id == "" {
+		return "finding"
+	}
+	return id
+}
+
+// level maps a severity onto the levels of SARIF, which has none below note.
+func level(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	}
+	return "note"
+}
+
+func message(f Finding) string {
+	where := assetName(f.Asset)
+	if where == "" {
+		return f.Title
+	}
+	return fmt.Sprintf("%s at %s", f.Title, where)
+}
+
+// assetName is the host, port and location of an asset, e.g. 10.10.10.5:443/tcp https://10.10.10.5/login.
+func assetName(asset Asset) string {
+	name := cmp.Or(asset.Host, asset.Hostname)
+	if asset.Port > 0 {
+		name = fmt.Sprintf("%s:%d/%s", name, asset.Port, asset.Protocol)
+	}
+	return strings.TrimSpace(name + " " + asset.Location)
+}
+
+// sarifLocation points at the URL of a finding when its location is one, and names the host and port it's on.
+func sarifLocation(asset Asset) (SARIFLocation, bool) {
+	var location SARIFLocation
+	if u, err := url.Parse(asset.Location); err == nil && u.Scheme != "" && u.Host != "" {
+		location.PhysicalLocation = &SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: asset.Location}}
+	}
+	if host := cmp.Or(asset.Host, asset.Hostname); host != "" {
+		name := host
+		if asset.Port > 0 {
+			name = fmt.Sprintf("%s:%d/%s", host, asset.Port, asset.Protocol)
+		}
+		location.LogicalLocations = []SARIFLogicalLocation{{Name: name, FullyQualifiedName: assetName(asset), Kind: "resource"}}
+	}
+	return location, location.PhysicalLocation != nil || location.LogicalLocations != nil
+}
@@ -0,0 +1,254 @@
+package finding
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yyovil/tandem/internal/cvss"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/pubsub"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Severities lists the severities from the least to the most severe.
+var Severities = []Severity{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// Rank orders the severities, unknown ones rank below info.
+func (s Severity) Rank() int {
+	for i, severity := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseSeverity maps the severity names scanners use onto ours, anything unknown is info.
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low":
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+// SeverityOf maps the qualitative severity of a CVSS score onto ours, a score of 0 is info.
+func SeverityOf(rating cvss.Rating) Severity {
+	switch rating {
+	case cvss.RatingCritical:
+		return SeverityCritical
+	case cvss.RatingHigh:
+		return SeverityHigh
+	case cvss.RatingMedium:
+		return SeverityMedium
+	case cvss.RatingLow:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+// Finding is a vulnerability or an observation worth reporting.
+type Finding struct {
+	ID          string
+	Fingerprint string
+	// HostID is the inventory host the finding is about, if any.
+	HostID      string
+	Port        int64
+	Protocol    string
+	Location    string
+	Title       string
+	Severity    Severity
+	Description string
+	Evidence    string
+	Remediation string
+	References  []string
+	Source      string
+	// CVSSVector is the CVSS v3.1 or v4.0 vector of the finding, the severity follows from its score when it's set.
+	CVSSVector string
+	CVSSScore  float64
+	CreatedAt  int64
+	UpdatedAt  int64
+}
+
+// Compare orders the findings from the most to the least severe: by severity, then by CVSS score, then the first
+// found first.
+func Compare(a, b Finding) int {
+	return cmp.Or(
+		cmp.Compare(b.Severity.Rank(), a.Severity.Rank()),
+		cmp.Compare(b.CVSSScore, a.CVSSScore),
+		cmp.Compare(a.CreatedAt, b.CreatedAt),
+	)
+}
+
+// Service keeps the findings of the engagement. recording the same finding twice updates the one already recorded.
+type Service interface {
+	pubsub.Subscriber[Finding]
+	Record(ctx context.Context, finding Finding) (Finding, error)
+	Get(ctx context.Context, id string) (Finding, error)
+	List(ctx context.Context) ([]Finding, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type service struct {
+	*pubsub.Broker[Finding]
+	q db.Querier
+}
+
+func (s *service) Record(ctx context.Context, finding Finding) (Finding, error) {
+	if finding.CVSSVector != "" {
+		vector, err := cvss.Parse(finding.CVSSVector)
+		if err != nil {
+			return Finding{}, fmt.Errorf("invalid CVSS vector: %w", err)
+		}
+		finding.CVSSVector = vector.String()
+		finding.CVSSScore = vector.Score()
+		finding.Severity = SeverityOf(vector.Rating())
+	}
+	if finding.Severity == "" {
+		finding.Severity = SeverityInfo
+	}
+	if finding.References == nil {
+		finding.References = []string{}
+	}
+	refs, err := json.Marshal(finding.References)
+	if err != nil {
+		return Finding{}, err
+	}
+
+	id := uuid.New().String()
+	dbFinding, err := s.q.UpsertFinding(ctx, db.UpsertFindingParams{
+		ID:          id,
+		Fingerprint: Fingerprint(finding),
+		HostID:      sql.NullString{String: finding.HostID, Valid: finding.HostID != ""},
+		Port:        finding.Port,
+		Protocol:    finding.Protocol,
+		Location:    finding.Location,
+		Title:       finding.Title,
+		Severity:    string(finding.Severity),
+		Description: finding.Description,
+		Evidence:    finding.Evidence,
+		Remediation: finding.Remediation,
+		Refs:        string(refs),
+		Source:      finding.Source,
+		CvssVector:  finding.CVSSVector,
+		CvssScore:   finding.CVSSScore,
+	})
+	if err != nil {
+		return Finding{}, err
+	}
+
+	recorded := fromDBItem(dbFinding)
+	if recorded.ID == id {
+		s.Publish(pubsub.CreatedEvent, recorded)
+	} else {
+		s.Publish(pubsub.UpdatedEvent, recorded)
+	}
+	return recorded, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (Finding, error) {
+	dbFinding, err := s.q.GetFinding(ctx, id)
+	if err != nil {
+		return Finding{}, err
+	}
+	return fromDBItem(dbFinding), nil
+}
+
+func (s *service) List(ctx context.Context) ([]Finding, error) {
+	dbFindings, err := s.q.ListFindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	findings := make([]Finding, len(dbFindings))
+	for i, dbFinding := range dbFindings {
+		findings[i] = fromDBItem(dbFinding)
+	}
+	return findings, nil
+}
+
+func (s *service) Delete(ctx context.Context, id string) error {
+	finding, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.q.DeleteFinding(ctx, id); err != nil {
+		return err
+	}
+	s.Publish(pubsub.DeletedEvent, finding)
+	return nil
+}
+
+// Fingerprint identifies a finding across scans and imports: the same issue reported by the same source at the same place.
+func Fingerprint(finding Finding) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		finding.Source,
+		finding.HostID,
+		strconv.FormatInt(finding.Port, 10),
+		finding.Protocol,
+		finding.Location,
+		strings.ToLower(finding.Title),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func fromDBItem(item db.Finding) Finding {
+	var refs []string
+	// NOTE: refs is always written as a JSON array, a broken one just means no references.
+	_ = json.Unmarshal([]byte(item.Refs), &refs)
+	return Finding{
+		ID:          item.ID,
+		Fingerprint: item.Fingerprint,
+		HostID:      item.HostID.String,
+		Port:        item.Port,
+		Protocol:    item.Protocol,
+		Location:    item.Location,
+		Title:       item.Title,
+		Severity:    Severity(item.Severity),
+		Description: item.Description,
+		Evidence:    item.Evidence,
+		Remediation: item.Remediation,
+		References:  refs,
+		Source:      item.Source,
+		CVSSVector:  item.CvssVector,
+		CVSSScore:   item.CvssScore,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	broker := pubsub.NewBroker[Finding]()
+	return &service{
+		broker,
+		q,
+	}
+}
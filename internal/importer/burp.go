@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+type burpIssues struct {
+	Issues []struct {
+		Name string `xml:"name"`
+		Host struct {
+			IP  string `xml:"ip,attr"`
+			URL string `xml:",chardata"`
+		} `xml:"host"`
+		Path                  string `xml:"path"`
+		Location              string `xml:"location"`
+		Severity              string `xml:"severity"`
+		Confidence            string `xml:"confidence"`
+		IssueBackground       string `xml:"issueBackground"`
+		IssueDetail           string `xml:"issueDetail"`
+		RemediationBackground string `xml:"remediationBackground"`
+		RemediationDetail     string `xml:"remediationDetail"`
+		References            string `xml:"references"`
+		Classifications       string `xml:"vulnerabilityClassifications"`
+		RequestResponses      []struct {
+			Request struct {
+				Base64 bool   `xml:"base64,attr"`
+				Value  string `xml:",chardata"`
+			} `xml:"request"`
+		} `xml:"requestresponse"`
+	} `xml:"issue"`
+}
+
+var (
+	htmlTag  = regexp.MustCompile(`<[^>]*>`)
+	htmlHref = regexp.MustCompile(`href="([^"]+)"`)
+)
+
+// parseBurp parses the XML report burp suite exports issues to.
+func parseBurp(r io.Reader) (*Result, error) {
+	var report burpIssues
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse burp xml: %w", err)
+	}
+
+	hosts := newHostSet()
+	result := &Result{}
+	for _, issue := range report.Issues {
+		base, err := url.Parse(strings.TrimSpace(issue.Host.URL))
+		if err != nil {
+			return nil, fmt.Errorf("issue %q has an invalid host %q: %w", issue.Name, issue.Host.URL, err)
+		}
+		address := issue.Host.IP
+		if address == "" {
+			address = base.Hostname()
+		}
+		port := urlPort(base)
+
+		hostname := ""
+		if net.ParseIP(base.Hostname()) == nil {
+			hostname = base.Hostname()
+		}
+		hosts.add(inventory.Host{
+			Address:  address,
+			Hostname: hostname,
+			Status:   "up",
+			Source:   string(FormatBurp),
+			Ports: []inventory.Port{{
+				Number:   port,
+				Protocol: "tcp",
+				State:    "open",
+				Service:  base.Scheme,
+				Source:   string(FormatBurp),
+			}},
+		})
+
+		description := stripHTML(issue.IssueBackground)
+		if detail := stripHTML(issue.IssueDetail); detail != "" {
+			description = strings.TrimSpace(description + "\n\n" + detail)
+		}
+		remediation := stripHTML(issue.RemediationDetail)
+		if remediation == "" {
+			remediation = stripHTML(issue.RemediationBackground)
+		}
+		var references []string
+		for _, match := range htmlHref.FindAllStringSubmatch(issue.References+issue.Classifications, -1) {
+			references = append(references, html.UnescapeString(match[1]))
+		}
+
+		var evidence strings.Builder
+		if issue.Confidence != "" {
+			fmt.Fprintf(&evidence, "confidence: %s\n", issue.Confidence)
+		}
+		for _, rr := range issue.RequestResponses {
+			request := rr.Request.Value
+			if rr.Request.Base64 {
+				decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(request))
+				if err != nil {
+					continue
+				}
+				request = string(decoded)
+			}
+			// NOTE: the request line is enough to replay the issue, the whole request would bloat the finding.
+			if line, _, _ := strings.Cut(request, "\n"); strings.TrimSpace(line) != "" {
+				fmt.Fprintf(&evidence, "request: %s\n", strings.TrimSpace(line))
+			}
+		}
+
+		location := strings.TrimRight(base.String(), "/") + issue.Path
+		if issue.Location != "" && issue.Location != issue.Path {
+			location = strings.TrimRight(base.String(), "/") + issue.Location
+		}
+		result.Findings = append(result.Findings, Finding{
+			Host: address,
+			Finding: finding.Finding{
+				Port:        port,
+				Protocol:    "tcp",
+				Location:    location,
+				Title:       issue.Name,
+				Severity:    finding.ParseSeverity(issue.Severity),
+				Description: description,
+				Evidence:    strings.TrimSpace(evidence.String()),
+				Remediation: remediation,
+				References:  references,
+				Source:      string(FormatBurp),
+			},
+		})
+	}
+	result.Hosts = hosts.list()
+	return result, nil
+}
+
+// urlPort returns the port of a URL, falling back to the default one of its scheme.
+func urlPort(u *url.URL) int64 {
+	if port, err := strconv.ParseInt(u.Port(), 10, 64); err == nil {
+		return port
+	}
+	if u.Scheme == "https" {
+		return 443
+	}
+	return 80
+}
+
+// stripHTML turns the HTML snippets burp writes into plain text.
+func stripHTML(s string) string {
+	s = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "</p>", "\n", "</li>", "\n").Replace(s)
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+	return strings.TrimSpace(s)
+}
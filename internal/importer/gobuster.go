@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+var (
+	// gobusterPath matches the lines of dir mode, e.g. /admin (Status: 301) [Size: 312] [--> http://target/admin/].
+	gobusterPath = regexp.MustCompile(`^(\S+)\s+\(Status:\s*(\d+)\)(.*)$`)
+	// gobusterFound matches the lines of dns and vhost mode, e.g. Found: dev.example.com [10.0.0.5].
+	gobusterFound = regexp.MustCompile(`^Found:\s+(\S+)(.*)$`)
+	// gobusterIPs picks the addresses out of dns mode's --show-ips output.
+	gobusterIPs = regexp.MustCompile(`\[([0-9a-fA-F.:,\s]+)\]`)
+)
+
+func looksLikeGobuster(head []byte) bool {
+	for _, line := range bytes.Split(head, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if gobusterPath.Match(line) || gobusterFound.Match(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGobuster parses the output of gobuster's dir, dns and vhost modes as written by -o. dir mode only prints paths,
+// so unless it was run with -e the target has to be passed along.
+func parseGobuster(r io.Reader, target string) (*Result, error) {
+	var base *url.URL
+	if target != "" {
+		var err error
+		base, err = url.Parse(strings.TrimRight(target, "/"))
+		if err != nil || base.Host == "" {
+			return nil, fmt.Errorf("target %q isn't a URL", target)
+		}
+	}
+
+	hosts := newHostSet()
+	// NOTE: the paths are grouped per base URL, one finding each, instead of flooding the findings with a finding per path.
+	paths := map[string][]string{}
+	var bases []*url.URL
+	var vhosts []string
+
+	err := lines(r, func(line string) error {
+		if match := gobusterPath.FindStringSubmatch(line); match != nil {
+			location, status, rest := match[1], match[2], strings.TrimSpace(match[3])
+			pathBase := base
+			if u, err := url.Parse(location); err == nil && u.Scheme != "" && u.Host != "" {
+				pathBase = &url.URL{Scheme: u.Scheme, Host: u.Host}
+				location = u.RequestURI()
+			}
+			if pathBase == nil {
+				return fmt.Errorf("gobuster dir output only has paths, pass the URL it was run against as the target")
+			}
+			key := pathBase.String()
+			if _, ok := paths[key]; !ok {
+				bases = append(bases, pathBase)
+			}
+			entry := fmt.Sprintf("%s (%s)", location, status)
+			if rest != "" {
+				entry += " " + rest
+			}
+			paths[key] = append(paths[key], entry)
+			return nil
+		}
+
+		if match := gobusterFound.FindStringSubmatch(line); match != nil {
+			name, rest := match[1], match[2]
+			if strings.Contains(rest, "Status:") {
+				vhosts = append(vhosts, strings.TrimSpace(name+" "+rest))
+				return nil
+			}
+			addresses := []string{}
+			if ips := gobusterIPs.FindStringSubmatch(rest); ips != nil {
+				for _, ip := range strings.Split(ips[1], ",") {
+					if ip = strings.TrimSpace(ip); net.ParseIP(ip) != nil {
+						addresses = append(addresses, ip)
+					}
+				}
+			}
+			if len(addresses) == 0 {
+				addresses = append(addresses, name)
+			}
+			for _, address := range addresses {
+				host := inventory.Host{Address: address, Source: string(FormatGobuster)}
+				if address != name {
+					host.Hostname = name
+				}
+				hosts.add(host)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, pathBase := range bases {
+		key := pathBase.String()
+		address := pathBase.Hostname()
+		port := urlPort(pathBase)
+		hosts.add(inventory.Host{
+			Address: address,
+			Status:  "up",
+			Source:  string(FormatGobuster),
+			Ports: []inventory.Port{{
+				Number:   port,
+				Protocol: "tcp",
+				State:    "open",
+				Service:  pathBase.Scheme,
+				Source:   string(FormatGobuster),
+			}},
+		})
+		result.Findings = append(result.Findings, Finding{
+			Host: address,
+			Finding: finding.Finding{
+				Port:        port,
+				Protocol:    "tcp",
+				Location:    key,
+				Title:       "Content discovered by directory brute forcing",
+				Severity:    finding.SeverityInfo,
+				Description: fmt.Sprintf("gobuster found %d paths on %s.", len(paths[key]), key),
+				Evidence:    strings.Join(paths[key], "\n"),
+				Source:      string(FormatGobuster),
+			},
+		})
+	}
+
+	if len(vhosts) > 0 {
+		if base == nil {
+			return nil, fmt.Errorf("gobuster vhost output doesn't name the server, pass the URL it was run against as the target")
+		}
+		result.Findings = append(result.Findings, Finding{
+			Host: base.Hostname(),
+			Finding: finding.Finding{
+				Port:        urlPort(base),
+				Protocol:    "tcp",
+				Location:    base.String(),
+				Title:       "Virtual hosts discovered by brute forcing",
+				Severity:    finding.SeverityInfo,
+				Description: fmt.Sprintf("gobuster found %d virtual hosts on %s.", len(vhosts), base.String()),
+				Evidence:    strings.Join(vhosts, "\n"),
+				Source:      string(FormatGobuster),
+			},
+		})
+	}
+	result.Hosts = hosts.list()
+	return result, nil
+}
@@ -0,0 +1,405 @@
+// Package importer turns the output of scanners run outside of tandem into hosts, ports and findings.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yyovil/tandem/internal/cvss"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+type Format string
+
+const (
+	FormatNessus   Format = "nessus"
+	FormatBurp     Format = "burp"
+	FormatNuclei   Format = "nuclei"
+	FormatMasscan  Format = "masscan"
+	FormatGobuster Format = "gobuster"
+	FormatNmap     Format = "nmap"
+)
+
+var Formats = []Format{FormatNessus, FormatBurp, FormatNuclei, FormatMasscan, FormatGobuster, FormatNmap}
+
+// Options tweak how a file gets imported.
+type Options struct {
+	// Format skips the detection when set.
+	Format Format
+	// Target is the base URL gobuster was pointed at, its output doesn't carry it.
+	Target string
+}
+
+// Result is what a parser got out of a file.
+type Result struct {
+	Hosts    []inventory.Host
+	Findings []Finding
+}
+
+// Finding is a finding along with the address of the host it was found on, if any.
+type Finding struct {
+	finding.Finding
+	Host string
+}
+
+// Import is a file that has been imported.
+type Import struct {
+	ID         string
+	Path       string
+	Format     Format
+	Hosts      int64
+	Ports      int64
+	Findings   int64
+	NotifiedAt int64
+	CreatedAt  int64
+}
+
+// Service imports files into the inventory and the findings, and keeps track of the imports the orchestrator hasn't heard about yet.
+type Service interface {
+	Import(ctx context.Context, path string, opts Options) (Import, error)
+	Pending(ctx context.Context) ([]Import, error)
+	// Notice tells the orchestrator about the imports along with what the inventory and the findings hold now.
+	Notice(ctx context.Context, imports []Import) (string, error)
+	MarkNotified(ctx context.Context, imports []Import) error
+}
+
+type service struct {
+	q         db.Querier
+	inventory inventory.Service
+	findings  finding.Service
+}
+
+func (s *service) Import(ctx context.Context, path string, opts Options) (Import, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Import{}, err
+	}
+	format := opts.Format
+	if format == "" {
+		format, err = Detect(path, data)
+		if err != nil {
+			return Import{}, err
+		}
+	}
+	result, err := Parse(format, bytes.NewReader(data), opts)
+	if err != nil {
+		return Import{}, err
+	}
+
+	// NOTE: hosts go first so the findings can point at them.
+	hostIDs := map[string]string{}
+	var ports int64
+	for _, host := range result.Hosts {
+		recorded, err := s.inventory.RecordHost(ctx, host)
+		if err != nil {
+			return Import{}, fmt.Errorf("failed to record host %s: %w", host.Address, err)
+		}
+		hostIDs[recorded.Address] = recorded.ID
+		ports += int64(len(host.Ports))
+	}
+
+	var findings int64
+	for _, f := range result.Findings {
+		if f.Host != "" {
+			if _, ok := hostIDs[f.Host]; !ok {
+				recorded, err := s.inventory.RecordHost(ctx, inventory.Host{Address: f.Host, Source: string(format)})
+				if err != nil {
+					return Import{}, fmt.Errorf("failed to record host %s: %w", f.Host, err)
+				}
+				hostIDs[f.Host] = recorded.ID
+			}
+			f.HostID = hostIDs[f.Host]
+		}
+		if _, err := s.findings.Record(ctx, f.Finding); err != nil {
+			return Import{}, fmt.Errorf("failed to record finding %q: %w", f.Title, err)
+		}
+		findings++
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	dbImport, err := s.q.CreateImport(ctx, db.CreateImportParams{
+		ID:       uuid.New().String(),
+		Path:     absPath,
+		Format:   string(format),
+		Hosts:    int64(len(hostIDs)),
+		Ports:    ports,
+		Findings: findings,
+	})
+	if err != nil {
+		return Import{}, err
+	}
+	return fromDBItem(dbImport), nil
+}
+
+func (s *service) Pending(ctx context.Context) ([]Import, error) {
+	dbImports, err := s.q.ListPendingImports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]Import, len(dbImports))
+	for i, dbImport := range dbImports {
+		imports[i] = fromDBItem(dbImport)
+	}
+	return imports, nil
+}
+
+// maxNoticeItems caps the hosts and findings listed in the notice, the rest are only counted.
+const maxNoticeItems = 20
+
+func (s *service) Notice(ctx context.Context, imports []Import) (string, error) {
+	if len(imports) == 0 {
+		return "", nil
+	}
+	hosts, err := s.inventory.ListHosts(ctx)
+	if err != nil {
+		return "", err
+	}
+	findings, err := s.findings.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<notice>\nnew scanner output has been imported since you last heard about it:\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&sb, "- %s (%s): %d hosts, %d ports, %d findings\n", imp.Path, imp.Format, imp.Hosts, imp.Ports, imp.Findings)
+	}
+
+	addresses := map[string]string{}
+	if len(hosts) > 0 {
+		fmt.Fprintf(&sb, "\nthe inventory now holds %d hosts:\n", len(hosts))
+		for i, host := range hosts {
+			addresses[host.ID] = host.Address
+			if i >= maxNoticeItems {
+				continue
+			}
+			sb.WriteString("- " + host.Address)
+			if host.Hostname != "" && host.Hostname != host.Address {
+				fmt.Fprintf(&sb, " (%s)", host.Hostname)
+			}
+			var open []string
+			for _, port := range host.Ports {
+				if port.State == "open" {
+					open = append(open, strings.TrimSpace(fmt.Sprintf("%d/%s %s", port.Number, port.Protocol, port.Service)))
+				}
+			}
+			if len(open) > 0 {
+				sb.WriteString(": " + strings.Join(open, ", "))
+			}
+			sb.WriteString("\n")
+		}
+		if len(hosts) > maxNoticeItems {
+			fmt.Fprintf(&sb, "- and %d more\n", len(hosts)-maxNoticeItems)
+		}
+	}
+
+	if len(findings) > 0 {
+		slices.SortStableFunc(findings, finding.Compare)
+		fmt.Fprintf(&sb, "\nthere are %d findings, the most severe ones:\n", len(findings))
+		for i, f := range findings {
+			if i >= maxNoticeItems {
+				break
+			}
+			fmt.Fprintf(&sb, "- [%s] %s", f.Severity, f.Title)
+			where := addresses[f.HostID]
+			if f.Port > 0 {
+				where = fmt.Sprintf("%s:%d", where, f.Port)
+			}
+			if f.Location != "" {
+				where = strings.TrimSpace(where + " " + f.Location)
+			}
+			if where != "" {
+				fmt.Fprintf(&sb, " at %s", where)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\ntake it into account when planning the follow-up work.\n</notice>")
+	return sb.String(), nil
+}
+
+func (s *service) MarkNotified(ctx context.Context, imports []Import) error {
+	for _, imp := range imports {
+		if err := s.q.MarkImportNotified(ctx, imp.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Detect guesses the format of a file from its extension and its first few kilobytes.
+func Detect(path string, data []byte) (Format, error) {
+	if strings.EqualFold(filepath.Ext(path), ".nessus") {
+		return FormatNessus, nil
+	}
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.TrimSpace(head)
+
+	switch {
+	case bytes.Contains(head, []byte("<NessusClientData_v2")):
+		return FormatNessus, nil
+	case bytes.Contains(head, []byte("<nmaprun")):
+		return FormatNmap, nil
+	case bytes.Contains(head, []byte("<issues")):
+		return FormatBurp, nil
+	case bytes.Contains(head, []byte(`"template-id"`)) || bytes.Contains(head, []byte(`"template_id"`)):
+		return FormatNuclei, nil
+	case bytes.HasPrefix(head, []byte("[")) || bytes.HasPrefix(head, []byte("{")):
+		if bytes.Contains(head, []byte(`"ports"`)) && bytes.Contains(head, []byte(`"ip"`)) {
+			return FormatMasscan, nil
+		}
+	case looksLikeGobuster(head):
+		return FormatGobuster, nil
+	}
+	return "", fmt.Errorf("couldn't detect the format of %s, pass one of %s explicitly", path, formatList())
+}
+
+// Parse parses a file of the given format.
+func Parse(format Format, r io.Reader, opts Options) (*Result, error) {
+	switch format {
+	case FormatNessus:
+		return parseNessus(r)
+	case FormatBurp:
+		return parseBurp(r)
+	case FormatNuclei:
+		return parseNuclei(r)
+	case FormatMasscan:
+		return parseMasscan(r)
+	case FormatGobuster:
+		return parseGobuster(r, opts.Target)
+	case FormatNmap:
+		return parseNmap(r)
+	}
+	return nil, fmt.Errorf("unknown format %q, expected one of %s", format, formatList())
+}
+
+func formatList() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// lines yields the non empty, trimmed lines of r.
+func lines(r io.Reader, yield func(line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := yield(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// hostSet merges the hosts a file mentions more than once, e.g. masscan writes a record per port.
+type hostSet struct {
+	order []string
+	hosts map[string]*inventory.Host
+}
+
+func newHostSet() *hostSet {
+	return &hostSet{hosts: map[string]*inventory.Host{}}
+}
+
+func (s *hostSet) add(host inventory.Host) {
+	ports := host.Ports
+	known, ok := s.hosts[host.Address]
+	if !ok {
+		s.order = append(s.order, host.Address)
+		host.Ports = nil
+		known = &host
+		s.hosts[host.Address] = known
+	}
+	if known.Hostname == "" {
+		known.Hostname = host.Hostname
+	}
+	if known.OS == "" {
+		known.OS = host.OS
+	}
+	if known.Status == "" {
+		known.Status = host.Status
+	}
+
+	for _, port := range ports {
+		merged := false
+		for i, knownPort := range known.Ports {
+			if knownPort.Number != port.Number || knownPort.Protocol != port.Protocol {
+				continue
+			}
+			if port.Service != "" {
+				known.Ports[i].Service = port.Service
+			}
+			if port.Product != "" {
+				known.Ports[i].Product = port.Product
+			}
+			if port.Version != "" {
+				known.Ports[i].Version = port.Version
+			}
+			merged = true
+			break
+		}
+		if !merged {
+			known.Ports = append(known.Ports, port)
+		}
+	}
+}
+
+func (s *hostSet) list() []inventory.Host {
+	hosts := make([]inventory.Host, len(s.order))
+	for i, address := range s.order {
+		hosts[i] = *s.hosts[address]
+	}
+	return hosts
+}
+
+// cvssVector keeps the CVSS vector a scanner reported if it parses, a broken one would keep the finding from being
+// recorded at all.
+func cvssVector(vector string) string {
+	if cvss.Validate(vector) != nil {
+		return ""
+	}
+	return vector
+}
+
+func fromDBItem(item db.Import) Import {
+	return Import{
+		ID:         item.ID,
+		Path:       item.Path,
+		Format:     Format(item.Format),
+		Hosts:      item.Hosts,
+		Ports:      item.Ports,
+		Findings:   item.Findings,
+		NotifiedAt: item.NotifiedAt.Int64,
+		CreatedAt:  item.CreatedAt,
+	}
+}
+
+func NewService(q db.Querier, inventory inventory.Service, findings finding.Service) Service {
+	return &service{
+		q:         q,
+		inventory: inventory,
+		findings:  findings,
+	}
+}
@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yyovil/tandem/internal/finding"
+)
+
+func parseTestdata(t *testing.T, name string, opts Options) (Format, *Result) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	format, err := Detect(name, data)
+	if err != nil {
+		t.Fatalf("detect failed: %v", err)
+	}
+	result, err := Parse(format, bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	return format, result
+}
+
+func TestParseNessus(t *testing.T) {
+	format, result := parseTestdata(t, "scan.nessus", Options{})
+	if format != FormatNessus {
+		t.Fatalf("detected %s", format)
+	}
+	if len(result.Hosts) != 1 {
+		t.Fatalf("expected 1 host, got %d", len(result.Hosts))
+	}
+	host := result.Hosts[0]
+	if host.Address != "10.0.0.5" || host.Hostname != "web.example.com" || host.OS != "Linux Kernel 5.4" {
+		t.Fatalf("unexpected host: %+v", host)
+	}
+	if len(host.Ports) != 2 || host.Ports[0].Number != 22 || host.Ports[1].Service != "www" {
+		t.Fatalf("unexpected ports: %+v", host.Ports)
+	}
+	if len(result.Findings) != 2 {
+		t.Fatalf("expected the 2 findings above info, got %d", len(result.Findings))
+	}
+	log4shell := result.Findings[1]
+	if log4shell.Severity != finding.SeverityCritical || log4shell.Host != "10.0.0.5" || log4shell.References[0] != "CVE-2021-44228" || log4shell.CVSSVector != "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H" {
+		t.Fatalf("unexpected finding: %+v", log4shell)
+	}
+	if refs := result.Findings[0].References; len(refs) != 2 {
+		t.Fatalf("expected the see also links as references, got %v", refs)
+	}
+}
+
+func TestParseBurp(t *testing.T) {
+	format, result := parseTestdata(t, "burp.xml", Options{})
+	if format != FormatBurp {
+		t.Fatalf("detected %s", format)
+	}
+	if len(result.Hosts) != 1 || result.Hosts[0].Address != "10.0.0.5" || result.Hosts[0].Ports[0].Number != 443 {
+		t.Fatalf("unexpected hosts: %+v", result.Hosts)
+	}
+	sqli := result.Findings[0]
+	if sqli.Severity != finding.SeverityHigh || sqli.Location != "https://web.example.com/search [q parameter]" {
+		t.Fatalf("unexpected finding: %+v", sqli)
+	}
+	if strings.Contains(sqli.Description, "<") || !strings.Contains(sqli.Evidence, "request: GET /search?q=1' HTTP/1.1") {
+		t.Fatalf("unexpected description or evidence: %q %q", sqli.Description, sqli.Evidence)
+	}
+	if len(sqli.References) != 1 {
+		t.Fatalf("unexpected references: %v", sqli.References)
+	}
+}
+
+func TestParseNuclei(t *testing.T) {
+	format, result := parseTestdata(t, "nuclei.jsonl", Options{})
+	if format != FormatNuclei {
+		t.Fatalf("detected %s", format)
+	}
+	if len(result.Hosts) != 2 || result.Hosts[0].Hostname != "web.example.com" || result.Hosts[1].Ports[0].Number != 8080 {
+		t.Fatalf("unexpected hosts: %+v", result.Hosts)
+	}
+	git := result.Findings[0]
+	if git.Severity != finding.SeverityMedium || git.Port != 443 || git.Location != "https://web.example.com/.git/config" || len(git.References) != 2 {
+		t.Fatalf("unexpected finding: %+v", git)
+	}
+	if title := result.Findings[1].Title; title != "Wappalyzer Technology Detection: nginx" {
+		t.Fatalf("unexpected title: %s", title)
+	}
+}
+
+func TestParseMasscan(t *testing.T) {
+	format, result := parseTestdata(t, "masscan.json", Options{})
+	if format != FormatMasscan {
+		t.Fatalf("detected %s", format)
+	}
+	if len(result.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(result.Hosts))
+	}
+	ports := result.Hosts[0].Ports
+	if len(ports) != 2 || ports[0].Service != "http" || ports[0].Product != "nginx/1.18.0" {
+		t.Fatalf("expected the banner to merge into the port, got %+v", ports)
+	}
+}
+
+func TestParseGobuster(t *testing.T) {
+	data, err := os.ReadFile("testdata/gobuster.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format, err := Detect("gobuster.txt", data); err != nil || format != FormatGobuster {
+		t.Fatalf("detected %s: %v", format, err)
+	}
+	if _, err := Parse(FormatGobuster, bytes.NewReader(data), Options{}); err == nil {
+		t.Fatal("expected dir output without a target to fail")
+	}
+
+	result, err := Parse(FormatGobuster, bytes.NewReader(data), Options{Target: "http://10.0.0.5/"})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Port != 80 || strings.Count(result.Findings[0].Evidence, "\n") != 2 {
+		t.Fatalf("expected one finding listing the 3 paths, got %+v", result.Findings)
+	}
+}
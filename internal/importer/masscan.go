@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+type masscanRecord struct {
+	IP    string `json:"ip"`
+	Ports []struct {
+		Port    int64  `json:"port"`
+		Proto   string `json:"proto"`
+		Status  string `json:"status"`
+		Service struct {
+			Name   string `json:"name"`
+			Banner string `json:"banner"`
+		} `json:"service"`
+	} `json:"ports"`
+}
+
+// parseMasscan parses masscan's -oJ and -oD output. -oJ isn't always valid JSON, the trailing commas and the closing
+// bracket of an interrupted scan are all over the place, so it is read a record per line.
+func parseMasscan(r io.Reader) (*Result, error) {
+	hosts := newHostSet()
+	lineNo := 0
+	err := lines(r, func(line string) error {
+		lineNo++
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ","))
+		if line == "" || !strings.HasPrefix(line, "{") {
+			return nil
+		}
+
+		var record masscanRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return fmt.Errorf("failed to parse masscan json line %d: %w", lineNo, err)
+		}
+		// NOTE: the { "finished": 1 } trailer has no ip.
+		if record.IP == "" {
+			return nil
+		}
+
+		host := inventory.Host{Address: record.IP, Status: "up", Source: string(FormatMasscan)}
+		for _, port := range record.Ports {
+			state := port.Status
+			if state == "" {
+				// NOTE: banner records only come for open ports and don't carry a status.
+				state = "open"
+			}
+			host.Ports = append(host.Ports, inventory.Port{
+				Number:   port.Port,
+				Protocol: port.Proto,
+				State:    state,
+				Service:  port.Service.Name,
+				Product:  strings.TrimSpace(port.Service.Banner),
+				Source:   string(FormatMasscan),
+			})
+		}
+		hosts.add(host)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Hosts: hosts.list()}, nil
+}
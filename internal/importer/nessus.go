@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+type nessusReport struct {
+	Hosts []struct {
+		Name       string `xml:"name,attr"`
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"HostProperties>tag"`
+		Items []struct {
+			Port         int64    `xml:"port,attr"`
+			Service      string   `xml:"svc_name,attr"`
+			Protocol     string   `xml:"protocol,attr"`
+			Severity     int      `xml:"severity,attr"`
+			PluginID     string   `xml:"pluginID,attr"`
+			PluginName   string   `xml:"pluginName,attr"`
+			Synopsis     string   `xml:"synopsis"`
+			Description  string   `xml:"description"`
+			Solution     string   `xml:"solution"`
+			PluginOutput string   `xml:"plugin_output"`
+			CVEs         []string `xml:"cve"`
+			SeeAlso      []string `xml:"see_also"`
+			CVSS3Vector  string   `xml:"cvss3_vector"`
+		} `xml:"ReportItem"`
+	} `xml:"Report>ReportHost"`
+}
+
+// nessusSeverities maps the 0 to 4 severity of a ReportItem.
+var nessusSeverities = []finding.Severity{finding.SeverityInfo, finding.SeverityLow, finding.SeverityMedium, finding.SeverityHigh, finding.SeverityCritical}
+
+// parseNessus parses a .nessus (v2) export. every item with a port becomes a port of its host, items above info become findings.
+func parseNessus(r io.Reader) (*Result, error) {
+	var report nessusReport
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse nessus xml: %w", err)
+	}
+
+	hosts := newHostSet()
+	result := &Result{}
+	for _, reportHost := range report.Hosts {
+		host := inventory.Host{Address: reportHost.Name, Status: "up", Source: string(FormatNessus)}
+		for _, property := range reportHost.Properties {
+			value := strings.TrimSpace(property.Value)
+			switch property.Name {
+			case "host-ip":
+				host.Address = value
+			case "host-fqdn", "hostname":
+				if host.Hostname == "" {
+					host.Hostname = value
+				}
+			case "operating-system":
+				host.OS = value
+			}
+		}
+		if host.Hostname == "" && host.Address != reportHost.Name {
+			host.Hostname = reportHost.Name
+		}
+
+		for _, item := range reportHost.Items {
+			if item.Port > 0 {
+				host.Ports = append(host.Ports, inventory.Port{
+					Number:   item.Port,
+					Protocol: item.Protocol,
+					State:    "open",
+					Service:  strings.TrimSuffix(item.Service, "?"),
+					Source:   string(FormatNessus),
+				})
+			}
+			if item.Severity <= 0 || item.Severity >= len(nessusSeverities) {
+				continue
+			}
+
+			description := strings.TrimSpace(item.Synopsis)
+			if details := strings.TrimSpace(item.Description); details != "" {
+				description = strings.TrimSpace(description + "\n\n" + details)
+			}
+			references := append([]string{}, item.CVEs...)
+			for _, seeAlso := range item.SeeAlso {
+				references = append(references, strings.Fields(seeAlso)...)
+			}
+			result.Findings = append(result.Findings, Finding{
+				Host: host.Address,
+				Finding: finding.Finding{
+					Port:        item.Port,
+					Protocol:    item.Protocol,
+					Location:    "nessus plugin " + item.PluginID,
+					Title:       item.PluginName,
+					Severity:    nessusSeverities[item.Severity],
+					Description: description,
+					Evidence:    strings.TrimSpace(item.PluginOutput),
+					Remediation: strings.TrimSpace(item.Solution),
+					References:  references,
+					Source:      string(FormatNessus),
+					CVSSVector:  cvssVector(item.CVSS3Vector),
+				},
+			})
+		}
+		hosts.add(host)
+	}
+	result.Hosts = hosts.list()
+	return result, nil
+}
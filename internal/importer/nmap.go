@@ -0,0 +1,42 @@
+package importer
+
+import (
+	"io"
+
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/nmap"
+)
+
+// parseNmap parses an nmap XML report, only the hosts that were up make it into the inventory.
+func parseNmap(r io.Reader) (*Result, error) {
+	run, err := nmap.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	hosts := newHostSet()
+	for _, host := range run.Hosts {
+		if host.Status.State != "up" || host.Address() == "" {
+			continue
+		}
+		inventoryHost := inventory.Host{
+			Address:  host.Address(),
+			Hostname: host.Hostname(),
+			OS:       host.OS(),
+			Status:   host.Status.State,
+			Source:   string(FormatNmap),
+		}
+		for _, port := range host.Ports {
+			inventoryHost.Ports = append(inventoryHost.Ports, inventory.Port{
+				Number:   int64(port.PortID),
+				Protocol: port.Protocol,
+				State:    port.State.State,
+				Service:  port.Service.FullName(),
+				Product:  port.Service.Product,
+				Version:  port.Service.Version,
+				Source:   string(FormatNmap),
+			})
+		}
+		hosts.add(inventoryHost)
+	}
+	return &Result{Hosts: hosts.list()}, nil
+}
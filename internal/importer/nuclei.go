@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+type nucleiResult struct {
+	TemplateID string `json:"template-id"`
+	Info       struct {
+		Name           string          `json:"name"`
+		Severity       string          `json:"severity"`
+		Description    string          `json:"description"`
+		Remediation    string          `json:"remediation"`
+		Reference      json.RawMessage `json:"reference"`
+		Classification struct {
+			CVEs        []string `json:"cve-id"`
+			CWEs        []string `json:"cwe-id"`
+			CVSSMetrics string   `json:"cvss-metrics"`
+		} `json:"classification"`
+	} `json:"info"`
+	Type             string   `json:"type"`
+	Host             string   `json:"host"`
+	MatchedAt        string   `json:"matched-at"`
+	IP               string   `json:"ip"`
+	Port             any      `json:"port"`
+	MatcherName      string   `json:"matcher-name"`
+	ExtractedResults []string `json:"extracted-results"`
+	CurlCommand      string   `json:"curl-command"`
+}
+
+// parseNuclei parses nuclei's -jsonl output, the array -json-export writes works as well.
+func parseNuclei(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []nucleiResult
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, fmt.Errorf("failed to parse nuclei json: %w", err)
+		}
+	} else {
+		lineNo := 0
+		err := lines(bytes.NewReader(data), func(line string) error {
+			lineNo++
+			var result nucleiResult
+			if err := json.Unmarshal([]byte(line), &result); err != nil {
+				return fmt.Errorf("failed to parse nuclei jsonl line %d: %w", lineNo, err)
+			}
+			results = append(results, result)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hosts := newHostSet()
+	result := &Result{}
+	for _, r := range results {
+		hostname, port, scheme := nucleiTarget(r)
+		address := r.IP
+		if address == "" {
+			address = hostname
+		}
+		if address == "" {
+			continue
+		}
+		host := inventory.Host{Address: address, Status: "up", Source: string(FormatNuclei)}
+		if hostname != address {
+			host.Hostname = hostname
+		}
+		protocol := "tcp"
+		if r.Type == "dns" {
+			protocol = "udp"
+		}
+		if port > 0 {
+			host.Ports = []inventory.Port{{Number: port, Protocol: protocol, State: "open", Service: scheme, Source: string(FormatNuclei)}}
+		}
+		hosts.add(host)
+
+		title := r.Info.Name
+		if r.MatcherName != "" {
+			title = fmt.Sprintf("%s: %s", title, r.MatcherName)
+		}
+		var evidence []string
+		if len(r.ExtractedResults) > 0 {
+			evidence = append(evidence, "extracted: "+strings.Join(r.ExtractedResults, ", "))
+		}
+		if r.CurlCommand != "" {
+			evidence = append(evidence, r.CurlCommand)
+		}
+		references := append(append([]string{}, r.Info.Classification.CVEs...), r.Info.Classification.CWEs...)
+		references = append(references, nucleiReferences(r.Info.Reference)...)
+
+		location := r.MatchedAt
+		if location == "" {
+			location = r.Host
+		}
+		result.Findings = append(result.Findings, Finding{
+			Host: address,
+			Finding: finding.Finding{
+				Port:        port,
+				Protocol:    protocol,
+				Location:    location,
+				Title:       title,
+				Severity:    finding.ParseSeverity(r.Info.Severity),
+				Description: strings.TrimSpace(fmt.Sprintf("template %s\n\n%s", r.TemplateID, r.Info.Description)),
+				Evidence:    strings.Join(evidence, "\n"),
+				Remediation: strings.TrimSpace(r.Info.Remediation),
+				References:  references,
+				Source:      string(FormatNuclei),
+				CVSSVector:  cvssVector(r.Info.Classification.CVSSMetrics),
+			},
+		})
+	}
+	result.Hosts = hosts.list()
+	return result, nil
+}
+
+// nucleiTarget works out the hostname, port and scheme a result is about. nuclei reports the host either as a URL or as host:port.
+func nucleiTarget(r nucleiResult) (string, int64, string) {
+	var port int64
+	switch p := r.Port.(type) {
+	case float64:
+		port = int64(p)
+	case string:
+		port, _ = strconv.ParseInt(p, 10, 64)
+	}
+
+	target := r.Host
+	if target == "" {
+		target = r.MatchedAt
+	}
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" && u.Host != "" {
+		if port == 0 {
+			port = urlPort(u)
+		}
+		return u.Hostname(), port, u.Scheme
+	}
+	if u, err := url.Parse("//" + target); err == nil && u.Host != "" {
+		if port == 0 {
+			port, _ = strconv.ParseInt(u.Port(), 10, 64)
+		}
+		return u.Hostname(), port, ""
+	}
+	return target, port, ""
+}
+
+// nucleiReferences reads the reference field of a template, it is a list of URLs or a single one.
+func nucleiReferences(raw json.RawMessage) []string {
+	var references []string
+	if err := json.Unmarshal(raw, &references); err == nil {
+		return references
+	}
+	var reference string
+	if err := json.Unmarshal(raw, &reference); err == nil && reference != "" {
+		return []string{reference}
+	}
+	return nil
+}
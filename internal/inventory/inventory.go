@@ -0,0 +1,173 @@
+package inventory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/pubsub"
+)
+
+// Host is a machine discovered during the engagement.
+type Host struct {
+	ID        string
+	Address   string
+	Hostname  string
+	OS        string
+	Status    string
+	Source    string
+	Ports     []Port
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Port is a port of a host along with the service listening on it.
+type Port struct {
+	ID        string
+	HostID    string
+	Number    int64
+	Protocol  string
+	State     string
+	Service   string
+	Product   string
+	Version   string
+	Source    string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Service is the asset inventory of the engagement. recording a host or a port that is already known merges the new details into it.
+type Service interface {
+	pubsub.Subscriber[Host]
+	RecordHost(ctx context.Context, host Host) (Host, error)
+	RecordPort(ctx context.Context, hostID string, port Port) (Port, error)
+	GetHost(ctx context.Context, address string) (Host, error)
+	ListHosts(ctx context.Context) ([]Host, error)
+}
+
+type service struct {
+	*pubsub.Broker[Host]
+	q db.Querier
+}
+
+func (s *service) RecordHost(ctx context.Context, host Host) (Host, error) {
+	id := uuid.New().String()
+	dbHost, err := s.q.UpsertHost(ctx, db.UpsertHostParams{
+		ID:       id,
+		Address:  host.Address,
+		Hostname: host.Hostname,
+		Os:       host.OS,
+		Status:   host.Status,
+		Source:   host.Source,
+	})
+	if err != nil {
+		return Host{}, err
+	}
+
+	recorded := fromDBHost(dbHost)
+	for _, port := range host.Ports {
+		recordedPort, err := s.RecordPort(ctx, recorded.ID, port)
+		if err != nil {
+			return Host{}, err
+		}
+		recorded.Ports = append(recorded.Ports, recordedPort)
+	}
+
+	// NOTE: a known host keeps its ID, the new one is only taken when the host gets inserted.
+	if recorded.ID == id {
+		s.Publish(pubsub.CreatedEvent, recorded)
+	} else {
+		s.Publish(pubsub.UpdatedEvent, recorded)
+	}
+	return recorded, nil
+}
+
+func (s *service) RecordPort(ctx context.Context, hostID string, port Port) (Port, error) {
+	dbPort, err := s.q.UpsertPort(ctx, db.UpsertPortParams{
+		ID:       uuid.New().String(),
+		HostID:   hostID,
+		Port:     port.Number,
+		Protocol: port.Protocol,
+		State:    port.State,
+		Service:  port.Service,
+		Product:  port.Product,
+		Version:  port.Version,
+		Source:   port.Source,
+	})
+	if err != nil {
+		return Port{}, err
+	}
+	return fromDBPort(dbPort), nil
+}
+
+func (s *service) GetHost(ctx context.Context, address string) (Host, error) {
+	dbHost, err := s.q.GetHostByAddress(ctx, address)
+	if err != nil {
+		return Host{}, err
+	}
+	return s.withPorts(ctx, fromDBHost(dbHost))
+}
+
+func (s *service) ListHosts(ctx context.Context) ([]Host, error) {
+	dbHosts, err := s.q.ListHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]Host, len(dbHosts))
+	for i, dbHost := range dbHosts {
+		hosts[i], err = s.withPorts(ctx, fromDBHost(dbHost))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hosts, nil
+}
+
+func (s *service) withPorts(ctx context.Context, host Host) (Host, error) {
+	dbPorts, err := s.q.ListPortsByHost(ctx, host.ID)
+	if err != nil {
+		return Host{}, err
+	}
+	host.Ports = make([]Port, len(dbPorts))
+	for i, dbPort := range dbPorts {
+		host.Ports[i] = fromDBPort(dbPort)
+	}
+	return host, nil
+}
+
+func fromDBHost(item db.Host) Host {
+	return Host{
+		ID:        item.ID,
+		Address:   item.Address,
+		Hostname:  item.Hostname,
+		OS:        item.Os,
+		Status:    item.Status,
+		Source:    item.Source,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func fromDBPort(item db.Port) Port {
+	return Port{
+		ID:        item.ID,
+		HostID:    item.HostID,
+		Number:    item.Port,
+		Protocol:  item.Protocol,
+		State:     item.State,
+		Service:   item.Service,
+		Product:   item.Product,
+		Version:   item.Version,
+		Source:    item.Source,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	broker := pubsub.NewBroker[Host]()
+	return &service{
+		broker,
+		q,
+	}
+}
@@ -0,0 +1,320 @@
+// Package mcpserver exposes the engagement over MCP so that an external client can drive it: the subagents, the scope
+// enforced terminal and read-only views of the sessions and findings, all backed by the services of the app.
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/yyovil/tandem/internal/agent"
+	"github.com/yyovil/tandem/internal/app"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/logging"
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/schedule"
+	"github.com/yyovil/tandem/internal/session"
+	"github.com/yyovil/tandem/internal/tools"
+	"github.com/yyovil/tandem/internal/version"
+)
+
+const (
+	ListSessionsToolName = "list_sessions"
+	ListFindingsToolName = "list_findings"
+
+	sessionsURI        = "tandem://sessions"
+	sessionURITemplate = "tandem://sessions/{id}"
+	findingsURI        = "tandem://findings"
+
+	// mcpAgentName stands in for the agent in the audit log when a client runs a tool itself.
+	mcpAgentName = "mcp client"
+)
+
+const instructions = `tandem runs a penetration testing engagement within the scope and rules of engagement of the engagement directory it was started in.
+hand tasks to the subagents (reconnoiter, vulnerability_scanner, exploiter, reporter) with the subagent tool, run single commands with the terminal tool, and read the sessions and findings to follow along.`
+
+// Server is the MCP server of an engagement.
+type Server struct {
+	app *app.App
+	mcp *server.MCPServer
+
+	// sessionID is the session the subagent tasks of this server hang off, it's created on the first task.
+	mu        sync.Mutex
+	sessionID string
+}
+
+// SessionView is how a session is shown to MCP clients.
+type SessionView struct {
+	ID               string  `json:"id"`
+	ParentSessionID  string  `json:"parent_session_id,omitempty"`
+	Title            string  `json:"title"`
+	MessageCount     int64   `json:"message_count"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+	CreatedAt        int64   `json:"created_at"`
+	UpdatedAt        int64   `json:"updated_at"`
+}
+
+// MessageView is how a message of a session is shown to MCP clients.
+type MessageView struct {
+	Role        message.MessageRole  `json:"role"`
+	Content     string               `json:"content,omitempty"`
+	ToolCalls   []message.ToolCall   `json:"tool_calls,omitempty"`
+	ToolResults []message.ToolResult `json:"tool_results,omitempty"`
+	CreatedAt   int64                `json:"created_at"`
+}
+
+// FindingView is how a finding is shown to MCP clients.
+type FindingView struct {
+	ID          string           `json:"id"`
+	Title       string           `json:"title"`
+	Severity    finding.Severity `json:"severity"`
+	HostID      string           `json:"host_id,omitempty"`
+	Port        int64            `json:"port,omitempty"`
+	Protocol    string           `json:"protocol,omitempty"`
+	Location    string           `json:"location,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Evidence    string           `json:"evidence,omitempty"`
+	Remediation string           `json:"remediation,omitempty"`
+	References  []string         `json:"references,omitempty"`
+	Source      string           `json:"source,omitempty"`
+	CVSSVector  string           `json:"cvss_vector,omitempty"`
+	CVSSScore   float64          `json:"cvss_score,omitempty"`
+	CreatedAt   int64            `json:"created_at"`
+	UpdatedAt   int64            `json:"updated_at"`
+}
+
+func New(app *app.App) *Server {
+	s := &Server{
+		app: app,
+		mcp: server.NewMCPServer("tandem", version.Version,
+			server.WithToolCapabilities(false),
+			server.WithResourceCapabilities(false, false),
+			server.WithInstructions(instructions),
+			server.WithRecovery(),
+		),
+	}
+
+	s.addTool(agent.NewAgentTool(app.Sessions, app.Messages, app.Inventory, app.Vault, app.Reports, app.Findings), s.withTaskSession)
+	// NOTE: the terminal needs a session too, an isolated container goes with it when the isolation level is per session.
+	s.addTool(tools.NewScopedTerminal(), s.withTaskSession)
+	s.mcp.AddTool(
+		mcp.NewTool(ListSessionsToolName, mcp.WithDescription("List the sessions of the engagement, the subagent tasks are sessions of their own with the session that started them as their parent.")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return toolResult(s.sessions(ctx))
+		},
+	)
+	s.mcp.AddTool(
+		mcp.NewTool(ListFindingsToolName, mcp.WithDescription("List the findings recorded during the engagement from the most to the least severe, with their CVSS vectors and scores.")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return toolResult(s.findings(ctx))
+		},
+	)
+
+	s.mcp.AddResource(
+		mcp.NewResource(sessionsURI, "sessions", mcp.WithResourceDescription("the sessions of the engagement"), mcp.WithMIMEType("application/json")),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return resourceContents(request.Params.URI)(s.sessions(ctx))
+		},
+	)
+	s.mcp.AddResourceTemplate(
+		mcp.NewResourceTemplate(sessionURITemplate, "session", mcp.WithTemplateDescription("the messages of a session of the engagement"), mcp.WithTemplateMIMEType("application/json")),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return resourceContents(request.Params.URI)(s.messages(ctx, strings.TrimPrefix(request.Params.URI, sessionsURI+"/")))
+		},
+	)
+	s.mcp.AddResource(
+		mcp.NewResource(findingsURI, "findings", mcp.WithResourceDescription("the findings recorded during the engagement"), mcp.WithMIMEType("application/json")),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return resourceContents(request.Params.URI)(s.findings(ctx))
+		},
+	)
+	return s
+}
+
+// Serve speaks MCP over stdin and stdout until the client goes away or ctx is done, the container of the client's
+// session is released then.
+func (s *Server) Serve(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	defer s.release(context.WithoutCancel(ctx))
+	// NOTE: stdout belongs to the protocol, the errors of the transport go to stderr which clients show as the server's log.
+	return server.NewStdioServer(s.mcp).Listen(ctx, stdin, stdout)
+}
+
+// release removes the isolated container of the client's session, if it has one.
+func (s *Server) release(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionID == "" {
+		return
+	}
+	if err := tools.ReleaseSession(ctx, s.sessionID); err != nil {
+		logging.Error("failed to release the container of the MCP session", "session_id", s.sessionID, "error", err)
+	}
+}
+
+// addTool exposes a tool of the swarm, prepare sets up the context it runs with.
+func (s *Server) addTool(tool tools.BaseTool, prepare func(ctx context.Context, callID string) (context.Context, error)) {
+	tool = tools.Audited(tool)[0]
+	info := tool.Info()
+	schema, _ := json.Marshal(map[string]any{
+		"type":       "object",
+		"properties": info.Parameters,
+		"required":   info.Required,
+	})
+	s.mcp.AddTool(mcp.NewToolWithRawSchema(info.Name, info.Description, schema), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		input, err := json.Marshal(request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to encode the %s arguments: %s", info.Name, err)), nil
+		}
+		call := tools.ToolCall{ID: uuid.New().String(), Name: info.Name, Input: string(input)}
+		ctx = context.WithValue(ctx, tools.AgentNameContextKey, mcpAgentName)
+		if prepare != nil {
+			if ctx, err = prepare(ctx, call.ID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		if tools.ReachesTargets(tool) {
+			if err := schedule.Hold(ctx, mcpAgentName); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		response, err := tool.Run(ctx, call)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result := mcp.NewToolResultText(response.Content)
+		result.IsError = response.IsError
+		return result, nil
+	})
+}
+
+// withTaskSession puts the session of the client into the context, the subagent tasks are created as its children and
+// the terminal runs in its container.
+func (s *Server) withTaskSession(ctx context.Context, callID string) (context.Context, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionID == "" {
+		title := "MCP client"
+		if client, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo); ok && client.GetClientInfo().Name != "" {
+			title = "MCP: " + client.GetClientInfo().Name
+		}
+		created, err := s.app.Sessions.Create(ctx, title)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create a session for the task: %s", err)
+		}
+		s.sessionID = created.ID
+	}
+	ctx = context.WithValue(ctx, tools.SessionIDContextKey, s.sessionID)
+	// NOTE: there's no assistant message behind a call from a client, the call stands in for it.
+	return context.WithValue(ctx, tools.MessageIDContextKey, callID), nil
+}
+
+func (s *Server) sessions(ctx context.Context) (any, error) {
+	sessions, err := s.app.Sessions.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]SessionView, len(sessions))
+	for i, session := range sessions {
+		views[i] = sessionView(session)
+	}
+	return views, nil
+}
+
+func (s *Server) messages(ctx context.Context, sessionID string) (any, error) {
+	if _, err := s.app.Sessions.Get(ctx, sessionID); err != nil {
+		return nil, fmt.Errorf("no session %s: %w", sessionID, err)
+	}
+	messages, err := s.app.Messages.List(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]MessageView, len(messages))
+	for i, msg := range messages {
+		views[i] = MessageView{
+			Role:        msg.Role,
+			Content:     msg.Content().Text,
+			ToolCalls:   msg.ToolCalls(),
+			ToolResults: msg.ToolResults(),
+			CreatedAt:   msg.CreatedAt,
+		}
+	}
+	return views, nil
+}
+
+func (s *Server) findings(ctx context.Context) (any, error) {
+	findings, err := s.app.Findings.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(findings, finding.Compare)
+	views := make([]FindingView, len(findings))
+	for i, f := range findings {
+		views[i] = FindingView{
+			ID:          f.ID,
+			Title:       f.Title,
+			Severity:    f.Severity,
+			HostID:      f.HostID,
+			Port:        f.Port,
+			Protocol:    f.Protocol,
+			Location:    f.Location,
+			Description: f.Description,
+			Evidence:    f.Evidence,
+			Remediation: f.Remediation,
+			References:  f.References,
+			Source:      f.Source,
+			CVSSVector:  f.CVSSVector,
+			CVSSScore:   f.CVSSScore,
+			CreatedAt:   f.CreatedAt,
+			UpdatedAt:   f.UpdatedAt,
+		}
+	}
+	return views, nil
+}
+
+func sessionView(s session.Session) SessionView {
+	return SessionView{
+		ID:               s.ID,
+		ParentSessionID:  s.ParentSessionID,
+		Title:            s.Title,
+		MessageCount:     s.MessageCount,
+		PromptTokens:     s.PromptTokens,
+		CompletionTokens: s.CompletionTokens,
+		Cost:             s.Cost,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+	}
+}
+
+func toolResult(v any, err error) (*mcp.CallToolResult, error) {
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+func resourceContents(uri string) func(v any, err error) ([]mcp.ResourceContents, error) {
+	return func(v any, err error) ([]mcp.ResourceContents, error) {
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)}}, nil
+	}
+}
@@ -29,6 +29,7 @@ type partType string
 const (
 	reasoningType  partType = "reasoning"
 	textType       partType = "text"
+	contextType    partType = "context"
 	binaryType     partType = "binary"
 	toolCallType   partType = "tool_call"
 	toolResultType partType = "tool_result"
@@ -65,6 +66,14 @@ func (tc TextContent) String() string {
 
 func (TextContent) isPart() {}
 
+// ContextContent is text the application hands the model along with a user message, like the notice about imported
+// scanner output. it isn't what the user wrote, so it's sent to the model but not shown, exported or indexed as theirs.
+type ContextContent struct {
+	Text string `json:"text"`
+}
+
+func (ContextContent) isPart() {}
+
 type ReasoningContent struct {
 	Thinking string `json:"thinking"`
 }
@@ -90,6 +99,8 @@ type ToolResult struct {
 	Content    string `json:"content"`
 	Metadata   string `json:"metadata"`
 	IsError    bool   `json:"is_error"`
+	// Images are handed to the models that support attachments along with the content.
+	Images []BinaryContent `json:"images,omitempty"`
 }
 
 func (ToolResult) isPart() {}
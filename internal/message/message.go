@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/logging"
 	"github.com/yyovil/tandem/internal/models"
 	"github.com/yyovil/tandem/internal/pubsub"
 )
@@ -26,11 +27,13 @@ type Message struct {
 type Service interface {
 	pubsub.Subscriber[Message]
 	Create(ctx context.Context, sessionID string, params CreateMessageParams) (Message, error)
+	Copy(ctx context.Context, message Message, sessionID string) (Message, error)
 	Update(ctx context.Context, message Message) error
 	Get(ctx context.Context, id string) (Message, error)
 	List(ctx context.Context, sessionID string) ([]Message, error)
 	Delete(ctx context.Context, id string) error
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
+	Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error)
 }
 
 type service struct {
@@ -73,6 +76,42 @@ func (s *service) Create(ctx context.Context, sessionID string, params CreateMes
 	// overwrite created/updated with millisecond precision
 	message.CreatedAt = nowMs
 	message.UpdatedAt = nowMs
+	if err := s.index(ctx, message); err != nil {
+		logging.Warn("failed to index the message for search", "message", message.ID, "error", err)
+	}
+	s.Publish(pubsub.CreatedEvent, message)
+	return message, nil
+}
+
+// Copy writes a copy of a message into another session as it is, keeping its times, e.g. into a fork of its session.
+func (s *service) Copy(ctx context.Context, message Message, sessionID string) (Message, error) {
+	parts, err := marshallParts(message.Parts)
+	if err != nil {
+		return Message{}, err
+	}
+	finishedAt := sql.NullInt64{}
+	if f := message.FinishPart(); f != nil {
+		finishedAt.Int64 = f.Time
+		finishedAt.Valid = true
+	}
+	message.ID = uuid.New().String()
+	message.SessionID = sessionID
+	_, err = s.q.ImportMessage(ctx, db.ImportMessageParams{
+		ID:         message.ID,
+		SessionID:  sessionID,
+		Role:       string(message.Role),
+		Parts:      string(parts),
+		Model:      sql.NullString{String: string(message.Model), Valid: message.Model != ""},
+		CreatedAt:  message.CreatedAt,
+		UpdatedAt:  message.UpdatedAt,
+		FinishedAt: finishedAt,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	if err := s.index(ctx, message); err != nil {
+		logging.Warn("failed to index the message for search", "message", message.ID, "error", err)
+	}
 	s.Publish(pubsub.CreatedEvent, message)
 	return message, nil
 }
@@ -95,6 +134,8 @@ func marshallParts(parts []ContentPart) ([]byte, error) {
 			typ = reasoningType
 		case TextContent:
 			typ = textType
+		case ContextContent:
+			typ = contextType
 		case ToolCall:
 			typ = toolCallType
 		case ToolResult:
@@ -146,6 +187,12 @@ func unmarshallParts(data []byte) ([]ContentPart, error) {
 				return nil, err
 			}
 			parts = append(parts, part)
+		case contextType:
+			part := ContextContent{}
+			if err := json.Unmarshal(wrapper.Data, &part); err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
 		case toolCallType:
 			part := ToolCall{}
 			if err := json.Unmarshal(wrapper.Data, &part); err != nil {
@@ -191,6 +238,12 @@ func (s *service) Update(ctx context.Context, message Message) error {
 	if err != nil {
 		return err
 	}
+	// NOTE: streamed messages are updated on every delta, they are indexed once they are done.
+	if message.IsFinished() {
+		if err := s.index(ctx, message); err != nil {
+			logging.Warn("failed to index the message for search", "message", message.ID, "error", err)
+		}
+	}
 	// ensure updated at has millisecond precision
 	message.UpdatedAt = time.Now().UnixMilli()
 	s.Publish(pubsub.UpdatedEvent, message)
@@ -277,6 +330,7 @@ func (s *service) fromDBItem(item db.Message) (Message, error) {
 		SessionID: item.SessionID,
 		Role:      MessageRole(item.Role),
 		Parts:     parts,
+		Model:     models.ModelID(item.Model.String),
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
 	}, nil
@@ -308,6 +362,16 @@ func (m *Message) Content() TextContent {
 	return TextContent{}
 }
 
+func (m *Message) ContextContent() []ContextContent {
+	contexts := make([]ContextContent, 0)
+	for _, part := range m.Parts {
+		if c, ok := part.(ContextContent); ok {
+			contexts = append(contexts, c)
+		}
+	}
+	return contexts
+}
+
 func (m *Message) BinaryContent() []BinaryContent {
 	binaryContents := make([]BinaryContent, 0)
 	for _, part := range m.Parts {
@@ -0,0 +1,167 @@
+package message
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/db"
+)
+
+// snippet markers, they can't appear in a message, so callers can highlight the matches however they like.
+const (
+	MatchStart = "\x02"
+	MatchEnd   = "\x03"
+)
+
+// storeCredentialToolName is the tool the secrets are handed to the vault with, its calls are kept out of the index.
+// it's tools.StoreCredentialToolName, which can't be imported from here.
+const storeCredentialToolName = "store_credential"
+
+type SearchOptions struct {
+	Limit int
+	// Tokens is the number of tokens around the matches a snippet shows.
+	Tokens int
+}
+
+type SearchResult struct {
+	MessageID string `json:"message_id"`
+	SessionID string `json:"session_id"`
+	// ParentSessionID is set when the message is from the session of a subagent.
+	ParentSessionID string      `json:"parent_session_id,omitempty"`
+	SessionTitle    string      `json:"session_title"`
+	Role            MessageRole `json:"role"`
+	// Kind is the part of the message that matched, one of text, tool_call and tool_result.
+	Kind    string `json:"kind"`
+	Tool    string `json:"tool,omitempty"`
+	Snippet string `json:"snippet"`
+	// CreatedAt is a Unix timestamp in seconds.
+	CreatedAt int64 `json:"created_at"`
+}
+
+// Search looks up the messages of all sessions matching the query, best match first. the query is a list of words,
+// "quoted phrases" and prefixes ending in *, all of which have to match, or either side of an OR.
+func (s *service) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	match := MatchQuery(query)
+	if match == "" {
+		return []SearchResult{}, nil
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	if opts.Tokens <= 0 {
+		opts.Tokens = 16
+	}
+	rows, err := s.q.SearchMessages(ctx, db.SearchMessagesParams{
+		Open:   MatchStart,
+		Close:  MatchEnd,
+		Tokens: int64(min(opts.Tokens, 64)),
+		Query:  match,
+		Limit:  int64(opts.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = SearchResult{
+			MessageID:       row.MessageID,
+			SessionID:       row.SessionID,
+			ParentSessionID: row.ParentSessionID.String,
+			SessionTitle:    row.SessionTitle,
+			Role:            MessageRole(row.Role),
+			Kind:            row.Kind,
+			Tool:            row.Tool,
+			Snippet:         row.Snippet,
+			CreatedAt:       row.CreatedAt,
+		}
+	}
+	return results, nil
+}
+
+// MatchQuery turns a search into an FTS5 query, quoting every word so that the punctuation in IPs, paths and flags
+// doesn't read as query syntax.
+func MatchQuery(query string) string {
+	var terms []string
+	for _, term := range splitQuery(query) {
+		prefix := strings.HasSuffix(term, "*") && !strings.HasPrefix(term, `"`)
+		if term == "OR" {
+			if len(terms) > 0 && terms[len(terms)-1] != "OR" {
+				terms = append(terms, term)
+			}
+			continue
+		}
+		term = strings.Trim(term, `"*`)
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+		term = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) > 0 && terms[len(terms)-1] == "OR" {
+		terms = terms[:len(terms)-1]
+	}
+	return strings.Join(terms, " ")
+}
+
+// splitQuery splits a query on whitespace, keeping the quoted phrases along with their quotes.
+func splitQuery(query string) []string {
+	var (
+		terms  []string
+		term   strings.Builder
+		quoted bool
+	)
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// index replaces the rows of a message in the search index with its text, tool call inputs and tool results.
+func (s *service) index(ctx context.Context, message Message) error {
+	if err := s.q.DeleteMessageSearch(ctx, message.ID); err != nil {
+		return err
+	}
+	for _, part := range message.Parts {
+		row := db.InsertMessageSearchParams{
+			MessageID: message.ID,
+			SessionID: message.SessionID,
+		}
+		switch p := part.(type) {
+		case TextContent:
+			row.Kind, row.Content = string(textType), p.Text
+		case ToolCall:
+			if p.Name == storeCredentialToolName {
+				continue
+			}
+			row.Kind, row.Tool, row.Content = string(toolCallType), p.Name, p.Input
+		case ToolResult:
+			row.Kind, row.Tool, row.Content = string(toolResultType), p.Name, p.Content
+		default:
+			continue
+		}
+		if strings.TrimSpace(row.Content) == "" {
+			continue
+		}
+		if err := s.q.InsertMessageSearch(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
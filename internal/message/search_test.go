@@ -0,0 +1,121 @@
+package message
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+)
+
+func TestMatchQuery(t *testing.T) {
+	for query, want := range map[string]string{
+		"10.10.10.5":                 `"10.10.10.5"`,
+		`anonymous "ftp login" smb*`: `"anonymous" "ftp login" "smb"*`,
+		"sqlmap OR hydra":            `"sqlmap" OR "hydra"`,
+		"OR nikto OR":                `"nikto"`,
+		`--script=vuln say"hi`:       `"--script=vuln" "say""hi"`,
+		"  * \"\" ":                  "",
+	} {
+		if got := MatchQuery(query); got != want {
+			t.Errorf("MatchQuery(%q) = %s, want %s", query, got, want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: the messages from before the index are indexed by its migration.
+	if err := goose.UpTo(conn, "migrations", 20251019150000); err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: written as they were back then, the queries follow the latest schema.
+	for _, statement := range []string{
+		`INSERT INTO sessions (id, parent_session_id, title, updated_at, created_at) VALUES ('recon', NULL, 'Recon of the DMZ', 0, 0)`,
+		`INSERT INTO sessions (id, parent_session_id, title, updated_at, created_at) VALUES ('call-1', 'recon', 'reconnoiter agent''s session', 0, 0)`,
+		`INSERT INTO messages (id, session_id, role, parts, created_at, updated_at) VALUES ('old-1', 'call-1', 'assistant', '[{"type":"tool_call","data":{"id":"nmap-1","name":"nmap","input":"{\"target\":\"10.10.10.5\"}"}}]', 0, 0)`,
+		`INSERT INTO messages (id, session_id, role, parts, created_at, updated_at) VALUES ('old-2', 'call-1', 'tool', '[{"type":"tool_result","data":{"tool_call_id":"nmap-1","content":"21/tcp open ftp vsftpd 2.3.4"}}]', 0, 0)`,
+		`INSERT INTO messages (id, session_id, role, parts, created_at, updated_at) VALUES ('old-3', 'recon', 'assistant', '[{"type":"tool_call","data":{"id":"cred-1","name":"store_credential","input":"{\"secret\":\"hunter22\"}"}}]', 0, 0)`,
+	} {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewService(db.New(conn))
+	results, err := service.Search(ctx, "vsftpd", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].MessageID != "old-2" || results[0].Kind != "tool_result" || results[0].Tool != "nmap" || results[0].ParentSessionID != "recon" {
+		t.Fatalf("backfilled matches = %+v", results)
+	}
+	if !strings.Contains(results[0].Snippet, MatchStart+"vsftpd"+MatchEnd) {
+		t.Errorf("snippet %q doesn't mark the match", results[0].Snippet)
+	}
+
+	if _, err := service.Create(ctx, "recon", CreateMessageParams{Role: User, Parts: []ContentPart{TextContent{Text: "look for anonymous FTP logins"}}}); err != nil {
+		t.Fatal(err)
+	}
+	assistant, err := service.Create(ctx, "recon", CreateMessageParams{Role: Assistant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assistant.Parts = []ContentPart{TextContent{Text: "the FTP server allows anonymous logins"}}
+	if err := service.Update(ctx, assistant); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := service.Search(ctx, "allows", SearchOptions{}); len(results) != 0 {
+		t.Errorf("a message still streaming is indexed: %+v", results)
+	}
+	assistant.Parts = append(assistant.Parts, Finish{Reason: FinishReasonEndTurn, Time: time.Now().Unix()})
+	if err := service.Update(ctx, assistant); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err = service.Search(ctx, "anonym* ftp", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("matches = %+v", results)
+	}
+	if results, _ := service.Search(ctx, `"server allows"`, SearchOptions{}); len(results) != 1 || results[0].MessageID != assistant.ID || results[0].Role != Assistant {
+		t.Errorf("phrase matches = %+v", results)
+	}
+
+	// NOTE: the secrets handed to the vault stay out of the index, the ones from before it included.
+	if _, err := service.Create(ctx, "recon", CreateMessageParams{Role: Assistant, Parts: []ContentPart{
+		ToolCall{ID: "cred-2", Name: "store_credential", Input: `{"secret":"letmein99"}`, Finished: true},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := service.Search(ctx, "hunter22 OR letmein99", SearchOptions{}); len(results) != 0 {
+		t.Errorf("a secret handed to the vault is indexed: %+v", results)
+	}
+
+	if err := service.Delete(ctx, assistant.ID); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := service.Search(ctx, "allows", SearchOptions{}); len(results) != 0 {
+		t.Errorf("a deleted message is still found: %+v", results)
+	}
+}
@@ -0,0 +1,163 @@
+// Package nmap parses the XML output of nmap (-oX).
+package nmap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Run struct {
+	Scanner  string   `xml:"scanner,attr"`
+	Args     string   `xml:"args,attr"`
+	Version  string   `xml:"version,attr"`
+	Start    int64    `xml:"start,attr"`
+	Hosts    []Host   `xml:"host"`
+	RunStats RunStats `xml:"runstats"`
+}
+
+type RunStats struct {
+	Finished struct {
+		Elapsed float64 `xml:"elapsed,attr"`
+		Summary string  `xml:"summary,attr"`
+		Exit    string  `xml:"exit,attr"`
+		ErrMsg  string  `xml:"errormsg,attr"`
+	} `xml:"finished"`
+	Hosts struct {
+		Up    int `xml:"up,attr"`
+		Down  int `xml:"down,attr"`
+		Total int `xml:"total,attr"`
+	} `xml:"hosts"`
+}
+
+type Host struct {
+	Status struct {
+		State  string `xml:"state,attr"`
+		Reason string `xml:"reason,attr"`
+	} `xml:"status"`
+	Addresses  []Address   `xml:"address"`
+	Hostnames  []Hostname  `xml:"hostnames>hostname"`
+	Ports      []Port      `xml:"ports>port"`
+	ExtraPorts []ExtraPort `xml:"ports>extraports"`
+	OSMatches  []OSMatch   `xml:"os>osmatch"`
+	Scripts    []Script    `xml:"hostscript>script"`
+}
+
+type Address struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+	Vendor   string `xml:"vendor,attr"`
+}
+
+type Hostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type Port struct {
+	Protocol string `xml:"protocol,attr"`
+	PortID   int    `xml:"portid,attr"`
+	State    struct {
+		State  string `xml:"state,attr"`
+		Reason string `xml:"reason,attr"`
+	} `xml:"state"`
+	Service Service  `xml:"service"`
+	Scripts []Script `xml:"script"`
+}
+
+type ExtraPort struct {
+	State string `xml:"state,attr"`
+	Count int    `xml:"count,attr"`
+}
+
+type Service struct {
+	Name      string   `xml:"name,attr"`
+	Product   string   `xml:"product,attr"`
+	Version   string   `xml:"version,attr"`
+	ExtraInfo string   `xml:"extrainfo,attr"`
+	Tunnel    string   `xml:"tunnel,attr"`
+	Method    string   `xml:"method,attr"`
+	CPEs      []string `xml:"cpe"`
+}
+
+type OSMatch struct {
+	Name     string `xml:"name,attr"`
+	Accuracy int    `xml:"accuracy,attr"`
+}
+
+type Script struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+// Parse decodes an nmap XML report.
+func Parse(r io.Reader) (*Run, error) {
+	var run Run
+	decoder := xml.NewDecoder(r)
+	// NOTE: nmap declares a DOCTYPE and an xsl stylesheet, neither matters to us.
+	decoder.Strict = false
+	if err := decoder.Decode(&run); err != nil {
+		return nil, fmt.Errorf("failed to parse nmap xml: %w", err)
+	}
+	return &run, nil
+}
+
+// Address returns the IP address of the host, falling back to whatever address nmap reported.
+func (h Host) Address() string {
+	for _, address := range h.Addresses {
+		if address.AddrType == "ipv4" || address.AddrType == "ipv6" {
+			return address.Addr
+		}
+	}
+	if len(h.Addresses) > 0 {
+		return h.Addresses[0].Addr
+	}
+	return ""
+}
+
+// Hostname returns the user supplied hostname if any, the PTR record otherwise.
+func (h Host) Hostname() string {
+	for _, hostname := range h.Hostnames {
+		if hostname.Type == "user" {
+			return hostname.Name
+		}
+	}
+	if len(h.Hostnames) > 0 {
+		return h.Hostnames[0].Name
+	}
+	return ""
+}
+
+// OS returns the most accurate os match.
+func (h Host) OS() string {
+	best := OSMatch{}
+	for _, match := range h.OSMatches {
+		if match.Accuracy > best.Accuracy {
+			best = match
+		}
+	}
+	return best.Name
+}
+
+// Describe joins the product, version and extra info of a service the way nmap prints them.
+func (s Service) Describe() string {
+	parts := []string{}
+	for _, part := range []string{s.Product, s.Version} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if s.ExtraInfo != "" {
+		parts = append(parts, "("+s.ExtraInfo+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// FullName is the service name as nmap prints it, e.g. ssl/http.
+func (s Service) FullName() string {
+	if s.Tunnel != "" {
+		return s.Tunnel + "/" + s.Name
+	}
+	return s.Name
+}
@@ -0,0 +1,43 @@
+package nmap
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/scan.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	run, err := Parse(f)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if run.Version != "7.94" || run.RunStats.Hosts.Up != 1 || run.RunStats.Hosts.Total != 2 {
+		t.Fatalf("unexpected run: version %q, stats %+v", run.Version, run.RunStats.Hosts)
+	}
+	if len(run.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(run.Hosts))
+	}
+
+	host := run.Hosts[0]
+	if host.Address() != "10.10.10.5" || host.Hostname() != "web01.lab" || host.OS() != "Linux 5.0 - 5.14" {
+		t.Fatalf("unexpected host: %s %s %s", host.Address(), host.Hostname(), host.OS())
+	}
+	if len(host.Ports) != 2 || host.ExtraPorts[0].Count != 998 {
+		t.Fatalf("unexpected ports: %+v", host.Ports)
+	}
+	ssh := host.Ports[0]
+	if ssh.PortID != 22 || ssh.State.State != "open" || ssh.Service.Describe() != "OpenSSH 8.9p1 Ubuntu 3ubuntu0.6 (Ubuntu Linux; protocol 2.0)" {
+		t.Fatalf("unexpected ssh port: %+v", ssh)
+	}
+	if len(ssh.Scripts) != 1 || ssh.Scripts[0].ID != "ssh-hostkey" {
+		t.Fatalf("unexpected scripts: %+v", ssh.Scripts)
+	}
+	if https := host.Ports[1]; https.Service.FullName() != "ssl/http" {
+		t.Fatalf("unexpected service name: %s", https.Service.FullName())
+	}
+}
@@ -0,0 +1,222 @@
+// Package nvd looks CVEs up in a local mirror of the NVD JSON feeds. both the yearly 1.1 feeds (nvdcve-1.1-2021.json.gz)
+// and the 2.0 schema are understood, the latter either as collections or as one file per CVE named after it.
+package nvd
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CVE is the part of an NVD record worth handing to an agent.
+type CVE struct {
+	ID          string      `json:"id"`
+	Published   string      `json:"published"`
+	Modified    string      `json:"modified"`
+	Description string      `json:"description"`
+	Metrics     []Metric    `json:"metrics"`
+	CWEs        []string    `json:"cwes"`
+	CPEs        []CPEMatch  `json:"cpes"`
+	References  []Reference `json:"references"`
+}
+
+// Metric is a CVSS score of a CVE.
+type Metric struct {
+	Version  string  `json:"version"`
+	Vector   string  `json:"vector"`
+	Score    float64 `json:"score"`
+	Severity string  `json:"severity"`
+	Source   string  `json:"source,omitempty"`
+}
+
+// CPEMatch is a vulnerable configuration, the versions bound the range of the CPE affected.
+type CPEMatch struct {
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+}
+
+type Reference struct {
+	URL  string   `json:"url"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+var (
+	cvePattern  = regexp.MustCompile(`(?i)^CVE-(\d{4})-\d{4,}$`)
+	yearPattern = regexp.MustCompile(`(19|20)\d{2}`)
+)
+
+// Lookup finds the given CVEs in the feeds under dir. CVEs that aren't in the mirror are missing from the result.
+func Lookup(ctx context.Context, dir string, ids []string) (map[string]CVE, error) {
+	wanted := map[string]bool{}
+	years := map[string]bool{}
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		match := cvePattern.FindStringSubmatch(id)
+		if match == nil {
+			return nil, fmt.Errorf("%q isn't a CVE ID, expected something like CVE-2021-44228", id)
+		}
+		wanted[id] = true
+		years[match[1]] = true
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".json.gz")) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the NVD mirror: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no NVD feeds found in %s", dir)
+	}
+
+	found := map[string]CVE{}
+	keep := func(cve CVE) {
+		if known, ok := found[cve.ID]; !ok || cve.Modified > known.Modified {
+			found[cve.ID] = cve
+		}
+	}
+
+	// NOTE: a file per CVE is the cheapest to read, the yearly feeds are only scanned for what's left.
+	var feeds []string
+	for _, path := range files {
+		name := strings.ToUpper(strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".json"))
+		if cvePattern.MatchString(name) {
+			if wanted[name] {
+				cve, err := readRecord(path)
+				if err != nil {
+					return nil, err
+				}
+				keep(cve)
+			}
+			continue
+		}
+		// feeds named after a year only hold the CVEs of that year, the rest (modified, recent) may hold any.
+		if year := yearPattern.FindString(filepath.Base(path)); year != "" && !years[year] {
+			continue
+		}
+		feeds = append(feeds, path)
+	}
+	sort.Strings(feeds)
+
+	for _, path := range feeds {
+		if len(found) == len(wanted) && !strings.Contains(filepath.Base(path), "modified") {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		err := scanFeed(path, func(cve CVE) {
+			if wanted[cve.ID] {
+				keep(cve)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+func open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, f}, nil
+}
+
+// readRecord reads a file holding a single 2.0 CVE record.
+func readRecord(path string) (CVE, error) {
+	r, err := open(path)
+	if err != nil {
+		return CVE{}, err
+	}
+	defer r.Close()
+	var record cve20
+	if err := json.NewDecoder(r).Decode(&record); err != nil {
+		return CVE{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return record.normalize(), nil
+}
+
+// scanFeed streams the records of a feed collection, the yearly feeds are too big to be decoded in one go.
+func scanFeed(path string, yield func(CVE)) error {
+	r, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return fmt.Errorf("%s isn't an NVD feed", path)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		switch token {
+		case "CVE_Items", "vulnerabilities":
+			if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+				return fmt.Errorf("failed to parse %s: expected an array of records", path)
+			}
+			for decoder.More() {
+				var cve CVE
+				if token == "CVE_Items" {
+					var item item11
+					err = decoder.Decode(&item)
+					cve = item.normalize()
+				} else {
+					var item struct {
+						CVE cve20 `json:"cve"`
+					}
+					err = decoder.Decode(&item)
+					cve = item.CVE.normalize()
+				}
+				if err != nil {
+					return fmt.Errorf("failed to parse %s: %w", path, err)
+				}
+				yield(cve)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package nvd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	found, err := Lookup(context.Background(), "testdata/feeds", []string{"CVE-2021-44228", "cve-2021-41773", "CVE-2021-3156", "CVE-2020-0001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Fatalf("expected 3 CVEs, got %d", len(found))
+	}
+
+	// the 1.1 yearly feed holds it, the 2.0 modified feed has a newer revision.
+	log4shell := found["CVE-2021-44228"]
+	if log4shell.Modified != "2021-12-28T10:15:00.000" || log4shell.Metrics[0].Score != 10 {
+		t.Fatalf("expected the modified revision, got %+v", log4shell)
+	}
+
+	traversal := found["CVE-2021-41773"]
+	if traversal.Description != "A path traversal flaw in Apache HTTP Server 2.4.49." {
+		t.Fatalf("expected the english description, got %q", traversal.Description)
+	}
+	if len(traversal.Metrics) != 3 || traversal.Metrics[0].Source != "nvd@nist.gov" || traversal.Metrics[2].Severity != "MEDIUM" {
+		t.Fatalf("expected the primary v3.1 metric first and v2 last, got %+v", traversal.Metrics)
+	}
+	if len(traversal.CPEs) != 1 || traversal.CWEs[0] != "CWE-22" {
+		t.Fatalf("unexpected cpes or cwes: %+v %v", traversal.CPEs, traversal.CWEs)
+	}
+
+	if sudo := found["CVE-2021-3156"]; len(sudo.Metrics) != 0 || sudo.Published != "2021-01-26T21:15Z" {
+		t.Fatalf("unexpected record: %+v", sudo)
+	}
+}
+
+func TestLookupLegacyFeed(t *testing.T) {
+	var log4shell CVE
+	err := scanFeed("testdata/feeds/nvdcve-1.1-2021.json.gz", func(cve CVE) {
+		if cve.ID == "CVE-2021-44228" {
+			log4shell = cve
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log4shell.Metrics) != 2 || log4shell.Metrics[1].Version != "2.0" || log4shell.Metrics[1].Severity != "HIGH" {
+		t.Fatalf("unexpected metrics: %+v", log4shell.Metrics)
+	}
+	if len(log4shell.CPEs) != 1 || log4shell.CPEs[0].VersionEndExcluding != "2.3.1" {
+		t.Fatalf("expected only the vulnerable cpe, got %+v", log4shell.CPEs)
+	}
+	if len(log4shell.CWEs) != 2 || log4shell.References[0].Tags[0] != "Vendor Advisory" {
+		t.Fatalf("unexpected cwes or references: %v %+v", log4shell.CWEs, log4shell.References)
+	}
+}
+
+func TestLookupInvalidID(t *testing.T) {
+	if _, err := Lookup(context.Background(), "testdata/feeds", []string{"log4shell"}); err == nil {
+		t.Fatal("expected an invalid ID to fail")
+	}
+}
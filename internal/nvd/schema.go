@@ -0,0 +1,211 @@
+package nvd
+
+import "sort"
+
+// item11 is a record of the legacy 1.1 feeds.
+type item11 struct {
+	CVE struct {
+		Meta struct {
+			ID string `json:"ID"`
+		} `json:"CVE_data_meta"`
+		ProblemType struct {
+			Data []struct {
+				Description []struct {
+					Value string `json:"value"`
+				} `json:"description"`
+			} `json:"problemtype_data"`
+		} `json:"problemtype"`
+		References struct {
+			Data []struct {
+				URL  string   `json:"url"`
+				Tags []string `json:"tags"`
+			} `json:"reference_data"`
+		} `json:"references"`
+		Description struct {
+			Data []langString `json:"description_data"`
+		} `json:"description"`
+	} `json:"cve"`
+	Configurations struct {
+		Nodes []node11 `json:"nodes"`
+	} `json:"configurations"`
+	Impact struct {
+		V3 struct {
+			CVSS struct {
+				Version      string  `json:"version"`
+				VectorString string  `json:"vectorString"`
+				BaseScore    float64 `json:"baseScore"`
+				BaseSeverity string  `json:"baseSeverity"`
+			} `json:"cvssV3"`
+		} `json:"baseMetricV3"`
+		V2 struct {
+			CVSS struct {
+				VectorString string  `json:"vectorString"`
+				BaseScore    float64 `json:"baseScore"`
+			} `json:"cvssV2"`
+			Severity string `json:"severity"`
+		} `json:"baseMetricV2"`
+	} `json:"impact"`
+	Published string `json:"publishedDate"`
+	Modified  string `json:"lastModifiedDate"`
+}
+
+type node11 struct {
+	Children []node11 `json:"children"`
+	CPEMatch []struct {
+		Vulnerable bool   `json:"vulnerable"`
+		URI        string `json:"cpe23Uri"`
+		CPEMatch
+	} `json:"cpe_match"`
+}
+
+type langString struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+func (item item11) normalize() CVE {
+	cve := CVE{
+		ID:          item.CVE.Meta.ID,
+		Published:   item.Published,
+		Modified:    item.Modified,
+		Description: english(item.CVE.Description.Data),
+	}
+	if v3 := item.Impact.V3.CVSS; v3.VectorString != "" {
+		cve.Metrics = append(cve.Metrics, Metric{Version: v3.Version, Vector: v3.VectorString, Score: v3.BaseScore, Severity: v3.BaseSeverity})
+	}
+	if v2 := item.Impact.V2; v2.CVSS.VectorString != "" {
+		cve.Metrics = append(cve.Metrics, Metric{Version: "2.0", Vector: v2.CVSS.VectorString, Score: v2.CVSS.BaseScore, Severity: v2.Severity})
+	}
+	for _, data := range item.CVE.ProblemType.Data {
+		for _, description := range data.Description {
+			cve.CWEs = appendUnique(cve.CWEs, description.Value)
+		}
+	}
+	var walk func(nodes []node11)
+	walk = func(nodes []node11) {
+		for _, node := range nodes {
+			for _, match := range node.CPEMatch {
+				if match.Vulnerable {
+					match.CPEMatch.Criteria = match.URI
+					cve.CPEs = append(cve.CPEs, match.CPEMatch)
+				}
+			}
+			walk(node.Children)
+		}
+	}
+	walk(item.Configurations.Nodes)
+	for _, reference := range item.CVE.References.Data {
+		cve.References = append(cve.References, Reference{URL: reference.URL, Tags: reference.Tags})
+	}
+	return cve
+}
+
+// cve20 is a record of the 2.0 schema, the one the NVD API and the 2.0 feeds use.
+type cve20 struct {
+	ID           string       `json:"id"`
+	Published    string       `json:"published"`
+	Modified     string       `json:"lastModified"`
+	Descriptions []langString `json:"descriptions"`
+	Metrics      struct {
+		V40 []metric20 `json:"cvssMetricV40"`
+		V31 []metric20 `json:"cvssMetricV31"`
+		V30 []metric20 `json:"cvssMetricV30"`
+		V2  []metric20 `json:"cvssMetricV2"`
+	} `json:"metrics"`
+	Weaknesses []struct {
+		Description []langString `json:"description"`
+	} `json:"weaknesses"`
+	Configurations []struct {
+		Nodes []struct {
+			CPEMatch []struct {
+				Vulnerable bool `json:"vulnerable"`
+				CPEMatch
+			} `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+	References []struct {
+		URL  string   `json:"url"`
+		Tags []string `json:"tags"`
+	} `json:"references"`
+}
+
+type metric20 struct {
+	Source   string `json:"source"`
+	Type     string `json:"type"`
+	CVSSData struct {
+		Version      string  `json:"version"`
+		VectorString string  `json:"vectorString"`
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	// BaseSeverity sits next to the cvss data for v2.
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+func (record cve20) normalize() CVE {
+	cve := CVE{
+		ID:          record.ID,
+		Published:   record.Published,
+		Modified:    record.Modified,
+		Description: english(record.Descriptions),
+	}
+	for _, metrics := range [][]metric20{record.Metrics.V40, record.Metrics.V31, record.Metrics.V30, record.Metrics.V2} {
+		// NOTE: the primary source, the NVD itself, goes first.
+		sorted := append([]metric20{}, metrics...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Type == "Primary" && sorted[j].Type != "Primary"
+		})
+		for _, metric := range sorted {
+			severity := metric.CVSSData.BaseSeverity
+			if severity == "" {
+				severity = metric.BaseSeverity
+			}
+			cve.Metrics = append(cve.Metrics, Metric{
+				Version:  metric.CVSSData.Version,
+				Vector:   metric.CVSSData.VectorString,
+				Score:    metric.CVSSData.BaseScore,
+				Severity: severity,
+				Source:   metric.Source,
+			})
+		}
+	}
+	for _, weakness := range record.Weaknesses {
+		for _, description := range weakness.Description {
+			cve.CWEs = appendUnique(cve.CWEs, description.Value)
+		}
+	}
+	for _, configuration := range record.Configurations {
+		for _, node := range configuration.Nodes {
+			for _, match := range node.CPEMatch {
+				if match.Vulnerable {
+					cve.CPEs = append(cve.CPEs, match.CPEMatch)
+				}
+			}
+		}
+	}
+	for _, reference := range record.References {
+		cve.References = append(cve.References, Reference{URL: reference.URL, Tags: reference.Tags})
+	}
+	return cve
+}
+
+func english(descriptions []langString) string {
+	for _, description := range descriptions {
+		if description.Lang == "en" {
+			return description.Value
+		}
+	}
+	if len(descriptions) > 0 {
+		return descriptions[0].Value
+	}
+	return ""
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, known := range values {
+		if known == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -68,6 +68,9 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 				}
 			}
 			var contentBlocks []anthropic.ContentBlockParamUnion
+			for _, c := range msg.ContextContent() {
+				contentBlocks = append(contentBlocks, anthropic.NewTextBlock(c.Text))
+			}
 			contentBlocks = append(contentBlocks, content)
 			for _, binaryContent := range msg.BinaryContent() {
 				base64Image := binaryContent.String(models.ProviderAnthropic)
@@ -107,6 +110,27 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 			results := make([]anthropic.ContentBlockParamUnion, len(msg.ToolResults()))
 			for i, toolResult := range msg.ToolResults() {
 				results[i] = anthropic.NewToolResultBlock(toolResult.ToolCallID)
+				results[i].OfToolResult.IsError = anthropic.Bool(toolResult.IsError)
+				if toolResult.Content != "" {
+					results[i].OfToolResult.Content = append(results[i].OfToolResult.Content, anthropic.ToolResultBlockParamContentUnion{
+						OfText: &anthropic.TextBlockParam{Text: toolResult.Content},
+					})
+				}
+				if !a.providerOptions.model.SupportsAttachments {
+					continue
+				}
+				for _, image := range toolResult.Images {
+					results[i].OfToolResult.Content = append(results[i].OfToolResult.Content, anthropic.ToolResultBlockParamContentUnion{
+						OfImage: &anthropic.ImageBlockParam{
+							Source: anthropic.ImageBlockParamSourceUnion{
+								OfBase64: &anthropic.Base64ImageSourceParam{
+									Data:      image.String(models.ProviderAnthropic),
+									MediaType: anthropic.Base64ImageSourceMediaType(image.MIMEType),
+								},
+							},
+						},
+					})
+				}
 			}
 			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(results...))
 		}
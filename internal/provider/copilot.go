@@ -194,6 +194,10 @@ func (c *copilotClient) convertMessages(messages []message.Message) (copilotMess
 		switch msg.Role {
 		case message.User:
 			var content []openai.ChatCompletionContentPartUnionParam
+			for _, c := range msg.ContextContent() {
+				contextBlock := openai.ChatCompletionContentPartTextParam{Text: c.Text}
+				content = append(content, openai.ChatCompletionContentPartUnionParam{OfText: &contextBlock})
+			}
 			textBlock := openai.ChatCompletionContentPartTextParam{Text: msg.Content().String()}
 			content = append(content, openai.ChatCompletionContentPartUnionParam{OfText: &textBlock})
 
@@ -235,10 +239,21 @@ func (c *copilotClient) convertMessages(messages []message.Message) (copilotMess
 			})
 
 		case message.Tool:
+			var images []openai.ChatCompletionContentPartUnionParam
 			for _, result := range msg.ToolResults() {
 				copilotMessages = append(copilotMessages,
 					openai.ToolMessage(result.Content, result.ToolCallID),
 				)
+				for _, image := range result.Images {
+					imageBlock := openai.ChatCompletionContentPartImageParam{ImageURL: openai.ChatCompletionContentPartImageImageURLParam{URL: image.String(models.ProviderOpenAI)}}
+					images = append(images, openai.ChatCompletionContentPartUnionParam{OfImageURL: &imageBlock})
+				}
+			}
+			// NOTE: tool messages only carry text, the images they returned follow as a message of the user.
+			if len(images) > 0 && c.providerOptions.model.SupportsAttachments {
+				textBlock := openai.ChatCompletionContentPartTextParam{Text: "the images returned by the tool calls above"}
+				images = append([]openai.ChatCompletionContentPartUnionParam{{OfText: &textBlock}}, images...)
+				copilotMessages = append(copilotMessages, openai.UserMessage(images))
 			}
 		}
 	}
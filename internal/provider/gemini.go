@@ -65,6 +65,9 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 		switch msg.Role {
 		case message.User:
 			var parts []*genai.Part
+			for _, c := range msg.ContextContent() {
+				parts = append(parts, &genai.Part{Text: c.Text})
+			}
 			parts = append(parts, &genai.Part{Text: msg.Content().String()})
 			for _, binaryContent := range msg.BinaryContent() {
 				imageFormat := strings.Split(binaryContent.MIMEType, "/")
@@ -104,6 +107,7 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 			}
 
 		case message.Tool:
+			var images []*genai.Part
 			for _, result := range msg.ToolResults() {
 				response := map[string]any{"result": result.Content}
 				parsed, err := parseJsonToMap(result.Content)
@@ -134,6 +138,17 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 					},
 					Role: "function",
 				})
+				if len(result.Images) > 0 {
+					images = append(images, &genai.Part{Text: "the images returned by " + toolCall.Name})
+					for _, image := range result.Images {
+						images = append(images, &genai.Part{InlineData: &genai.Blob{MIMEType: image.MIMEType, Data: image.Data}})
+					}
+				}
+			}
+			// NOTE: a function response only carries JSON, the images the calls returned follow as inline data once all
+			// of the responses are in, a user turn between the responses to one turn of calls is rejected.
+			if len(images) > 0 && g.providerOptions.model.SupportsAttachments {
+				history = append(history, &genai.Content{Parts: images, Role: "user"})
 			}
 		}
 	}
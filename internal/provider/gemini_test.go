@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/models"
+)
+
+func TestGeminiConvertMessagesImages(t *testing.T) {
+	g := &geminiClient{providerOptions: providerClientOptions{model: models.Model{SupportsAttachments: true}}}
+	image := message.BinaryContent{MIMEType: "image/png", Data: []byte("png")}
+	history := g.convertMessages([]message.Message{
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "call-1", Name: "screenshot", Input: `{}`},
+			message.ToolCall{ID: "call-2", Name: "screenshot", Input: `{}`},
+		}},
+		{Role: message.Tool, Parts: []message.ContentPart{
+			message.ToolResult{ToolCallID: "call-1", Name: "screenshot", Content: "login page", Images: []message.BinaryContent{image}},
+			message.ToolResult{ToolCallID: "call-2", Name: "screenshot", Content: "admin panel", Images: []message.BinaryContent{image}},
+		}},
+	})
+
+	// NOTE: the responses to the parallel calls follow each other, the images come after all of them.
+	var roles []string
+	for _, content := range history {
+		roles = append(roles, content.Role)
+	}
+	if len(roles) != 4 || roles[1] != "function" || roles[2] != "function" || roles[3] != "user" {
+		t.Fatalf("roles = %v", roles)
+	}
+	if parts := history[3].Parts; len(parts) != 4 || parts[1].InlineData == nil || parts[3].InlineData == nil {
+		t.Errorf("expected both images in one user turn, got %d parts", len(parts))
+	}
+}
@@ -80,6 +80,10 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 		switch msg.Role {
 		case message.User:
 			var content []openai.ChatCompletionContentPartUnionParam
+			for _, c := range msg.ContextContent() {
+				contextBlock := openai.ChatCompletionContentPartTextParam{Text: c.Text}
+				content = append(content, openai.ChatCompletionContentPartUnionParam{OfText: &contextBlock})
+			}
 			textBlock := openai.ChatCompletionContentPartTextParam{Text: msg.Content().String()}
 			content = append(content, openai.ChatCompletionContentPartUnionParam{OfText: &textBlock})
 			for _, binaryContent := range msg.BinaryContent() {
@@ -121,10 +125,21 @@ func (o *openaiClient) convertMessages(messages []message.Message) (openaiMessag
 			})
 
 		case message.Tool:
+			var images []openai.ChatCompletionContentPartUnionParam
 			for _, result := range msg.ToolResults() {
 				openaiMessages = append(openaiMessages,
 					openai.ToolMessage(result.Content, result.ToolCallID),
 				)
+				for _, image := range result.Images {
+					imageBlock := openai.ChatCompletionContentPartImageParam{ImageURL: openai.ChatCompletionContentPartImageImageURLParam{URL: image.String(models.ProviderOpenAI)}}
+					images = append(images, openai.ChatCompletionContentPartUnionParam{OfImageURL: &imageBlock})
+				}
+			}
+			// NOTE: tool messages only carry text, the images they returned follow as a message of the user.
+			if len(images) > 0 && o.providerOptions.model.SupportsAttachments {
+				textBlock := openai.ChatCompletionContentPartTextParam{Text: "the images returned by the tool calls above"}
+				images = append([]openai.ChatCompletionContentPartUnionParam{{OfText: &textBlock}}, images...)
+				openaiMessages = append(openaiMessages, openai.UserMessage(images))
 			}
 		}
 	}
@@ -88,6 +88,7 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 	for _, o := range opts {
 		o(&clientOptions)
 	}
+	clientOptions.systemMessage = redactor.Redact(clientOptions.systemMessage)
 	switch providerName {
 	case models.ProviderCopilot:
 		return &baseProvider[CopilotClient]{
@@ -161,8 +162,13 @@ func (p *baseProvider[C]) cleanMessages(messages []message.Message) (cleaned []m
 }
 
 func (p *baseProvider[C]) SendMessages(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
-	messages = p.cleanMessages(messages)
-	return p.client.send(ctx, messages, tools)
+	messages = redactMessages(redactor, p.cleanMessages(messages))
+	if redactor == nil {
+		return p.client.send(ctx, messages, tools)
+	}
+	response, err := p.client.send(ctx, messages, tools)
+	restoreResponse(redactor, response)
+	return response, err
 }
 
 func (p *baseProvider[C]) Model() models.Model {
@@ -170,8 +176,11 @@ func (p *baseProvider[C]) Model() models.Model {
 }
 
 func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message.Message, tools []tools.BaseTool, options ...GenerateContentConfigOption) <-chan ProviderEvent {
-	messages = p.cleanMessages(messages)
-	return p.client.stream(ctx, messages, tools, options...)
+	messages = redactMessages(redactor, p.cleanMessages(messages))
+	if redactor == nil {
+		return p.client.stream(ctx, messages, tools, options...)
+	}
+	return restoreEvents(redactor, p.client.stream(ctx, messages, tools, options...))
 }
 
 func WithAPIKey(apiKey string) ProviderClientOption {
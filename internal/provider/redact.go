@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/redact"
+	"github.com/yyovil/tandem/internal/tools"
+)
+
+// redactor replaces the secrets in what's sent to the providers, the conversation goes out as it is without one.
+var redactor *redact.Redactor
+
+// UseRedactor redacts what's sent to the providers and restores their answers. it's called before the providers are
+// created, their system message is redacted once along the way.
+func UseRedactor(r *redact.Redactor) {
+	redactor = r
+}
+
+// redactMessages returns copies of the messages with their secrets replaced, the stored messages keep the values. the
+// secrets of the credential vault are masked with their handles first, with or without a redactor: the output that
+// revealed a credential before it was stored and the call storing it are sent again on every turn.
+func redactMessages(r *redact.Redactor, messages []message.Message) []message.Message {
+	redacted := make([]message.Message, len(messages))
+	for i, msg := range messages {
+		parts := make([]message.ContentPart, len(msg.Parts))
+		for j, part := range msg.Parts {
+			switch part := part.(type) {
+			case message.TextContent:
+				part.Text = r.Redact(tools.MaskSecrets(part.Text))
+				parts[j] = part
+			case message.ContextContent:
+				part.Text = r.Redact(tools.MaskSecrets(part.Text))
+				parts[j] = part
+			case message.ToolCall:
+				part.Input = r.RedactJSON(tools.MaskInput(part.Name, part.Input))
+				parts[j] = part
+			case message.ToolResult:
+				part.Content = r.Redact(tools.MaskSecrets(part.Content))
+				parts[j] = part
+			default:
+				parts[j] = part
+			}
+		}
+		msg.Parts = parts
+		redacted[i] = msg
+	}
+	return redacted
+}
+
+// restoreResponse puts the values back into an answer, so the agent stores and runs what the model meant.
+func restoreResponse(r *redact.Redactor, response *ProviderResponse) {
+	if response == nil {
+		return
+	}
+	response.Content = r.Restore(response.Content)
+	for i := range response.ToolCalls {
+		response.ToolCalls[i].Input = r.RestoreJSON(response.ToolCalls[i].Input)
+	}
+}
+
+// restoreEvents restores the placeholders in a streamed answer. the deltas that may end halfway through a placeholder
+// are held back until the rest of it arrives.
+func restoreEvents(r *redact.Redactor, events <-chan ProviderEvent) <-chan ProviderEvent {
+	restored := make(chan ProviderEvent)
+	go func() {
+		defer close(restored)
+		content, thinking := r.NewStreamRestorer(), r.NewStreamRestorer()
+		flush := func() {
+			if text := thinking.Flush(); text != "" {
+				restored <- ProviderEvent{Type: EventThinkingDelta, Thinking: text}
+			}
+			if text := content.Flush(); text != "" {
+				restored <- ProviderEvent{Type: EventContentDelta, Content: text}
+			}
+		}
+		for event := range events {
+			switch event.Type {
+			case EventContentDelta:
+				if event.Content = content.Write(event.Content); event.Content == "" {
+					continue
+				}
+			case EventThinkingDelta:
+				if event.Thinking = thinking.Write(event.Thinking); event.Thinking == "" {
+					continue
+				}
+			default:
+				flush()
+			}
+			if event.ToolCall != nil {
+				toolCall := *event.ToolCall
+				toolCall.Input = r.RestoreJSON(toolCall.Input)
+				event.ToolCall = &toolCall
+			}
+			restoreResponse(r, event.Response)
+			restored <- event
+		}
+		flush()
+	}()
+	return restored
+}
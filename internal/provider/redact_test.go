@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/tools"
+	"github.com/yyovil/tandem/internal/vault"
+)
+
+func TestRedactMessagesMasksTheVault(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	credentials := vault.NewService(db.New(conn))
+	if err := credentials.Unlock(ctx, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	tools.UseVault(credentials)
+	t.Cleanup(func() { tools.UseVault(nil) })
+
+	const secret = `Summer2024!"\`
+	if _, err := credentials.Store(ctx, vault.Credential{Username: "svc_sql"}, secret); err != nil {
+		t.Fatal(err)
+	}
+	input, _ := json.Marshal(tools.StoreCredentialArgs{Username: "svc_sql", Secret: secret})
+	history := []message.Message{
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-1", Name: "terminal", Content: "svc_sql:" + secret}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.TextContent{Text: "the password of svc_sql is " + secret},
+			message.ToolCall{ID: "call-2", Name: tools.StoreCredentialToolName, Input: string(input), Finished: true},
+		}},
+		// NOTE: stored but too short to be masked, the call storing it still doesn't give it away.
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "call-3", Name: tools.StoreCredentialToolName, Input: `{"secret":"abc"}`, Finished: true},
+		}},
+	}
+
+	sent, err := json.Marshal(redactMessages(nil, history))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sent), "Summer2024") || strings.Contains(string(sent), `"abc"`) {
+		t.Fatalf("a secret of the vault is sent to the provider: %s", sent)
+	}
+	if !strings.Contains(string(sent), "{{cred:1}}") {
+		t.Errorf("expected the handle in place of the secret: %s", sent)
+	}
+	if !strings.Contains(history[0].Parts[0].(message.ToolResult).Content, secret) {
+		t.Error("the stored messages were changed")
+	}
+}
@@ -0,0 +1,362 @@
+// Package redact replaces secrets and personal data with placeholders before the conversation leaves for an LLM
+// provider. the placeholders are stable, the same value always gets the same one, and they are swapped back for the
+// values in whatever the model answers so that the UI, the reports and the tools only ever see the real thing.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// PlaceholderPattern matches the placeholders the values are replaced with, e.g. {{redacted:ntlm_hash:2}}.
+var PlaceholderPattern = regexp.MustCompile(`\{\{redacted:([a-z0-9_-]+):(\d+)\}\}`)
+
+// maxPlaceholderLength bounds how much of a streamed answer is held back in case it's the start of a placeholder.
+const maxPlaceholderLength = 96
+
+const (
+	defaultEntropyMinLength = 32
+	entropyKind             = "high_entropy"
+	denyKind                = "denied"
+)
+
+// detector finds sensitive values in text. when the pattern has a group named value only that group is redacted.
+type detector struct {
+	kind    string
+	pattern *regexp.Regexp
+	valid   func(string) bool
+}
+
+// builtins are the detectors that can be picked by name in the redaction section of swarm.json.
+var builtins = []detector{
+	{kind: "private_key", pattern: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)},
+	{kind: "netntlm", pattern: regexp.MustCompile(`(?i)[^\s:]+::[^\s:]*:[a-f0-9]{16}:[a-f0-9]{32}:[a-f0-9]{32,}`)},
+	{kind: "ntlm_hash", pattern: regexp.MustCompile(`(?i)\b[a-f0-9]{32}:[a-f0-9]{32}\b`)},
+	{kind: "password_hash", pattern: regexp.MustCompile(`\$(2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}|[156]\$[^\s:$]{1,16}\$[./A-Za-z0-9]{22,86}|y\$[./A-Za-z0-9]+\$[./A-Za-z0-9]+\$[./A-Za-z0-9]{43})`)},
+	{kind: "aws_access_key", pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "github_token", pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{kind: "slack_token", pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{kind: "jwt", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{kind: "api_key", pattern: regexp.MustCompile(`(?i)\b[a-z0-9_-]*?(api[_-]?key|secret[_-]?key|client[_-]?secret|access[_-]?token|auth[_-]?token|password|passwd|pwd)\b["']?\s*[:=]\s*["']?(?P<value>[^\s"',;]{6,})`)},
+	{kind: "email", pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	{kind: "credit_card", pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+	{kind: "ssn", pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+}
+
+// Kinds lists the names of the built-in detectors.
+func Kinds() []string {
+	kinds := make([]string, len(builtins))
+	for i, d := range builtins {
+		kinds[i] = d.kind
+	}
+	return kinds
+}
+
+// Redactor replaces the sensitive values it detects with placeholders and keeps the mapping to put them back.
+type Redactor struct {
+	detectors        []detector
+	allow            []*regexp.Regexp
+	entropy          float64
+	entropyMinLength int
+
+	mu           sync.Mutex
+	placeholders map[string]string // value to placeholder
+	values       map[string]string // placeholder to value
+	counts       map[string]int
+}
+
+// New builds the redactor of the redaction section of swarm.json, nil when redaction is turned off.
+func New(cfg config.Redaction) (*Redactor, error) {
+	if cfg.Disabled {
+		return nil, nil
+	}
+	r := &Redactor{
+		entropy:          cfg.Entropy,
+		entropyMinLength: cfg.EntropyMinLength,
+		placeholders:     map[string]string{},
+		values:           map[string]string{},
+		counts:           map[string]int{},
+	}
+	if r.entropyMinLength <= 0 {
+		r.entropyMinLength = defaultEntropyMinLength
+	}
+
+	if len(cfg.Detectors) == 0 {
+		r.detectors = append(r.detectors, builtins...)
+	}
+	for _, kind := range cfg.Detectors {
+		i := slices.IndexFunc(builtins, func(d detector) bool { return d.kind == kind })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown redaction detector %q, expected one of %s", kind, strings.Join(Kinds(), ", "))
+		}
+		r.detectors = append(r.detectors, builtins[i])
+	}
+
+	names := make([]string, 0, len(cfg.Patterns))
+	for name := range cfg.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern, err := regexp.Compile(cfg.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %s: %w", name, err)
+		}
+		r.detectors = append(r.detectors, detector{kind: strings.ToLower(name), pattern: pattern})
+	}
+	var deny []string
+	for _, value := range cfg.Deny {
+		if value = strings.TrimSpace(value); value != "" {
+			deny = append(deny, regexp.QuoteMeta(value))
+		}
+	}
+	if len(deny) > 0 {
+		r.detectors = append(r.detectors, detector{kind: denyKind, pattern: regexp.MustCompile(`(?i)` + strings.Join(deny, "|"))})
+	}
+
+	for _, allowed := range cfg.Allow {
+		pattern, err := regexp.Compile(allowed)
+		if err != nil {
+			return nil, fmt.Errorf("redaction allow rule %q: %w", allowed, err)
+		}
+		r.allow = append(r.allow, pattern)
+	}
+	return r, nil
+}
+
+type match struct {
+	start, end int
+	kind       string
+}
+
+// Redact replaces the sensitive values in s with their placeholders.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	var matches []match
+	for _, d := range r.detectors {
+		valueGroup := d.pattern.SubexpIndex("value")
+		for _, loc := range d.pattern.FindAllStringSubmatchIndex(s, -1) {
+			start, end := loc[0], loc[1]
+			if valueGroup > 0 {
+				if loc[2*valueGroup] < 0 {
+					continue
+				}
+				start, end = loc[2*valueGroup], loc[2*valueGroup+1]
+			}
+			if d.valid != nil && !d.valid(s[start:end]) {
+				continue
+			}
+			matches = append(matches, match{start, end, d.kind})
+		}
+	}
+	if r.entropy > 0 {
+		for _, loc := range tokenPattern.FindAllStringIndex(s, -1) {
+			token := s[loc[0]:loc[1]]
+			if len(token) >= r.entropyMinLength && strings.ContainsAny(token, "0123456789") && shannonEntropy(token) >= r.entropy {
+				matches = append(matches, match{loc[0], loc[1], entropyKind})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return s
+	}
+
+	// NOTE: the earliest and then the longest match wins, the placeholders themselves are never redacted again.
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.start < last {
+			continue
+		}
+		value := s[m.start:m.end]
+		if r.allowed(value) || PlaceholderPattern.MatchString(value) {
+			continue
+		}
+		sb.WriteString(s[last:m.start])
+		sb.WriteString(r.placeholder(m.kind, value))
+		last = m.end
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// RedactJSON redacts the strings within a JSON document, e.g. the input of a tool call, and keeps it valid JSON.
+func (r *Redactor) RedactJSON(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return r.Redact(s)
+	}
+	var redacted strings.Builder
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.redactValue(v)); err != nil {
+		return r.Redact(s)
+	}
+	return strings.TrimSuffix(redacted.String(), "\n")
+}
+
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.Redact(v)
+	case []any:
+		for i := range v {
+			v[i] = r.redactValue(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = r.redactValue(v[key])
+		}
+	}
+	return v
+}
+
+// Restore puts the values back in place of their placeholders, unknown placeholders are left as they are.
+func (r *Redactor) Restore(s string) string {
+	if r == nil || !strings.Contains(s, "{{redacted:") {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return PlaceholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if value, ok := r.values[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// RestoreJSON restores the placeholders within the strings of a JSON document, escaping the values for it.
+func (r *Redactor) RestoreJSON(s string) string {
+	if r == nil || !strings.Contains(s, "{{redacted:") {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return PlaceholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		value, ok := r.values[placeholder]
+		if !ok {
+			return placeholder
+		}
+		escaped, _ := json.Marshal(value)
+		return string(escaped[1 : len(escaped)-1])
+	})
+}
+
+func (r *Redactor) allowed(value string) bool {
+	for _, pattern := range r.allow {
+		if pattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) placeholder(kind, value string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if placeholder, ok := r.placeholders[value]; ok {
+		return placeholder
+	}
+	r.counts[kind]++
+	placeholder := "{{redacted:" + kind + ":" + strconv.Itoa(r.counts[kind]) + "}}"
+	r.placeholders[value] = placeholder
+	r.values[placeholder] = value
+	// NOTE: only the kind and the placeholder are logged, the log is shown in the TUI and may be shared.
+	logging.Info("redacted a value before it was sent to the provider", "kind", kind, "placeholder", placeholder, "length", len(value))
+	return placeholder
+}
+
+// StreamRestorer restores the placeholders of an answer streamed in pieces, a placeholder may be split across them.
+type StreamRestorer struct {
+	redactor *Redactor
+	pending  string
+}
+
+func (r *Redactor) NewStreamRestorer() *StreamRestorer {
+	return &StreamRestorer{redactor: r}
+}
+
+// Write returns the restored part of the stream that can be shown, holding back what may be an unfinished placeholder.
+func (s *StreamRestorer) Write(delta string) string {
+	s.pending += delta
+	hold := len(s.pending)
+	if i := strings.LastIndex(s.pending, "{{"); i >= 0 && !strings.Contains(s.pending[i:], "}}") && len(s.pending)-i < maxPlaceholderLength {
+		hold = i
+	} else if strings.HasSuffix(s.pending, "{") {
+		hold = len(s.pending) - 1
+	}
+	out := s.redactor.Restore(s.pending[:hold])
+	s.pending = s.pending[hold:]
+	return out
+}
+
+// Flush returns whatever is held back once the stream is done.
+func (s *StreamRestorer) Flush() string {
+	out := s.redactor.Restore(s.pending)
+	s.pending = ""
+	return out
+}
+
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]+`)
+
+// shannonEntropy is the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, c := range s {
+		counts[c]++
+	}
+	var entropy float64
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// luhn tells card numbers apart from other long numbers.
+func luhn(s string) bool {
+	var digits []int
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
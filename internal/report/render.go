@@ -0,0 +1,355 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/session"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+)
+
+// Formats lists the formats a report is rendered in.
+var Formats = []Format{FormatMarkdown, FormatHTML}
+
+// maxInlineImage bounds the screenshots embedded in the HTML report, bigger ones are only listed.
+const maxInlineImage = 2 << 20
+
+// TemplateName is the file name of the template of a format, both built in and in the templates directory.
+func TemplateName(format Format) string {
+	return "report." + string(format) + ".tmpl"
+}
+
+// Data is what the templates render.
+type Data struct {
+	Engagement  string
+	GeneratedAt time.Time
+	Scope       config.Scope
+	Sections    []Narrative
+	Findings    []Finding
+	Severities  []SeverityCount
+	Hosts       []inventory.Host
+	Sessions    []Session
+	Commands    []Command
+	Evidence    []Evidence
+}
+
+// Narrative is a narrative section along with its heading.
+type Narrative struct {
+	Name    SectionName
+	Title   string
+	Content string
+}
+
+// Finding is a finding along with the address of its host.
+type Finding struct {
+	finding.Finding
+	Host      string
+	Created   time.Time
+	Anchor    string
+	Reference string
+}
+
+type SeverityCount struct {
+	Severity finding.Severity
+	Count    int
+}
+
+type Session struct {
+	ID       string
+	Title    string
+	Messages int64
+	Cost     float64
+	Created  time.Time
+}
+
+// Command is a tool execution of the appendix, taken from the audit log.
+type Command struct {
+	Started  time.Time
+	Agent    string
+	Tool     string
+	Command  string
+	ExitCode *int
+	IsError  bool
+}
+
+// Evidence is a file pulled out of the containers, screenshots are inlined in the HTML report.
+type Evidence struct {
+	Path   string
+	Size   int64
+	SHA256 string
+	Image  htmltemplate.URL
+}
+
+// Section returns the markdown of a narrative section, empty when nobody wrote it yet.
+func (d Data) Section(name string) string {
+	for _, section := range d.Sections {
+		if string(section.Name) == name {
+			return section.Content
+		}
+	}
+	return ""
+}
+
+// Sources are the services the report is collected from.
+type Sources struct {
+	Sections  Service
+	Findings  finding.Service
+	Sessions  session.Service
+	Inventory inventory.Service
+}
+
+// Collect gathers the data of the report. the commands come from the audit log, which the caller adds.
+func Collect(ctx context.Context, sources Sources) (Data, error) {
+	cfg := config.Get()
+	data := Data{
+		Engagement:  config.EngagementID(),
+		GeneratedAt: time.Now(),
+		Scope:       cfg.Scope,
+	}
+
+	written, err := sources.Sections.List(ctx)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to list the report sections: %w", err)
+	}
+	for _, name := range SectionNames {
+		narrative := Narrative{Name: name, Title: name.Title()}
+		for _, section := range written {
+			if section.Name == name {
+				narrative.Content = strings.TrimSpace(section.Content)
+			}
+		}
+		data.Sections = append(data.Sections, narrative)
+	}
+
+	data.Hosts, err = sources.Inventory.ListHosts(ctx)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to list the hosts: %w", err)
+	}
+	addresses := make(map[string]string, len(data.Hosts))
+	for _, host := range data.Hosts {
+		addresses[host.ID] = host.Address
+	}
+
+	findings, err := sources.Findings.List(ctx)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to list the findings: %w", err)
+	}
+	slices.SortStableFunc(findings, finding.Compare)
+	counts := make(map[finding.Severity]int)
+	for i, f := range findings {
+		counts[f.Severity]++
+		data.Findings = append(data.Findings, Finding{
+			Finding:   f,
+			Host:      addresses[f.HostID],
+			Created:   time.Unix(f.CreatedAt, 0),
+			Anchor:    fmt.Sprintf("finding-%d", i+1),
+			Reference: fmt.Sprintf("F-%02d", i+1),
+		})
+	}
+	for _, severity := range slices.Backward(finding.Severities) {
+		data.Severities = append(data.Severities, SeverityCount{Severity: severity, Count: counts[severity]})
+	}
+
+	sessions, err := sources.Sessions.List(ctx)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to list the sessions: %w", err)
+	}
+	for _, s := range slices.Backward(sessions) {
+		data.Sessions = append(data.Sessions, Session{
+			ID:       s.ID,
+			Title:    s.Title,
+			Messages: s.MessageCount,
+			Cost:     s.Cost,
+			Created:  time.Unix(s.CreatedAt, 0),
+		})
+	}
+
+	data.Evidence, err = CollectEvidence(config.EvidenceDir())
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to read the evidence: %w", err)
+	}
+	return data, nil
+}
+
+// CollectEvidence lists the files in the evidence directory with their checksums.
+func CollectEvidence(dir string) ([]Evidence, error) {
+	var evidence []Evidence
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		sum := sha256.Sum256(content)
+		item := Evidence{
+			Path:   filepath.ToSlash(rel),
+			Size:   int64(len(content)),
+			SHA256: hex.EncodeToString(sum[:]),
+		}
+		if kind := mime.TypeByExtension(filepath.Ext(path)); strings.HasPrefix(kind, "image/") && len(content) <= maxInlineImage {
+			item.Image = htmltemplate.URL("data:" + kind + ";base64," + base64.StdEncoding.EncodeToString(content))
+		}
+		evidence = append(evidence, item)
+		return nil
+	})
+	return evidence, err
+}
+
+// Render writes the report in a format. the template in dir, if there's one, wins over the built-in one.
+func Render(w io.Writer, format Format, data Data, dir string) error {
+	name := TemplateName(format)
+	source, err := loadTemplate(name, dir)
+	if err != nil {
+		return err
+	}
+	return execute(w, format, name, source, data)
+}
+
+// execute renders a template of a format into w, nothing is written when it fails.
+func execute(w io.Writer, format Format, name, source string, data any) error {
+	var buf bytes.Buffer
+	switch format {
+	case FormatMarkdown:
+		tmpl, err := texttemplate.New(name).Funcs(texttemplate.FuncMap(funcs)).Parse(source)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+	case FormatHTML:
+		tmpl, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).Funcs(htmltemplate.FuncMap{"markdown": markdown}).Parse(source)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// BuiltinTemplate returns the template of a format shipped with tandem.
+func BuiltinTemplate(format Format) ([]byte, error) {
+	return templates.ReadFile("templates/" + TemplateName(format))
+}
+
+func loadTemplate(name, dir string) (string, error) {
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	content, err := templates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// funcs are the helpers shared by the markdown and HTML templates.
+var funcs = map[string]any{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04 MST")
+	},
+	"day": func(t time.Time) string {
+		return t.Format("2 January 2006")
+	},
+	"title": func(s any) string {
+		value := fmt.Sprint(s)
+		if value == "" {
+			return value
+		}
+		return strings.ToUpper(value[:1]) + value[1:]
+	},
+	"upper": func(s any) string {
+		return strings.ToUpper(fmt.Sprint(s))
+	},
+	"size": func(n int64) string {
+		switch {
+		case n >= 1<<20:
+			return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+		case n >= 1<<10:
+			return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+		}
+		return fmt.Sprintf("%d B", n)
+	},
+	"clock": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("15:04:05")
+	},
+	// fence is a code fence longer than any run of backticks in the code it encloses.
+	"fence": func(code string) string {
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fence
+	},
+	// cell keeps a value from breaking out of a markdown table cell.
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// markdown renders the narrative and the findings' text in the HTML report. raw HTML in it is dropped rather than
+// trusted, the text comes from the agents which read it off the targets.
+func markdown(source string) (htmltemplate.HTML, error) {
+	var buf bytes.Buffer
+	if err := goldmark.New(goldmark.WithExtensions(extension.GFM)).Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return htmltemplate.HTML(buf.String()), nil
+}
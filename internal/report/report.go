@@ -0,0 +1,115 @@
+// Package report turns the engagement data, i.e. the findings, the sessions, the audit log and the evidence, into the
+// report handed to the client. the narrative sections are written by the reporter agent, or the operator.
+package report
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/pubsub"
+)
+
+type SectionName string
+
+const (
+	SectionExecutiveSummary SectionName = "executive_summary"
+	SectionScope            SectionName = "scope"
+	SectionMethodology      SectionName = "methodology"
+	SectionAttackNarrative  SectionName = "attack_narrative"
+	SectionConclusion       SectionName = "conclusion"
+)
+
+// SectionNames lists the narrative sections in the order they appear in the report.
+var SectionNames = []SectionName{
+	SectionExecutiveSummary,
+	SectionScope,
+	SectionMethodology,
+	SectionAttackNarrative,
+	SectionConclusion,
+}
+
+// Title is the heading of the section in the report.
+func (n SectionName) Title() string {
+	switch n {
+	case SectionExecutiveSummary:
+		return "Executive Summary"
+	case SectionScope:
+		return "Scope"
+	case SectionMethodology:
+		return "Methodology"
+	case SectionAttackNarrative:
+		return "Attack Narrative"
+	case SectionConclusion:
+		return "Conclusion"
+	}
+	return string(n)
+}
+
+// Section is the markdown of a narrative section of the report.
+type Section struct {
+	Name    SectionName
+	Content string
+	// Author is the agent, or the operator, who wrote the section last.
+	Author    string
+	UpdatedAt int64
+}
+
+// Service keeps the narrative sections of the report. writing a section again replaces it.
+type Service interface {
+	pubsub.Subscriber[Section]
+	Write(ctx context.Context, name SectionName, content, author string) (Section, error)
+	List(ctx context.Context) ([]Section, error)
+}
+
+type service struct {
+	*pubsub.Broker[Section]
+	q db.Querier
+}
+
+func (s *service) Write(ctx context.Context, name SectionName, content, author string) (Section, error) {
+	if !slices.Contains(SectionNames, name) {
+		return Section{}, fmt.Errorf("unknown report section %q", name)
+	}
+	dbSection, err := s.q.UpsertReportSection(ctx, db.UpsertReportSectionParams{
+		Name:    string(name),
+		Content: content,
+		Author:  author,
+	})
+	if err != nil {
+		return Section{}, err
+	}
+	section := fromDBItem(dbSection)
+	s.Publish(pubsub.UpdatedEvent, section)
+	return section, nil
+}
+
+func (s *service) List(ctx context.Context) ([]Section, error) {
+	dbSections, err := s.q.ListReportSections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]Section, len(dbSections))
+	for i, dbSection := range dbSections {
+		sections[i] = fromDBItem(dbSection)
+	}
+	return sections, nil
+}
+
+func fromDBItem(item db.ReportSection) Section {
+	return Section{
+		Name:      SectionName(item.Name),
+		Content:   item.Content,
+		Author:    item.Author,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
+
+func NewService(q db.Querier) Service {
+	broker := pubsub.NewBroker[Section]()
+	return &service{
+		broker,
+		q,
+	}
+}
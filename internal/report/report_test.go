@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yyovil/tandem/internal/finding"
+)
+
+func testData() Data {
+	exitCode := 0
+	return Data{
+		Engagement:  "acme",
+		GeneratedAt: time.Date(2025, 10, 20, 9, 0, 0, 0, time.UTC),
+		Sections: []Narrative{
+			{Name: SectionExecutiveSummary, Title: SectionExecutiveSummary.Title(), Content: "The **perimeter** fell. <script>alert(1)</script>"},
+			{Name: SectionMethodology, Title: SectionMethodology.Title()},
+		},
+		Findings: []Finding{
+			{Finding: finding.Finding{Title: "SQL injection in login", Severity: finding.SeverityCritical, Evidence: "' OR 1=1 -- <b>"}, Host: "10.10.10.5", Reference: "F-01", Anchor: "finding-1"},
+			{Finding: finding.Finding{Title: "Server banner | version", Severity: finding.SeverityInfo}, Reference: "F-02", Anchor: "finding-2"},
+		},
+		Commands: []Command{{Tool: "terminal", Command: "nmap -sV 10.10.10.5", ExitCode: &exitCode}},
+	}
+}
+
+func TestRender(t *testing.T) {
+	var md bytes.Buffer
+	if err := Render(&md, FormatMarkdown, testData(), ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"### F-01: SQL injection in login", "The **perimeter** fell.", "| 10.10.10.5 |", "`nmap -sV 10.10.10.5` | 0 |", "_Not written yet._"} {
+		if !strings.Contains(md.String(), expected) {
+			t.Errorf("expected the markdown report to contain %q", expected)
+		}
+	}
+
+	var html bytes.Buffer
+	if err := Render(&html, FormatHTML, testData(), ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"<strong>perimeter</strong>", "&#39; OR 1=1 -- &lt;b&gt;", `href="#finding-1"`} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("expected the HTML report to contain %q", expected)
+		}
+	}
+	if strings.Contains(html.String(), "<script>") {
+		t.Error("expected the raw HTML in the narrative to be dropped")
+	}
+}
+
+func TestRenderOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, TemplateName(FormatMarkdown)), []byte(`{{range .Findings}}{{upper .Severity}} {{.Title}}{{"\n"}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatMarkdown, testData(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "CRITICAL SQL injection in login\nINFO Server banner | version\n" {
+		t.Fatalf("expected the template in the directory to be used, got %q", buf.String())
+	}
+
+	// NOTE: a directory without the template of a format falls back to the built-in one.
+	buf.Reset()
+	if err := Render(&buf, FormatHTML, testData(), dir); err != nil || !strings.Contains(buf.String(), "<!DOCTYPE html>") {
+		t.Fatalf("expected the built-in HTML template, got %v", err)
+	}
+}
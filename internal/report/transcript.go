@@ -0,0 +1,275 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/models"
+	"github.com/yyovil/tandem/internal/session"
+)
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// TranscriptTemplateName is the file name of the transcript template of a format, both built in and in the templates
+// directory.
+func TranscriptTemplateName(format Format) string {
+	return "transcript." + string(format) + ".tmpl"
+}
+
+// Transcript is a session followed, depth first, by the sessions of the subagents it handed tasks to.
+type Transcript struct {
+	Engagement       string
+	GeneratedAt      time.Time
+	Reasoning        bool
+	Sessions         []TranscriptSession
+	PromptTokens     int64
+	CompletionTokens int64
+	Cost             float64
+}
+
+type TranscriptSession struct {
+	ID               string
+	Title            string
+	Anchor           string
+	Depth            int
+	ParentTitle      string
+	ParentAnchor     string
+	Created          time.Time
+	PromptTokens     int64
+	CompletionTokens int64
+	Cost             float64
+	Messages         []TranscriptMessage
+}
+
+type TranscriptMessage struct {
+	Role    message.MessageRole
+	Model   string
+	Created time.Time
+	Parts   []TranscriptPart
+}
+
+type PartKind string
+
+const (
+	PartText      PartKind = "text"
+	PartReasoning PartKind = "reasoning"
+	PartTool      PartKind = "tool"
+	PartImage     PartKind = "image"
+)
+
+// TranscriptPart is a part of a message. a tool call carries its result, and the anchor of the session of the
+// subagent when it handed one a task.
+type TranscriptPart struct {
+	Kind     PartKind
+	Text     string
+	Tool     string
+	Input    string
+	Output   string
+	IsError  bool
+	Finished bool
+	Task     string
+	Images   []TranscriptImage
+}
+
+// TranscriptImage is an attachment or a screenshot, inlined in the HTML transcript when it's small enough.
+type TranscriptImage struct {
+	Path string
+	URL  htmltemplate.URL
+}
+
+// CollectTranscript reads a session and the sessions of its subagents. reasoning tells whether the reasoning of the
+// models is kept.
+func CollectTranscript(ctx context.Context, sessions session.Service, messages message.Service, id string, reasoning bool) (Transcript, error) {
+	transcript := Transcript{GeneratedAt: time.Now(), Reasoning: reasoning}
+	root, err := sessions.Get(ctx, id)
+	if err != nil {
+		return Transcript{}, err
+	}
+	collector := transcriptCollector{sessions: sessions, messages: messages, transcript: &transcript, seen: map[string]bool{}}
+	if err := collector.collect(ctx, root, 0, nil); err != nil {
+		return Transcript{}, err
+	}
+	for _, s := range transcript.Sessions {
+		transcript.PromptTokens += s.PromptTokens
+		transcript.CompletionTokens += s.CompletionTokens
+		transcript.Cost += s.Cost
+	}
+	return transcript, nil
+}
+
+// RenderTranscript writes a transcript in a format. the template in dir, if there's one, wins over the built-in one.
+func RenderTranscript(w io.Writer, format Format, transcript Transcript, dir string) error {
+	name := TranscriptTemplateName(format)
+	source, err := loadTemplate(name, dir)
+	if err != nil {
+		return err
+	}
+	return execute(w, format, name, source, transcript)
+}
+
+type transcriptCollector struct {
+	sessions   session.Service
+	messages   message.Service
+	transcript *Transcript
+	seen       map[string]bool
+}
+
+// collect appends a session and then the sessions of the tasks it handed out, in the order it handed them out.
+func (c *transcriptCollector) collect(ctx context.Context, s session.Session, depth int, parent *TranscriptSession) error {
+	c.seen[s.ID] = true
+	item := TranscriptSession{
+		ID:               s.ID,
+		Title:            s.Title,
+		Anchor:           "session-" + s.ID,
+		Depth:            depth,
+		Created:          unixTime(s.CreatedAt),
+		PromptTokens:     s.PromptTokens,
+		CompletionTokens: s.CompletionTokens,
+		Cost:             s.Cost,
+	}
+	if parent != nil {
+		item.ParentTitle, item.ParentAnchor = parent.Title, parent.Anchor
+	}
+
+	messages, err := c.messages.List(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+	results := make(map[string]message.ToolResult)
+	for _, m := range messages {
+		for _, result := range m.ToolResults() {
+			results[result.ToolCallID] = result
+		}
+	}
+
+	var tasks []session.Session
+	paired := make(map[string]bool)
+	for _, m := range messages {
+		out := TranscriptMessage{Role: m.Role, Created: unixTime(m.CreatedAt)}
+		if m.Model != "" {
+			out.Model = string(m.Model)
+			if model, ok := models.SupportedModels[m.Model]; ok {
+				out.Model = model.Name
+			}
+		}
+		for _, part := range m.Parts {
+			switch part := part.(type) {
+			case message.TextContent:
+				if strings.TrimSpace(part.Text) != "" {
+					out.Parts = append(out.Parts, TranscriptPart{Kind: PartText, Text: part.Text})
+				}
+			case message.ReasoningContent:
+				if c.transcript.Reasoning && strings.TrimSpace(part.Thinking) != "" {
+					out.Parts = append(out.Parts, TranscriptPart{Kind: PartReasoning, Text: part.Thinking})
+				}
+			case message.BinaryContent:
+				out.Parts = append(out.Parts, TranscriptPart{Kind: PartImage, Images: []TranscriptImage{transcriptImage(part)}})
+			case message.ToolCall:
+				tool := TranscriptPart{Kind: PartTool, Tool: part.Name, Input: indentJSON(part.Input)}
+				if result, ok := results[part.ID]; ok {
+					paired[part.ID] = true
+					tool.Output, tool.IsError, tool.Finished = result.Content, result.IsError, true
+					for _, image := range result.Images {
+						tool.Images = append(tool.Images, transcriptImage(image))
+					}
+				}
+				// NOTE: the session of a task is created with the ID of the tool call handing it out.
+				task, err := c.sessions.Get(ctx, part.ID)
+				switch {
+				case err == nil && task.ParentSessionID == s.ID && !c.seen[task.ID]:
+					tool.Task = "session-" + task.ID
+					tasks = append(tasks, task)
+				case err != nil && !errors.Is(err, sql.ErrNoRows):
+					return err
+				}
+				out.Parts = append(out.Parts, tool)
+			case message.ToolResult:
+				if !paired[part.ToolCallID] {
+					out.Parts = append(out.Parts, TranscriptPart{Kind: PartTool, Tool: part.Name, Output: part.Content, IsError: part.IsError, Finished: true})
+				}
+			}
+		}
+		if len(out.Parts) > 0 {
+			item.Messages = append(item.Messages, out)
+		}
+	}
+
+	c.transcript.Sessions = append(c.transcript.Sessions, item)
+	for _, task := range tasks {
+		if err := c.collect(ctx, task, depth+1, &item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func transcriptImage(content message.BinaryContent) TranscriptImage {
+	image := TranscriptImage{Path: content.Path}
+	if strings.HasPrefix(content.MIMEType, "image/") && len(content.Data) <= maxInlineImage {
+		image.URL = htmltemplate.URL("data:" + content.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(content.Data))
+	}
+	return image
+}
+
+// indentJSON pretty prints the arguments of a tool call, they're shown as they are when they aren't JSON.
+func indentJSON(input string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(input), "", "  "); err != nil {
+		return input
+	}
+	return buf.String()
+}
+
+// unixTime reads the timestamps of the sessions and messages, stored in seconds by the database and in milliseconds
+// by the agents.
+func unixTime(timestamp int64) time.Time {
+	switch {
+	case timestamp <= 0:
+		return time.Time{}
+	case timestamp >= 1e11:
+		return time.UnixMilli(timestamp)
+	}
+	return time.Unix(timestamp, 0)
+}
+
+// WriteTranscript renders a transcript into dir in each of the formats, named after its first session. it returns the
+// paths it wrote.
+func WriteTranscript(transcript Transcript, formats []Format, dir, templates string) ([]string, error) {
+	if len(transcript.Sessions) == 0 {
+		return nil, errors.New("the transcript has no sessions")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	root := transcript.Sessions[0]
+	name := "transcript-" + root.ID
+	if slug := strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(root.Title), "-"), "-"); slug != "" {
+		name = fmt.Sprintf("transcript-%.40s-%.8s", slug, root.ID)
+	}
+	var paths []string
+	for _, format := range formats {
+		var buf bytes.Buffer
+		if err := RenderTranscript(&buf, format, transcript, templates); err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, name+"."+string(format))
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
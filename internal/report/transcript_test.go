@@ -0,0 +1,88 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/session"
+)
+
+func TestTranscript(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	q := db.New(conn)
+	sessions, messages := session.NewService(q), message.NewService(q)
+
+	root, err := sessions.Create(ctx, "Recon of acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := sessions.CreateTaskSession(ctx, "call-1", root.ID, "reconnoiter agent's session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []struct {
+		session string
+		params  message.CreateMessageParams
+	}{
+		{root.ID, message.CreateMessageParams{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "scan 10.10.10.5"}}}},
+		{root.ID, message.CreateMessageParams{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ReasoningContent{Thinking: "the reconnoiter should do it"},
+			message.ToolCall{ID: "call-1", Name: "agent", Input: `{"agent_name":"reconnoiter"}`, Finished: true},
+		}}},
+		{root.ID, message.CreateMessageParams{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-1", Name: "agent", Content: "22/tcp open ```ssh```"}}}},
+		{task.ID, message.CreateMessageParams{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "port 22 is open"}}}},
+	} {
+		if _, err := messages.Create(ctx, m.session, m.params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transcript, err := CollectTranscript(ctx, sessions, messages, root.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transcript.Sessions) != 2 || transcript.Sessions[1].Depth != 1 || transcript.Sessions[1].ParentAnchor != transcript.Sessions[0].Anchor {
+		t.Fatalf("expected the task session after the root one, got %+v", transcript.Sessions)
+	}
+	if messages := transcript.Sessions[0].Messages; len(messages) != 2 || len(messages[1].Parts) != 1 || messages[1].Parts[0].Task != "session-call-1" || messages[1].Parts[0].Output == "" {
+		t.Fatalf("expected the tool call paired with its result and its task without the reasoning, got %+v", messages)
+	}
+
+	var md bytes.Buffer
+	if err := RenderTranscript(&md, FormatMarkdown, transcript, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"# Session Transcript: Recon of acme", "[the subagent's session](#session-call-1)", "````\n22/tcp open ```ssh```\n````", "port 22 is open"} {
+		if !strings.Contains(md.String(), expected) {
+			t.Errorf("expected the markdown transcript to contain %q", expected)
+		}
+	}
+	var html bytes.Buffer
+	if err := RenderTranscript(&html, FormatHTML, transcript, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), `<section id="session-call-1">`) || strings.Contains(html.String(), "should do it") {
+		t.Error("expected the task session in the HTML transcript without the reasoning")
+	}
+}
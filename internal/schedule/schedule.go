@@ -0,0 +1,305 @@
+// Package schedule decides whether the rules of engagement allow testing at a given time. the windows recur every
+// week, the blackouts are one-off periods that win over them.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// horizon is how far ahead the next change is looked for, the windows repeat every week.
+const horizon = 8
+
+// maxWait bounds a single sleep so that a wait notices the clock jumping, e.g. after a suspend.
+const maxWait = time.Minute
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Schedule is the parsed form of the schedule section of swarm.json.
+type Schedule struct {
+	loc       *time.Location
+	windows   []window
+	blackouts []blackout
+}
+
+type window struct {
+	days                [7]bool
+	startHour, startMin int
+	endHour, endMin     int
+	crossesMidnight     bool
+}
+
+type blackout struct {
+	from, to time.Time
+	reason   string
+}
+
+// State is whether testing is allowed at a time, why not, and until when that holds.
+type State struct {
+	Open   bool
+	Reason string
+	// Until is when the state changes next, zero when it never does.
+	Until time.Time
+}
+
+// New parses the schedule, an empty one allows testing at any time.
+func New(cfg config.Schedule) (*Schedule, error) {
+	s := &Schedule{loc: time.Local}
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("schedule timezone: %w", err)
+		}
+		s.loc = loc
+	}
+
+	for i, w := range cfg.Windows {
+		var parsed window
+		var err error
+		if parsed.startHour, parsed.startMin, err = parseClock(w.Start); err != nil {
+			return nil, fmt.Errorf("schedule window %d start: %w", i+1, err)
+		}
+		if parsed.endHour, parsed.endMin, err = parseClock(w.End); err != nil {
+			return nil, fmt.Errorf("schedule window %d end: %w", i+1, err)
+		}
+		parsed.crossesMidnight = parsed.endHour*60+parsed.endMin <= parsed.startHour*60+parsed.startMin
+		if len(w.Days) == 0 {
+			parsed.days = [7]bool{true, true, true, true, true, true, true}
+		}
+		for _, day := range w.Days {
+			// NOTE: lowercasing can shorten a string, it's sliced by its own length.
+			name := strings.ToLower(day)
+			weekday, ok := weekdays[name[:min(3, len(name))]]
+			if !ok {
+				return nil, fmt.Errorf("schedule window %d: unknown day %q", i+1, day)
+			}
+			parsed.days[weekday] = true
+		}
+		s.windows = append(s.windows, parsed)
+	}
+
+	for i, b := range cfg.Blackouts {
+		from, err := parseTime(b.From, s.loc)
+		if err != nil {
+			return nil, fmt.Errorf("schedule blackout %d from: %w", i+1, err)
+		}
+		to, err := parseTime(b.To, s.loc)
+		if err != nil {
+			return nil, fmt.Errorf("schedule blackout %d to: %w", i+1, err)
+		}
+		if !to.After(from) {
+			return nil, fmt.Errorf("schedule blackout %d ends before it starts", i+1)
+		}
+		s.blackouts = append(s.blackouts, blackout{from: from, to: to, reason: b.Reason})
+	}
+	return s, nil
+}
+
+// FromConfig returns the schedule of the loaded config.
+func FromConfig() (*Schedule, error) {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil, fmt.Errorf("config not loaded")
+	}
+	return New(cfg.Schedule)
+}
+
+// Defined reports whether the engagement restricts when testing happens at all.
+func (s *Schedule) Defined() bool {
+	return len(s.windows) > 0 || len(s.blackouts) > 0
+}
+
+// At returns the state of the schedule at t.
+func (s *Schedule) At(t time.Time) State {
+	open, reason := s.allowed(t)
+	state := State{Open: open, Reason: reason}
+	// NOTE: the state can only change at the bounds of a window or a blackout, the first bound that flips it wins.
+	for _, bound := range s.bounds(t) {
+		if next, _ := s.allowed(bound); next != open {
+			state.Until = bound
+			break
+		}
+	}
+	return state
+}
+
+func (s *Schedule) allowed(t time.Time) (bool, string) {
+	for _, b := range s.blackouts {
+		if !t.Before(b.from) && t.Before(b.to) {
+			if b.reason != "" {
+				return false, "blackout: " + b.reason
+			}
+			return false, "blackout"
+		}
+	}
+	if len(s.windows) == 0 {
+		return true, ""
+	}
+	t = t.In(s.loc)
+	for _, w := range s.windows {
+		// NOTE: a window that crosses midnight may have started the day before.
+		for _, day := range []int{0, -1} {
+			start, end := w.on(t.AddDate(0, 0, day), s.loc)
+			if w.days[start.Weekday()] && !t.Before(start) && t.Before(end) {
+				return true, ""
+			}
+		}
+	}
+	return false, "outside the testing windows"
+}
+
+// on returns the bounds of the window starting on the day of t.
+func (w window) on(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	year, month, day := t.Date()
+	start := time.Date(year, month, day, w.startHour, w.startMin, 0, 0, loc)
+	if w.crossesMidnight {
+		day++
+	}
+	return start, time.Date(year, month, day, w.endHour, w.endMin, 0, 0, loc)
+}
+
+// bounds returns the bounds of the windows and blackouts after t in order.
+func (s *Schedule) bounds(t time.Time) []time.Time {
+	var bounds []time.Time
+	add := func(bound time.Time) {
+		if bound.After(t) {
+			bounds = append(bounds, bound)
+		}
+	}
+	local := t.In(s.loc)
+	for _, w := range s.windows {
+		for day := -1; day <= horizon; day++ {
+			start, end := w.on(local.AddDate(0, 0, day), s.loc)
+			if w.days[start.Weekday()] {
+				add(start)
+				add(end)
+			}
+		}
+	}
+	for _, b := range s.blackouts {
+		add(b.from)
+		add(b.to)
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].Before(bounds[j]) })
+	return bounds
+}
+
+// Wait blocks until testing is allowed, calling paused first when it isn't.
+func (s *Schedule) Wait(ctx context.Context, paused func(State)) error {
+	notified := false
+	for {
+		state := s.At(time.Now())
+		if state.Open {
+			return nil
+		}
+		if state.Until.IsZero() {
+			return fmt.Errorf("testing isn't allowed anymore: %s", state.Reason)
+		}
+		if !notified && paused != nil {
+			paused(state)
+			notified = true
+		}
+		timer := time.NewTimer(min(time.Until(state.Until), maxWait))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Watch calls changed whenever the state of the schedule changes until ctx is done.
+func (s *Schedule) Watch(ctx context.Context, changed func(State)) {
+	open := s.At(time.Now()).Open
+	ticker := time.NewTicker(maxWait)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if state := s.At(now); state.Open != open {
+				open = state.Open
+				changed(state)
+			}
+		}
+	}
+}
+
+func parseClock(value string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q isn't a time like 09:00", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func parseTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q isn't a time like 2025-10-24 18:00", value)
+}
+
+// FormatRemaining shows a duration the way the status bar does, e.g. 2h05m or 45m.
+func FormatRemaining(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%02dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// Hold runs before a tool call, it waits for the window to open or refuses the call depending on the schedule's
+// onClosed action. who names the agent in the notifications.
+func Hold(ctx context.Context, who string) error {
+	s, err := FromConfig()
+	if err != nil {
+		return err
+	}
+	if !s.Defined() {
+		return nil
+	}
+	state := s.At(time.Now())
+	if state.Open {
+		return nil
+	}
+	if config.Get().Schedule.OnClosed == config.ScheduleNotify {
+		if state.Until.IsZero() {
+			return fmt.Errorf("testing isn't allowed right now (%s)", state.Reason)
+		}
+		return fmt.Errorf("testing isn't allowed right now (%s), the window opens at %s. stop here and tell the operator, they resume the work then", state.Reason, state.Until.In(s.loc).Format("Mon 2006-01-02 15:04 MST"))
+	}
+	err = s.Wait(ctx, func(state State) {
+		logging.WarnPersist(fmt.Sprintf("%s is paused, %s. it resumes at %s", who, state.Reason, state.Until.In(s.loc).Format("Mon 15:04 MST")))
+	})
+	if err == nil {
+		logging.InfoPersist(fmt.Sprintf("the testing window is open, %s resumes", who))
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+)
+
+func TestSchedule(t *testing.T) {
+	s, err := New(config.Schedule{
+		Timezone: "Europe/London",
+		Windows: []config.TimeWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:30"},
+			{Days: []string{"saturday"}, Start: "22:00", End: "02:00"},
+		},
+		Blackouts: []config.Blackout{
+			{From: "2025-10-22 12:00", To: "2025-10-22 14:00", Reason: "change freeze"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	london, _ := time.LoadLocation("Europe/London")
+	at := func(value string) time.Time {
+		t.Helper()
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, london)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		now   string
+		open  bool
+		until string
+	}{
+		{"2025-10-20 10:00", true, "2025-10-20 17:30"},  // monday
+		{"2025-10-20 17:30", false, "2025-10-21 09:00"}, // the window ends
+		{"2025-10-22 12:30", false, "2025-10-22 14:00"}, // blackout
+		{"2025-10-22 11:00", true, "2025-10-22 12:00"},  // the blackout cuts the window short
+		{"2025-10-24 18:00", false, "2025-10-25 22:00"}, // friday evening waits for saturday night
+		{"2025-10-26 01:00", true, "2025-10-26 02:00"},  // past midnight of the saturday window
+		{"2025-10-26 03:00", false, "2025-10-27 09:00"}, // sunday
+	}
+	for _, test := range tests {
+		state := s.At(at(test.now))
+		if state.Open != test.open || !state.Until.Equal(at(test.until)) {
+			t.Errorf("%s: expected open %v until %s, got %v until %s (%s)", test.now, test.open, test.until, state.Open, state.Until.In(london).Format("2006-01-02 15:04"), state.Reason)
+		}
+	}
+
+	if state := s.At(at("2025-10-22 12:30")); state.Reason != "blackout: change freeze" {
+		t.Errorf("expected the blackout to be the reason, got %q", state.Reason)
+	}
+}
+
+func TestScheduleUndefined(t *testing.T) {
+	s, err := New(config.Schedule{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := s.At(time.Now()); s.Defined() || !state.Open || !state.Until.IsZero() {
+		t.Fatalf("expected an empty schedule to always allow testing, got %+v", state)
+	}
+
+	for _, invalid := range []config.Schedule{
+		{Timezone: "Mars/Olympus"},
+		{Windows: []config.TimeWindow{{Start: "9am", End: "17:00"}}},
+		{Windows: []config.TimeWindow{{Days: []string{"funday"}, Start: "09:00", End: "17:00"}}},
+		{Windows: []config.TimeWindow{{Days: []string{"ẞ"}, Start: "09:00", End: "17:00"}}},
+		{Blackouts: []config.Blackout{{From: "2025-10-22 14:00", To: "2025-10-22 12:00"}}},
+	} {
+		if _, err := New(invalid); err == nil {
+			t.Errorf("expected %+v to be rejected", invalid)
+		}
+	}
+}
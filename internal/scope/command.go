@@ -0,0 +1,120 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,63}$`)
+	// lastOctetRange is the nmap style range of the last octet, e.g. 10.10.10.1-50.
+	lastOctetRange = regexp.MustCompile(`^(\d{1,3}\.\d{1,3}\.\d{1,3}\.)(\d{1,3})-(\d{1,3})$`)
+)
+
+// fileExtensions end arguments that look like hostnames but are far more likely to be files, e.g. users.txt.
+var fileExtensions = map[string]bool{
+	"txt": true, "lst": true, "log": true, "xml": true, "json": true, "yaml": true, "yml": true, "csv": true,
+	"html": true, "htm": true, "php": true, "asp": true, "aspx": true, "jsp": true, "js": true, "css": true,
+	"py": true, "rb": true, "pl": true, "sh": true, "ps1": true, "exe": true, "dll": true, "so": true,
+	"nse": true, "conf": true, "cfg": true, "ini": true, "gz": true, "tgz": true, "zip": true, "tar": true,
+	"gnmap": true, "nmap": true, "pcap": true, "pcapng": true, "key": true, "pem": true, "crt": true, "md": true,
+}
+
+// Targets picks the hosts, addresses and networks out of the arguments of a command. it's a heuristic, arguments are
+// split on whitespace, = and , so that flags like --url=http://host and lists like 10.0.0.1,10.0.0.2 are looked into.
+func Targets(args []string) []string {
+	var targets []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		fields := strings.FieldsFunc(arg, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\n' || r == '=' || r == ',' || r == '"' || r == '\'' || r == ';' || r == '|' || r == '&'
+		})
+		for _, field := range fields {
+			for _, target := range targetsOf(field) {
+				if !seen[target] {
+					seen[target] = true
+					targets = append(targets, target)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+func targetsOf(field string) []string {
+	field = strings.ToLower(strings.TrimSpace(field))
+	if strings.HasPrefix(field, "-") || field == "" {
+		return nil
+	}
+	if strings.Contains(field, "://") {
+		u, err := url.Parse(field)
+		if err != nil || u.Hostname() == "" {
+			return nil
+		}
+		return []string{u.Hostname()}
+	}
+	// user@host and scp style user@host:path
+	if i := strings.LastIndex(field, "@"); i >= 0 {
+		field = field[i+1:]
+		if !strings.Contains(field, "[") && strings.Count(field, ":") == 1 {
+			field = field[:strings.Index(field, ":")]
+		}
+	}
+
+	if prefix, err := netip.ParsePrefix(field); err == nil {
+		return []string{prefix.Masked().String()}
+	}
+	// host/path without a scheme, e.g. example.com/admin
+	if i := strings.Index(field, "/"); i > 0 {
+		field = field[:i]
+	}
+	if match := lastOctetRange.FindStringSubmatch(field); match != nil {
+		return []string{match[1] + match[2], match[1] + match[3]}
+	}
+	host := normalizeHost(field)
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []string{addr.Unmap().String()}
+	}
+	if hostnamePattern.MatchString(host) && !fileExtensions[host[strings.LastIndex(host, ".")+1:]] {
+		return []string{host}
+	}
+	return nil
+}
+
+// CheckCommand returns an error wrapping ErrOutOfScope unless every target Targets finds in the command is in scope.
+func (s *Scope) CheckCommand(ctx context.Context, cmd []string) error {
+	for _, target := range Targets(cmd) {
+		var err error
+		if prefix, parseErr := netip.ParsePrefix(target); parseErr == nil {
+			err = s.checkPrefix(prefix)
+		} else {
+			err = s.Check(ctx, target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPrefix requires a network to lie within an included network as a whole and to not overlap any excluded one.
+func (s *Scope) checkPrefix(prefix netip.Prefix) error {
+	for _, r := range s.exclude {
+		if r.prefix.IsValid() && r.prefix.Overlaps(prefix) {
+			return fmt.Errorf("%w: %s overlaps %s which is excluded", ErrOutOfScope, prefix, r.raw)
+		}
+	}
+	if len(s.include) == 0 {
+		return nil
+	}
+	for _, r := range s.include {
+		if r.prefix.IsValid() && r.prefix.Bits() <= prefix.Bits() && r.prefix.Contains(prefix.Addr()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s isn't within the engagement's scope", ErrOutOfScope, prefix)
+}
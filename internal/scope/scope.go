@@ -0,0 +1,201 @@
+// Package scope decides whether a target is within the scope of the engagement.
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/config"
+)
+
+var ErrOutOfScope = errors.New("out of scope")
+
+// Scope is the parsed form of the scope section of swarm.json.
+type Scope struct {
+	include []rule
+	exclude []rule
+}
+
+// rule is a single scope entry, either a network or a hostname. a hostname starting with *. matches its subdomains.
+type rule struct {
+	raw      string
+	prefix   netip.Prefix
+	hostname string
+	wildcard bool
+}
+
+// New parses the include and exclude entries, an empty include list puts everything that isn't excluded in scope.
+func New(include, exclude []string) (*Scope, error) {
+	s := &Scope{}
+	for _, entry := range include {
+		r, err := parseRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		s.include = append(s.include, r)
+	}
+	for _, entry := range exclude {
+		r, err := parseRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		s.exclude = append(s.exclude, r)
+	}
+	return s, nil
+}
+
+// FromConfig returns the scope of the loaded config.
+func FromConfig() (*Scope, error) {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil, fmt.Errorf("config not loaded")
+	}
+	return New(cfg.Scope.Include, cfg.Scope.Exclude)
+}
+
+// Defined reports whether the engagement declares what's in scope at all.
+func (s *Scope) Defined() bool {
+	return len(s.include) > 0
+}
+
+// Check returns an error wrapping ErrOutOfScope unless host, with or without a port, is in scope. hostnames that
+// don't match a hostname entry are resolved and every address they resolve to has to be in scope.
+func (s *Scope) Check(ctx context.Context, host string) error {
+	_, err := s.check(ctx, host, false)
+	return err
+}
+
+// Resolve checks host like Check and returns the addresses it was checked with, a hostname is always resolved.
+// connecting to them rather than resolving the name again keeps a name that resolves to another address the second
+// time, DNS rebinding, from slipping out of scope.
+func (s *Scope) Resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	return s.check(ctx, host, true)
+}
+
+func (s *Scope) check(ctx context.Context, host string, resolveAlways bool) ([]netip.Addr, error) {
+	hostname := normalizeHost(host)
+	if hostname == "" {
+		return nil, fmt.Errorf("%w: no host given", ErrOutOfScope)
+	}
+
+	var addrs []netip.Addr
+	var resolveErr error
+	resolved := false
+	if addr, err := netip.ParseAddr(hostname); err == nil {
+		addrs = []netip.Addr{addr.Unmap()}
+		hostname = ""
+		resolved = true
+	}
+	resolve := func() ([]netip.Addr, error) {
+		if resolved {
+			return addrs, resolveErr
+		}
+		resolved = true
+		found, err := net.DefaultResolver.LookupNetIP(ctx, "ip", hostname)
+		if err != nil {
+			resolveErr = fmt.Errorf("%w: couldn't resolve %s to check it against the scope: %v", ErrOutOfScope, hostname, err)
+			return nil, resolveErr
+		}
+		for _, addr := range found {
+			addrs = append(addrs, addr.Unmap())
+		}
+		return addrs, nil
+	}
+
+	for _, r := range s.exclude {
+		if hostname != "" && r.matchesHostname(hostname) {
+			return nil, fmt.Errorf("%w: %s is excluded by %s", ErrOutOfScope, host, r.raw)
+		}
+		if !r.prefix.IsValid() {
+			continue
+		}
+		// NOTE: a name that doesn't resolve can't be connected to either, so it can't reach an excluded network.
+		excludedAddrs, _ := resolve()
+		for _, addr := range excludedAddrs {
+			if r.prefix.Contains(addr) {
+				return nil, fmt.Errorf("%w: %s is excluded by %s", ErrOutOfScope, describe(host, hostname, addr), r.raw)
+			}
+		}
+	}
+
+	if len(s.include) == 0 {
+		return addrs, nil
+	}
+	if hostname != "" {
+		for _, r := range s.include {
+			if r.matchesHostname(hostname) {
+				return addrs, nil
+			}
+		}
+	}
+	if _, err := resolve(); err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		included := false
+		for _, r := range s.include {
+			if r.prefix.IsValid() && r.prefix.Contains(addr) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return nil, fmt.Errorf("%w: %s isn't in the engagement's scope", ErrOutOfScope, describe(host, hostname, addr))
+		}
+	}
+	return addrs, nil
+}
+
+func (r rule) matchesHostname(hostname string) bool {
+	if r.hostname == "" {
+		return false
+	}
+	if r.wildcard {
+		return strings.HasSuffix(hostname, "."+r.hostname)
+	}
+	return hostname == r.hostname
+}
+
+func parseRule(entry string) (rule, error) {
+	raw := strings.TrimSpace(entry)
+	if raw == "" {
+		return rule{}, fmt.Errorf("empty scope entry")
+	}
+	if prefix, err := netip.ParsePrefix(raw); err == nil {
+		return rule{raw: raw, prefix: prefix.Masked()}, nil
+	}
+	if addr, err := netip.ParseAddr(raw); err == nil {
+		addr = addr.Unmap()
+		return rule{raw: raw, prefix: netip.PrefixFrom(addr, addr.BitLen())}, nil
+	}
+
+	hostname := strings.ToLower(strings.TrimSuffix(raw, "."))
+	wildcard := strings.HasPrefix(hostname, "*.")
+	hostname = strings.TrimPrefix(hostname, "*.")
+	if hostname == "" || strings.ContainsAny(hostname, "*/: ") {
+		return rule{}, fmt.Errorf("invalid scope entry %q, expected an IP, a CIDR, a hostname or *.domain", entry)
+	}
+	return rule{raw: raw, hostname: hostname, wildcard: wildcard}, nil
+}
+
+// describe names a host in an error, along with the address it resolved to if it is a hostname.
+func describe(host, hostname string, addr netip.Addr) string {
+	if hostname == "" {
+		return host
+	}
+	return fmt.Sprintf("%s (%s)", host, addr)
+}
+
+// normalizeHost strips the port, the IPv6 brackets and the trailing dot off a host.
+func normalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,127 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	s, err := New(
+		[]string{"10.10.10.0/24", "192.168.1.5", "app.example.com", "*.corp.example.com", "2001:db8::/64"},
+		[]string{"10.10.10.1", "vpn.corp.example.com"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host    string
+		inScope bool
+	}{
+		{"10.10.10.5", true},
+		{"10.10.10.5:8443", true},
+		{"10.10.10.1", false},
+		{"10.10.11.5", false},
+		{"192.168.1.5", true},
+		{"192.168.1.6", false},
+		{"APP.example.com.", true},
+		{"app.example.com:443", true},
+		{"www.corp.example.com", true},
+		{"corp.example.com", false},
+		{"vpn.corp.example.com", false},
+		{"[2001:db8::1]:443", true},
+		{"::ffff:10.10.10.5", true},
+	}
+	for _, test := range tests {
+		err := s.Check(context.Background(), test.host)
+		if test.inScope && err != nil {
+			t.Errorf("%s: expected it in scope, got %v", test.host, err)
+		}
+		if !test.inScope && !errors.Is(err, ErrOutOfScope) {
+			t.Errorf("%s: expected it out of scope, got %v", test.host, err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s, err := New([]string{"10.10.10.0/24", "localhost"}, []string{"10.10.10.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addrs, err := s.Resolve(context.Background(), "10.10.10.5:443"); err != nil || len(addrs) != 1 || addrs[0].String() != "10.10.10.5" {
+		t.Errorf("expected the address itself, got %v %v", addrs, err)
+	}
+	// NOTE: in scope by its name, it's resolved all the same so that it's connected to at what it was checked with.
+	if addrs, err := s.Resolve(context.Background(), "localhost"); err != nil || len(addrs) == 0 {
+		t.Errorf("expected the addresses of localhost, got %v %v", addrs, err)
+	}
+	if _, err := s.Resolve(context.Background(), "10.10.10.1"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("expected an excluded address refused, got %v", err)
+	}
+}
+
+func TestUndefinedScope(t *testing.T) {
+	s, err := New(nil, []string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Defined() {
+		t.Fatal("expected the scope to be undefined")
+	}
+	if err := s.Check(context.Background(), "192.168.1.1"); err != nil {
+		t.Fatalf("expected everything but the exclusions in scope, got %v", err)
+	}
+	if err := s.Check(context.Background(), "10.1.2.3"); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("expected the exclusion to apply, got %v", err)
+	}
+}
+
+func TestInvalidEntry(t *testing.T) {
+	if _, err := New([]string{"http://example.com"}, nil); err == nil {
+		t.Fatal("expected a URL to be rejected")
+	}
+}
+
+func TestTargets(t *testing.T) {
+	targets := Targets([]string{
+		"nmap", "-sV", "-p", "22,80", "10.10.10.0/24", "10.10.10.5:8443", "--script=http-title",
+		"-oN", "scan.nmap", "-iL", "hosts.txt", "http://App.Example.com:8080/login",
+		"admin@192.168.1.5:/tmp/loot", "10.10.10.1-50", "www.corp.example.com/admin", "/usr/share/wordlists/rockyou.txt",
+	})
+	expected := []string{
+		"10.10.10.0/24", "10.10.10.5", "app.example.com", "192.168.1.5", "10.10.10.1", "10.10.10.50", "www.corp.example.com",
+	}
+	if strings.Join(targets, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected %v, got %v", expected, targets)
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	s, err := New([]string{"10.10.10.0/24", "*.corp.example.com"}, []string{"10.10.10.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cmd     []string
+		inScope bool
+	}{
+		{[]string{"nmap", "-sV", "10.10.10.5"}, true},
+		{[]string{"curl", "-k", "https://www.corp.example.com/"}, true},
+		{[]string{"nmap", "10.10.10.128/25"}, true},
+		{[]string{"nmap", "10.10.0.0/16"}, false},
+		{[]string{"nmap", "10.10.10.0/30"}, false},
+		{[]string{"sh", "-c", "ping -c1 10.10.10.5 && ping -c1 10.10.11.5"}, false},
+		{[]string{"cat", "/etc/hosts"}, true},
+	}
+	for _, test := range tests {
+		err := s.CheckCommand(context.Background(), test.cmd)
+		if test.inScope && err != nil {
+			t.Errorf("%v: expected it in scope, got %v", test.cmd, err)
+		}
+		if !test.inScope && !errors.Is(err, ErrOutOfScope) {
+			t.Errorf("%v: expected it out of scope, got %v", test.cmd, err)
+		}
+	}
+}
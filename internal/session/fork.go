@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/yyovil/tandem/internal/message"
+)
+
+// Fork starts a new session with a copy of the history of a session up to and including one of its messages, the last
+// one when messageID is empty, to try another path without touching the original. a message making tool calls takes
+// the results of its calls along, a history can't end on calls without answers. the sessions of the subagent tasks
+// handed out in the copied history are copied along as tasks of the fork, so the fork doesn't depend on the source
+// staying around. the fork and the copied tasks start at zero cost and tokens, what the copied history cost was spent
+// in the source and counting it again would inflate the cost of the engagement.
+func Fork(ctx context.Context, sessions Service, messages message.Service, id, messageID string) (Session, error) {
+	source, err := sessions.Get(ctx, id)
+	if err != nil {
+		return Session{}, err
+	}
+	if source.ParentSessionID != "" {
+		return Session{}, fmt.Errorf("session %s is the session of a subagent, fork the session it belongs to", id)
+	}
+	history, err := messages.List(ctx, id)
+	if err != nil {
+		return Session{}, err
+	}
+
+	end := len(history) - 1
+	if messageID != "" {
+		end = -1
+		for i, m := range history {
+			if m.ID == messageID {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return Session{}, fmt.Errorf("message %s isn't in session %s", messageID, id)
+		}
+	}
+	if end >= 0 && len(history[end].ToolCalls()) > 0 && end+1 < len(history) && history[end+1].Role == message.Tool {
+		end++
+	}
+
+	fork, err := sessions.CreateForkSession(ctx, source.ID, fmt.Sprintf("%s (fork)", source.Title))
+	if err != nil {
+		return Session{}, err
+	}
+	copier := &historyCopier{sessions: sessions, messages: messages, created: []string{fork.ID}}
+	if err := copier.copy(ctx, source, fork, history[:end+1]); err != nil {
+		// NOTE: a fork with part of the history would pass for a whole one, it's removed again when it can't be finished.
+		if removeErr := copier.discard(context.WithoutCancel(ctx)); removeErr != nil {
+			return Session{}, fmt.Errorf("%w, and failed to remove the unfinished fork %s: %v", err, fork.ID, removeErr)
+		}
+		return Session{}, err
+	}
+	return sessions.Get(ctx, fork.ID)
+}
+
+// historyCopier copies the history of a session into another one, along with the sessions of the tasks it handed out.
+type historyCopier struct {
+	sessions Service
+	messages message.Service
+	// created are the sessions created so far, the fork first.
+	created []string
+}
+
+// copy copies the history of from into to. the task sessions are created with the ID of the tool call handing them out,
+// so the copied calls get new IDs and the copies of their sessions are created with those.
+func (c *historyCopier) copy(ctx context.Context, from, to Session, history []message.Message) error {
+	renamed := make(map[string]string)
+	for _, m := range history {
+		for _, call := range m.ToolCalls() {
+			task, err := c.sessions.Get(ctx, call.ID)
+			if errors.Is(err, sql.ErrNoRows) || (err == nil && task.ParentSessionID != from.ID) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			copied, err := c.sessions.CreateTaskSession(ctx, uuid.New().String(), to.ID, task.Title)
+			if err != nil {
+				return fmt.Errorf("failed to copy the session of task %s: %w", task.ID, err)
+			}
+			c.created = append(c.created, copied.ID)
+			taskHistory, err := c.messages.List(ctx, task.ID)
+			if err != nil {
+				return err
+			}
+			if err := c.copy(ctx, task, copied, taskHistory); err != nil {
+				return err
+			}
+			renamed[call.ID] = copied.ID
+		}
+	}
+
+	for _, m := range history {
+		m.Parts = slices.Clone(m.Parts)
+		for i, part := range m.Parts {
+			switch part := part.(type) {
+			case message.ToolCall:
+				if id, ok := renamed[part.ID]; ok {
+					part.ID = id
+					m.Parts[i] = part
+				}
+			case message.ToolResult:
+				if id, ok := renamed[part.ToolCallID]; ok {
+					part.ToolCallID = id
+					m.Parts[i] = part
+				}
+			}
+		}
+		copied, err := c.messages.Copy(ctx, m, to.ID)
+		if err != nil {
+			return fmt.Errorf("failed to copy message %s: %w", m.ID, err)
+		}
+		// NOTE: a summary replaces the history before it, the copy has to start from the copy of it.
+		if m.ID == from.SummaryMessageID {
+			to.SummaryMessageID = copied.ID
+		}
+	}
+	if to.SummaryMessageID != "" {
+		if _, err := c.sessions.Save(ctx, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discard removes the sessions created so far along with their messages.
+func (c *historyCopier) discard(ctx context.Context) error {
+	var errs []error
+	for _, id := range slices.Backward(c.created) {
+		errs = append(errs, c.messages.DeleteSessionMessages(ctx, id), c.sessions.Delete(ctx, id))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,166 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/message"
+)
+
+func TestFork(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	q := db.New(conn)
+	sessions, messages := NewService(q), message.NewService(q)
+
+	source, err := sessions.Create(ctx, "Active Directory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	finish := message.Finish{Reason: message.FinishReasonToolUse}
+	history := []message.CreateMessageParams{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "enumerate the domain"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: "call-1", Name: "terminal", Input: `{"command":"GetUserSPNs.py"}`, Finished: true}, finish}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-1", Name: "terminal", Content: "svc_sql has an SPN"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "let's try asreproasting"}, finish}},
+	}
+	var ids []string
+	for _, params := range history {
+		m, err := messages.Create(ctx, source.ID, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, m.ID)
+	}
+
+	// NOTE: forked at the tool call, the fork takes its result along.
+	fork, err := Fork(ctx, sessions, messages, source.ID, ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fork.ForkedFrom != source.ID || fork.ParentSessionID != "" || fork.MessageCount != 3 {
+		t.Fatalf("fork = %+v", fork)
+	}
+	copies, err := messages.List(ctx, fork.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 3 || copies[2].Role != message.Tool || copies[2].ToolResults()[0].Content != "svc_sql has an SPN" {
+		t.Fatalf("copies = %+v", copies)
+	}
+	for i, m := range copies {
+		if m.ID == ids[i] {
+			t.Errorf("message %d wasn't copied", i)
+		}
+	}
+	if results, err := messages.Search(ctx, "svc_sql", message.SearchOptions{}); err != nil || len(results) != 2 {
+		t.Errorf("expected the copy to be searchable, got %+v %v", results, err)
+	}
+	if source, err = sessions.Get(ctx, source.ID); err != nil || source.MessageCount != 4 {
+		t.Errorf("the source changed, %+v %v", source, err)
+	}
+
+	all, err := Fork(ctx, sessions, messages, source.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.MessageCount != 4 {
+		t.Errorf("expected the whole history, got %d messages", all.MessageCount)
+	}
+	if _, err := Fork(ctx, sessions, messages, source.ID, "missing"); err == nil {
+		t.Error("forked at a message that isn't in the session")
+	}
+	listed, err := sessions.List(ctx)
+	if err != nil || len(listed) != 3 {
+		t.Errorf("expected the forks to be listed, got %d %v", len(listed), err)
+	}
+
+	// NOTE: a fork that fails halfway through copying the history is removed again.
+	if _, err := Fork(ctx, sessions, &failingCopy{Service: messages, left: 2}, source.ID, ""); err == nil {
+		t.Fatal("expected the failed copy to be reported")
+	}
+	if listed, err := sessions.List(ctx); err != nil || len(listed) != 3 {
+		t.Errorf("expected the unfinished fork to be removed, got %d sessions %v", len(listed), err)
+	}
+
+	// NOTE: the task sessions go along with the fork, it keeps them when the source is deleted.
+	orchestrated, err := sessions.Create(ctx, "Web app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, params := range []message.CreateMessageParams{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "scan the web app"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.ToolCall{ID: "call-2", Name: "subagent", Input: `{"agent_name":"reconnoiter"}`, Finished: true}, finish}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "call-2", Name: "subagent", Content: "found /admin"}}},
+	} {
+		if _, err := messages.Create(ctx, orchestrated.ID, params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	task, err := sessions.CreateTaskSession(ctx, "call-2", orchestrated.ID, "reconnoiter agent's session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := messages.Create(ctx, task.ID, message.CreateMessageParams{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "gobuster the web app"}}}); err != nil {
+		t.Fatal(err)
+	}
+	task.Cost = 0.5
+	if _, err := sessions.Save(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	webFork, err := Fork(ctx, sessions, messages, orchestrated.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := errors.Join(messages.DeleteSessionMessages(ctx, task.ID), sessions.Delete(ctx, task.ID), messages.DeleteSessionMessages(ctx, orchestrated.ID), sessions.Delete(ctx, orchestrated.ID)); err != nil {
+		t.Fatal(err)
+	}
+	copies, err = messages.List(ctx, webFork.ID)
+	if err != nil || len(copies) != 3 {
+		t.Fatalf("copies = %+v %v", copies, err)
+	}
+	call, result := copies[1].ToolCalls()[0], copies[2].ToolResults()[0]
+	if call.ID == "call-2" || result.ToolCallID != call.ID {
+		t.Fatalf("expected the call and its result to be renamed together, got %s and %s", call.ID, result.ToolCallID)
+	}
+	copiedTask, err := sessions.Get(ctx, call.ID)
+	if err != nil || copiedTask.ParentSessionID != webFork.ID || copiedTask.Cost != 0 {
+		t.Fatalf("expected a copy of the task in the fork, got %+v %v", copiedTask, err)
+	}
+	if taskCopies, err := messages.List(ctx, copiedTask.ID); err != nil || len(taskCopies) != 1 || taskCopies[0].Content().Text != "gobuster the web app" {
+		t.Fatalf("expected the history of the task to be copied, got %+v %v", taskCopies, err)
+	}
+}
+
+// failingCopy fails to copy the messages after the first few.
+type failingCopy struct {
+	message.Service
+	left int
+}
+
+func (f *failingCopy) Copy(ctx context.Context, m message.Message, sessionID string) (message.Message, error) {
+	if f.left == 0 {
+		return message.Message{}, errors.New("disk full")
+	}
+	f.left--
+	return f.Service.Copy(ctx, m, sessionID)
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// AuditLog records the tool executions, see the audit package.
+type AuditLog interface {
+	Record(ctx context.Context, call ToolCall, response ToolResponse, runErr error, startedAt, finishedAt time.Time) error
+}
+
+// auditLog is where the audited tools record their runs, nothing is recorded without one.
+var auditLog AuditLog
+
+// UseAuditLog makes the audited tools record every run in the log.
+func UseAuditLog(l AuditLog) {
+	auditLog = l
+}
+
+type auditedTool struct {
+	BaseTool
+}
+
+// Audited wraps the tools so that every run of them ends up in the audit log.
+func Audited(tools ...BaseTool) []BaseTool {
+	audited := make([]BaseTool, len(tools))
+	for i, tool := range tools {
+		if _, ok := tool.(auditedTool); ok {
+			audited[i] = tool
+			continue
+		}
+		audited[i] = auditedTool{tool}
+	}
+	return audited
+}
+
+func (t auditedTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	startedAt := time.Now()
+	response, err := t.BaseTool.Run(ctx, call)
+	if auditLog != nil {
+		if auditErr := auditLog.Record(ctx, call, response, err, startedAt, time.Now()); auditErr != nil {
+			logging.ErrorPersist(fmt.Sprintf("failed to record %s in the audit log: %v", call.Name, auditErr))
+		}
+	}
+	return response, err
+}
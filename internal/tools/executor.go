@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/config"
+)
+
+// Executor is a backend the terminal tool runs commands on.
+type Executor interface {
+	// Exec runs the command till it exits. once ctx is done the whole process tree of the command is killed and ctx.Err() is returned.
+	Exec(ctx context.Context, req ExecRequest, stdout, stderr io.Writer) (ExecResult, error)
+	// Halt kills every command started through the executor and freezes the backend where it can.
+	Halt(ctx context.Context) error
+	// Resume undoes Halt.
+	Resume(ctx context.Context) error
+}
+
+// ExecRequest is a command to run on an executor.
+type ExecRequest struct {
+	// ID identifies the command, it's the ID of the tool call that asked for it.
+	ID      string
+	Command []string
+}
+
+// ExecResult describes how a command exited.
+type ExecResult struct {
+	ExitCode int
+	// ContainerID is only set by the container executors.
+	ContainerID string
+}
+
+// newExecutor creates the executor for a definition of swarm.json.
+func newExecutor(name string, definition config.Executor) (Executor, error) {
+	switch definition.Type {
+	case config.ExecutorDocker, config.ExecutorPodman, "":
+		return newContainerExecutor(name, definition)
+	case config.ExecutorLocal:
+		return newLocalExecutor(name, definition), nil
+	case config.ExecutorSSH:
+		return newSSHExecutor(name, definition), nil
+	default:
+		return nil, fmt.Errorf("unknown executor type %q", definition.Type)
+	}
+}
+
+// shellQuote joins the arguments into a command line that a POSIX shell splits back into the very same arguments.
+func shellQuote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// fileSafeID turns a tool call ID, which comes from the provider, into something that can go into a file name as is.
+// an ID with anything but letters, digits, - and _ in it is hashed instead.
+func fileSafeID(id string) string {
+	safe := id != "" && len(id) <= 64 && strings.IndexFunc(id, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) == -1
+	if safe {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,278 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/docker"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// containerExecutor runs the commands in the engagement containers of a docker compatible daemon, docker itself or podman.
+type containerExecutor struct {
+	name    string
+	client  *client.Client
+	manager *docker.Manager
+	pool    *docker.Pool
+
+	// NOTE: every container a command ran in, so that an emergency stop reaches the isolated ones too.
+	containers sync.Map
+
+	mu     sync.Mutex
+	paused []string
+}
+
+func newContainerExecutor(name string, definition config.Executor) (*containerExecutor, error) {
+	host := definition.Host
+	if host == "" && definition.Type == config.ExecutorPodman {
+		host = docker.PodmanHost()
+	}
+	cli, err := docker.NewClientForHost(host)
+	if err != nil {
+		return nil, err
+	}
+	manager := docker.NewManager(cli)
+	return &containerExecutor{
+		name:    name,
+		client:  cli,
+		manager: manager,
+		pool:    docker.NewPool(manager),
+	}, nil
+}
+
+func (e *containerExecutor) Exec(ctx context.Context, req ExecRequest, stdout, stderr io.Writer) (ExecResult, error) {
+	containerID, err := e.containerFor(ctx)
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("couldn't get the engagement container running: %w", err)
+	}
+	result := ExecResult{ContainerID: containerID}
+
+	// Execute the command inside the container (avoids interactive TTY read loop issues)
+	pidFile := pidFileFor(req.ID)
+	execResp, err := e.client.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          wrapTrackedCommand(pidFile, req.Command),
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attachResp, err := e.client.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to attach exec: %w", err)
+	}
+	defer attachResp.Close()
+
+	processes.add(req.ID, trackedProcess{
+		executor: e.name,
+		target:   containerID,
+		pidFile:  pidFile,
+		command:  strings.Join(req.Command, " "),
+	})
+	defer processes.remove(req.ID)
+
+	// NOTE: without a tty the exec output is multiplexed with 8 byte frame headers, so it has to be demuxed.
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, attachResp.Reader)
+		copyDone <- err
+	}()
+
+	select {
+	case err := <-copyDone:
+		if err != nil {
+			return result, fmt.Errorf("failed to read exec output: %w", err)
+		}
+	case <-ctx.Done():
+		// NOTE: cancelling the context doesn't stop the exec'd process, it has to be killed inside the container.
+		e.stopTrackedCommand(attachResp.Close, containerID, pidFile, copyDone)
+		return result, ctx.Err()
+	}
+
+	execInspect, err := e.client.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return result, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	result.ExitCode = execInspect.ExitCode
+	return result, nil
+}
+
+// containerFor picks the container a command runs in as per the configured isolation level.
+// the manager builds the image and creates the containers when they don't exist yet.
+func (e *containerExecutor) containerFor(ctx context.Context) (string, error) {
+	var (
+		containerID string
+		err         error
+	)
+
+	switch config.Get().Container.Isolation {
+	case config.IsolationAgent:
+		agentName, _ := ctx.Value(AgentNameContextKey).(string)
+		if agentName == "" {
+			return "", fmt.Errorf("agent name is required for agent isolation")
+		}
+		containerID, err = e.pool.Acquire(ctx, agentName)
+	case config.IsolationSession:
+		sessionID, _ := GetContextValues(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("session id is required for session isolation")
+		}
+		containerID, err = e.pool.Acquire(ctx, sessionID)
+	default:
+		containerID, err = e.manager.EnsureContainer(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	e.containers.Store(containerID, true)
+	return containerID, nil
+}
+
+// stopTrackedCommand kills the process tree of a running command and waits for its output stream to wind down.
+func (e *containerExecutor) stopTrackedCommand(closeStream func(), containerID, pidFile string, copyDone <-chan error) {
+	killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := e.execDetached(killCtx, containerID, killTreeCommand(pidFile)); err != nil {
+		logging.Error("failed to kill the process tree", "pidFile", pidFile, "error", err)
+	}
+	closeStream()
+	select {
+	case <-copyDone:
+	case <-killCtx.Done():
+	}
+}
+
+// execDetached runs a short housekeeping command inside the container and waits for it to finish.
+func (e *containerExecutor) execDetached(ctx context.Context, containerID string, cmd []string) error {
+	execResp, err := e.client.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd: cmd,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attachResp, err := e.client.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to attach exec: %w", err)
+	}
+	defer attachResp.Close()
+
+	// NOTE: draining the stream is how we wait for the exec to finish.
+	buf := make([]byte, 512)
+	for {
+		if _, err := attachResp.Reader.Read(buf); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// execOutput runs a short housekeeping command inside the container and returns what it wrote to stdout.
+func (e *containerExecutor) execOutput(ctx context.Context, containerID string, cmd []string) (string, error) {
+	execResp, err := e.client.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attachResp, err := e.client.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to attach exec: %w", err)
+	}
+	defer attachResp.Close()
+
+	var stdout, stderr strings.Builder
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, attachResp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read exec output: %w", err)
+	}
+	execInspect, err := e.client.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	if execInspect.ExitCode != 0 {
+		return "", fmt.Errorf("%s exited with %d: %s", cmd[0], execInspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Halt kills the agent started processes in every container the executor ran a command in and pauses those containers.
+func (e *containerExecutor) Halt(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	e.containers.Range(func(key, _ any) bool {
+		containerID := key.(string)
+		if err := e.execDetached(ctx, containerID, killAllCommand()); err != nil {
+			// NOTE: the isolated container of a finished task session is gone by now.
+			if client.IsErrNotFound(err) {
+				e.containers.Delete(containerID)
+				return true
+			}
+			errs = append(errs, fmt.Errorf("failed to kill processes in container %s: %w", containerID, err))
+		}
+		if err := e.client.ContainerPause(ctx, containerID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to pause container %s: %w", containerID, err))
+			return true
+		}
+		e.paused = append(e.paused, containerID)
+		return true
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Resume unpauses the containers paused by Halt.
+func (e *containerExecutor) Resume(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for _, containerID := range e.paused {
+		inspectRes, err := e.client.ContainerInspect(ctx, containerID)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("failed to inspect container: %w", err))
+			continue
+		}
+		if inspectRes.State.Status == container.StatePaused {
+			if err := e.client.ContainerUnpause(ctx, containerID); err != nil {
+				errs = append(errs, fmt.Errorf("failed to unpause container %s: %w", containerID, err))
+			}
+		}
+	}
+	e.paused = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// releaseSession removes the isolated container of a finished task session.
+func (e *containerExecutor) releaseSession(ctx context.Context, sessionID string) error {
+	return e.pool.Release(ctx, sessionID)
+}
+
+// releaseSessions removes the isolated containers of all sessions.
+func (e *containerExecutor) releaseSessions(ctx context.Context) error {
+	return e.pool.ReleaseAll(ctx)
+}
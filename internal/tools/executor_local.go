@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// localExecutor runs the commands straight on the operator's machine, for when tandem already runs on a kali box.
+type localExecutor struct {
+	name       string
+	workingDir string
+
+	mu      sync.Mutex
+	running map[string]*exec.Cmd
+}
+
+func newLocalExecutor(name string, definition config.Executor) *localExecutor {
+	return &localExecutor{
+		name:       name,
+		workingDir: definition.WorkingDir,
+		running:    make(map[string]*exec.Cmd),
+	}
+}
+
+func (e *localExecutor) Exec(ctx context.Context, req ExecRequest, stdout, stderr io.Writer) (ExecResult, error) {
+	cmd := exec.Command(req.Command[0], req.Command[1:]...)
+	// NOTE: the commands work in the engagement workspace unless the executor says otherwise.
+	cmd.Dir = e.workingDir
+	if cmd.Dir == "" {
+		cmd.Dir = config.BindMountPath()
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// NOTE: a daemon left behind by the command holds on to the output pipes, don't wait on it forever.
+	cmd.WaitDelay = 5 * time.Second
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return ExecResult{}, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	e.track(req.ID, cmd)
+	defer e.untrack(req.ID)
+	processes.add(req.ID, trackedProcess{
+		executor: e.name,
+		target:   "localhost",
+		command:  strings.Join(req.Command, " "),
+	})
+	defer processes.remove(req.ID)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return ExecResult{ExitCode: exitErr.ExitCode()}, nil
+		}
+		if errors.Is(err, exec.ErrWaitDelay) {
+			return ExecResult{ExitCode: cmd.ProcessState.ExitCode()}, nil
+		}
+		if err != nil {
+			return ExecResult{}, fmt.Errorf("failed to run command: %w", err)
+		}
+		return ExecResult{}, nil
+	case <-ctx.Done():
+		if err := killProcessGroup(cmd); err != nil {
+			logging.Error("failed to kill the process tree", "command", req.Command[0], "error", err)
+		}
+		<-done
+		return ExecResult{}, ctx.Err()
+	}
+}
+
+// Halt kills every running command. there is nothing to freeze on the host, the terminal refuses new commands instead.
+func (e *localExecutor) Halt(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for _, cmd := range e.running {
+		if err := killProcessGroup(cmd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+func (e *localExecutor) Resume(ctx context.Context) error {
+	return nil
+}
+
+func (e *localExecutor) track(id string, cmd *exec.Cmd) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.running[id] = cmd
+}
+
+func (e *localExecutor) untrack(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.running, id)
+}
@@ -0,0 +1,241 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshExecutor runs the commands on a remote jump box over ssh.
+type sshExecutor struct {
+	name       string
+	definition config.Executor
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func newSSHExecutor(name string, definition config.Executor) *sshExecutor {
+	return &sshExecutor{
+		name:       name,
+		definition: definition,
+	}
+}
+
+func (e *sshExecutor) Exec(ctx context.Context, req ExecRequest, stdout, stderr io.Writer) (ExecResult, error) {
+	session, err := e.newSession(ctx)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	defer session.Close()
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	// NOTE: the remote end gets a single command line, so every argument is quoted to survive the remote shell untouched.
+	pidFile := pidFileFor(req.ID)
+	commandLine := shellQuote(wrapTrackedCommand(pidFile, req.Command))
+	if e.definition.WorkingDir != "" {
+		commandLine = fmt.Sprintf("cd %s && %s", shellQuote([]string{e.definition.WorkingDir}), commandLine)
+	}
+
+	if err := session.Start(commandLine); err != nil {
+		return ExecResult{}, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	processes.add(req.ID, trackedProcess{
+		executor: e.name,
+		target:   e.definition.Host,
+		pidFile:  pidFile,
+		command:  strings.Join(req.Command, " "),
+	})
+	defer processes.remove(req.ID)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			return ExecResult{ExitCode: exitErr.ExitStatus()}, nil
+		}
+		if err != nil {
+			return ExecResult{}, fmt.Errorf("failed to run command: %w", err)
+		}
+		return ExecResult{}, nil
+	case <-ctx.Done():
+		killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := e.run(killCtx, killTreeCommand(pidFile)); err != nil {
+			logging.Error("failed to kill the process tree", "pidFile", pidFile, "error", err)
+		}
+		session.Close()
+		select {
+		case <-done:
+		case <-killCtx.Done():
+		}
+		return ExecResult{}, ctx.Err()
+	}
+}
+
+// Halt kills every agent started process on the remote host. there is nothing to freeze, the terminal refuses new commands instead.
+func (e *sshExecutor) Halt(ctx context.Context) error {
+	e.mu.Lock()
+	connected := e.client != nil
+	e.mu.Unlock()
+	if !connected {
+		return nil
+	}
+	return e.run(ctx, killAllCommand())
+}
+
+func (e *sshExecutor) Resume(ctx context.Context) error {
+	return nil
+}
+
+// run runs a short housekeeping command on the remote host and waits for it to finish.
+func (e *sshExecutor) run(ctx context.Context, cmd []string) error {
+	session, err := e.newSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	return session.Run(shellQuote(cmd))
+}
+
+// newSession opens a session on the connection to the remote host, dialing it again if it went away.
+func (e *sshExecutor) newSession(ctx context.Context) (*ssh.Session, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.client != nil {
+		session, err := e.client.NewSession()
+		if err == nil {
+			return session, nil
+		}
+		logging.Warn("ssh connection lost, reconnecting", "host", e.definition.Host, "error", err)
+		e.client.Close()
+		e.client = nil
+	}
+
+	client, err := e.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.client = client
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	return session, nil
+}
+
+func (e *sshExecutor) dial(ctx context.Context) (*ssh.Client, error) {
+	address := e.definition.Host
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "22")
+	}
+
+	auth, err := e.authMethods()
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := e.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	user := e.definition.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         15 * time.Second,
+	}
+
+	dialer := net.Dialer{Timeout: clientConfig.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", address, err)
+	}
+	logging.Info("connected to the ssh executor", "name", e.name, "host", address, "user", user)
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// authMethods uses the configured identity file and falls back to the ssh agent of the operator.
+func (e *sshExecutor) authMethods() ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if e.definition.IdentityFile != "" {
+		key, err := os.ReadFile(expandHome(e.definition.IdentityFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identity file: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse identity file, passphrase protected keys have to be loaded in the ssh agent: %w", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no ssh credentials, set identityFile for the executor or run an ssh agent")
+	}
+	return methods, nil
+}
+
+func (e *sshExecutor) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if e.definition.InsecureIgnoreHostKey {
+		logging.Warn("host key verification is disabled for the ssh executor", "name", e.name)
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	knownHostsFile := e.definition.KnownHosts
+	if knownHostsFile == "" {
+		knownHostsFile = "~/.ssh/known_hosts"
+	}
+	callback, err := knownhosts.New(expandHome(knownHostsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+)
+
+func TestShellQuote(t *testing.T) {
+	args := []string{"it's", "$HOME", "a b", `"quoted"`, ""}
+	out, err := exec.Command("sh", "-c", "printf '%s\\n' "+shellQuote(args)).Output()
+	if err != nil {
+		t.Skip("sh is not available: " + err.Error())
+	}
+	want := strings.Join(args, "\n") + "\n"
+	if string(out) != want {
+		t.Fatalf("shellQuote didn't round trip, got %q want %q", out, want)
+	}
+}
+
+func TestPidFileFor(t *testing.T) {
+	for _, id := range []string{"toolu_01A09q90qw90lq917835lq9", "call_abc-123"} {
+		if got := pidFileFor(id); got != containerPidDir+"/"+id+".pid" {
+			t.Errorf("expected the pid file of %q to keep its ID, got %s", id, got)
+		}
+	}
+	for _, id := range []string{"../../etc/passwd", "x; rm -rf /", "$(id)", "a/b", ""} {
+		got := pidFileFor(id)
+		if path.Dir(got) != containerPidDir || strings.ContainsAny(path.Base(got), "/;$() ") || path.Base(got) == ".pid" {
+			t.Errorf("expected the pid file of %q to stay in %s, got %s", id, containerPidDir, got)
+		}
+	}
+}
+
+func TestKillTreeCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the kill tree command is run by sh")
+	}
+	dir := t.TempDir()
+	marker := dir + "/injected"
+	// NOTE: a pid file that isn't there is a no-op, whatever its name says.
+	cmd := killTreeCommand(dir + "/x; touch " + marker + ".pid")
+	if err := exec.Command(cmd[0], cmd[1:]...).Run(); err != nil {
+		t.Fatalf("kill tree command failed: %v", err)
+	}
+	if _, err := os.Stat(marker + ".pid"); err == nil {
+		t.Fatal("the pid file was run as shell syntax")
+	}
+}
+
+func TestLocalExecutor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the local executor test relies on sh")
+	}
+	executor := newLocalExecutor("local", config.Executor{Type: config.ExecutorLocal, WorkingDir: t.TempDir()})
+
+	var stdout, stderr bytes.Buffer
+	result, err := executor.Exec(context.Background(), ExecRequest{
+		ID:      "test-local",
+		Command: []string{"sh", "-c", "echo out; echo err >&2; exit 3"},
+	}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	if result.ExitCode != 3 || stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Fatalf("unexpected result: exit code %d, stdout %q, stderr %q", result.ExitCode, stdout.String(), stderr.String())
+	}
+
+	// NOTE: the grandchild sleep has to die along with its parent, otherwise Exec hangs on the pipes.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err = executor.Exec(ctx, ExecRequest{
+		ID:      "test-local-timeout",
+		Command: []string{"sh", "-c", "sleep 30 & sleep 30"},
+	}, &stdout, &stderr)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("process tree wasn't killed in time, took %s", elapsed)
+	}
+}
+
+// TestSSHExecutor runs against an sshd stand-in, e.g. a linuxserver/openssh-server container.
+// it is skipped unless TANDEM_TEST_SSH_HOST is set, TANDEM_TEST_SSH_USER and TANDEM_TEST_SSH_KEY are optional.
+func TestSSHExecutor(t *testing.T) {
+	host := os.Getenv("TANDEM_TEST_SSH_HOST")
+	if host == "" {
+		t.Skip("TANDEM_TEST_SSH_HOST is not set")
+	}
+	executor := newSSHExecutor("ssh", config.Executor{
+		Type:                  config.ExecutorSSH,
+		Host:                  host,
+		User:                  os.Getenv("TANDEM_TEST_SSH_USER"),
+		IdentityFile:          os.Getenv("TANDEM_TEST_SSH_KEY"),
+		InsecureIgnoreHostKey: true,
+	})
+
+	var stdout, stderr bytes.Buffer
+	result, err := executor.Exec(context.Background(), ExecRequest{
+		ID:      "test-ssh",
+		Command: []string{"sh", "-c", "echo \"$1\"; exit 2", "sh", "it's quoted"},
+	}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	if result.ExitCode != 2 || stdout.String() != "it's quoted\n" {
+		t.Fatalf("unexpected result: exit code %d, stdout %q, stderr %q", result.ExitCode, stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,559 @@
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/docker"
+)
+
+const (
+	ReadFileToolName  = "read_file"
+	WriteFileToolName = "write_file"
+	ListFilesToolName = "list_files"
+	CopyOutToolName   = "copy_out"
+
+	defaultReadLimit = 2000
+	// maxReadSize caps how much of a file is pulled out of the container to be paged through.
+	maxReadSize     = 10 * 1024 * 1024
+	maxListEntries  = 1000
+	maxLineLength   = 2000
+	evidenceDirMode = 0o755
+)
+
+// confinePath resolves a path of a file tool against the workspace and refuses anything outside of the workspace and the loot directory.
+func confinePath(p string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if !path.IsAbs(p) {
+		p = path.Join(docker.WorkspaceDir, p)
+	}
+	p = path.Clean(p)
+	for _, root := range []string{docker.WorkspaceDir, docker.LootDir} {
+		if p == root || strings.HasPrefix(p, root+"/") {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside of the engagement workspace %s and the loot directory %s", p, docker.WorkspaceDir, docker.LootDir)
+}
+
+// fileToolContainer resolves the container of the agent calling a file tool.
+func fileToolContainer(ctx context.Context) (*containerExecutor, string, error) {
+	if terminal == nil || config.Get() == nil {
+		return nil, "", fmt.Errorf("config not loaded")
+	}
+	if terminal.halted.Load() {
+		return nil, "", fmt.Errorf("command execution is halted by the operator (emergency stop). wait for the operator to resume")
+	}
+	name, executor, err := terminal.executorFor(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't set up the %s executor: %w", name, err)
+	}
+	containerExecutor, ok := executor.(*containerExecutor)
+	if !ok {
+		return nil, "", fmt.Errorf("file tools need a docker or podman executor, %s isn't one. use the terminal instead", name)
+	}
+	containerID, err := containerExecutor.containerFor(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't get the engagement container running: %w", err)
+	}
+	return containerExecutor, containerID, nil
+}
+
+// resolveConfined resolves every symlink along a confined path inside the container and confines where it leads.
+// checking the last component alone isn't enough, a symlinked directory on the way like loot/link -> /etc leads out of
+// the workspace just as well. the parts of the path that don't exist yet are taken as they are.
+func resolveConfined(ctx context.Context, executor *containerExecutor, containerID, p string) (string, error) {
+	output, err := containerRealpath(ctx, executor, containerID, p)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", p, err)
+	}
+	resolved := strings.TrimSuffix(output, "\n")
+	if _, err := confinePath(resolved); err != nil {
+		return "", fmt.Errorf("%s leads to %s through a symlink: %w", p, resolved, err)
+	}
+	return resolved, nil
+}
+
+// containerRealpath resolves every symlink along a path inside the container, the parts that don't exist are kept.
+// NOTE: it's a variable so that the tests can resolve the paths of a directory standing in for the container.
+var containerRealpath = func(ctx context.Context, executor *containerExecutor, containerID, p string) (string, error) {
+	return executor.execOutput(ctx, containerID, []string{"realpath", "-m", "--", p})
+}
+
+// statConfined resolves a confined path like resolveConfined and stats where it leads.
+func statConfined(ctx context.Context, executor *containerExecutor, containerID, p string) (string, container.PathStat, error) {
+	resolved, err := resolveConfined(ctx, executor, containerID, p)
+	if err != nil {
+		return "", container.PathStat{}, err
+	}
+	stat, err := executor.client.ContainerStatPath(ctx, containerID, resolved)
+	if err != nil {
+		return "", stat, fmt.Errorf("failed to stat %s: %w", p, err)
+	}
+	return resolved, stat, nil
+}
+
+type ReadFileArgs struct {
+	Path   string `json:"path"`
+	Offset int    `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type ReadFileTool struct{}
+
+func NewReadFileTool() BaseTool {
+	return &ReadFileTool{}
+}
+
+func (t *ReadFileTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReadFileToolName,
+		Description: fmt.Sprintf("Read a text file from the kali container. paths are relative to the workspace %s, only the workspace and %s are accessible. long files are paged by lines.", docker.WorkspaceDir, docker.LootDir),
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "path of the file to read",
+			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "line number to start reading from, 0 based",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("number of lines to read. defaults to %d.", defaultReadLimit),
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (t *ReadFileTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ReadFileArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse read_file arguments: " + err.Error()), nil
+	}
+	p, err := confinePath(args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	executor, containerID, err := fileToolContainer(ctx)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	p, stat, err := statConfined(ctx, executor, containerID, p)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if stat.Mode.IsDir() {
+		return NewTextErrorResponse(fmt.Sprintf("%s is a directory, use %s instead", p, ListFilesToolName)), nil
+	}
+	if stat.Size > maxReadSize {
+		return NewTextErrorResponse(fmt.Sprintf("%s is %d bytes, too large to read. use %s to pull it to the evidence directory or the terminal to grep it", p, stat.Size, CopyOutToolName)), nil
+	}
+
+	content, err := readContainerFile(ctx, executor, containerID, p)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if bytes.IndexByte(content, 0) != -1 || !utf8.Valid(content) {
+		return NewTextErrorResponse(fmt.Sprintf("%s is a binary file of %d bytes. use %s to pull it to the evidence directory", p, len(content), CopyOutToolName)), nil
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultReadLimit
+	}
+	lines := strings.Split(string(content), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if args.Offset >= len(lines) && len(lines) > 0 {
+		return NewTextErrorResponse(fmt.Sprintf("offset %d is past the end of %s, it has %d lines", args.Offset, p, len(lines))), nil
+	}
+	offset := max(args.Offset, 0)
+	end := min(offset+limit, len(lines))
+
+	var sb strings.Builder
+	for i := offset; i < end; i++ {
+		line := lines[i]
+		if len(line) > maxLineLength {
+			line = line[:maxLineLength] + "..."
+		}
+		fmt.Fprintf(&sb, "%6d\t%s\n", i+1, line)
+	}
+	if end < len(lines) {
+		fmt.Fprintf(&sb, "\n(showing lines %d-%d of %d, use offset %d to read on)", offset+1, end, len(lines), end)
+	}
+	if len(lines) == 0 {
+		sb.WriteString("(empty file)")
+	}
+	return NewTextResponse(sb.String()), nil
+}
+
+// readContainerFile pulls a single file out of the container.
+func readContainerFile(ctx context.Context, executor *containerExecutor, containerID, p string) ([]byte, error) {
+	reader, _, err := executor.client.CopyFromContainer(ctx, containerID, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy %s from the container: %w", p, err)
+	}
+	defer reader.Close()
+
+	tr := tar.NewReader(reader)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("%s is not a regular file", p)
+	}
+	content, err := io.ReadAll(io.LimitReader(tr, maxReadSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return content, nil
+}
+
+type WriteFileArgs struct {
+	Path       string `json:"path"`
+	Content    string `json:"content"`
+	Encoding   string `json:"encoding,omitempty"`
+	Executable bool   `json:"executable,omitempty"`
+}
+
+type WriteFileTool struct{}
+
+func NewWriteFileTool() BaseTool {
+	return &WriteFileTool{}
+}
+
+func (t *WriteFileTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        WriteFileToolName,
+		Description: fmt.Sprintf("Write a file into the kali container, e.g. a payload, a script or a wordlist. the file is overwritten if it exists and missing directories are created. paths are relative to the workspace %s, only the workspace and %s are writable.", docker.WorkspaceDir, docker.LootDir),
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "path of the file to write",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "content of the file",
+			},
+			"encoding": map[string]any{
+				"type":        "string",
+				"description": "encoding of the content, base64 for binary data. defaults to text.",
+				"enum":        []string{"text", "base64"},
+			},
+			"executable": map[string]any{
+				"type":        "boolean",
+				"description": "make the file executable",
+			},
+		},
+		Required: []string{"path", "content"},
+	}
+}
+
+func (t *WriteFileTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args WriteFileArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse write_file arguments: " + err.Error()), nil
+	}
+	p, err := confinePath(args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if p == docker.WorkspaceDir || p == docker.LootDir {
+		return NewTextErrorResponse(fmt.Sprintf("%s is a directory", p)), nil
+	}
+
+	content := []byte(args.Content)
+	if args.Encoding == "base64" {
+		content, err = base64.StdEncoding.DecodeString(args.Content)
+		if err != nil {
+			return NewTextErrorResponse("content isn't valid base64: " + err.Error()), nil
+		}
+	}
+
+	executor, containerID, err := fileToolContainer(ctx)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	// NOTE: the directories are created where the path leads, mkdir follows the symlinks on the way.
+	if p, err = resolveConfined(ctx, executor, containerID, p); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	dir := path.Dir(p)
+	if _, err := executor.execOutput(ctx, containerID, []string{"mkdir", "-p", "--", dir}); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to create %s: %s", dir, err)), nil
+	}
+	if dir, _, err = statConfined(ctx, executor, containerID, dir); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	var mode int64 = 0o644
+	if args.Executable {
+		mode = 0o755
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    path.Base(p),
+		Mode:    mode,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return NewTextErrorResponse("failed to pack the file: " + err.Error()), nil
+	}
+	if _, err := tw.Write(content); err != nil {
+		return NewTextErrorResponse("failed to pack the file: " + err.Error()), nil
+	}
+	if err := tw.Close(); err != nil {
+		return NewTextErrorResponse("failed to pack the file: " + err.Error()), nil
+	}
+
+	if err := executor.client.CopyToContainer(ctx, containerID, dir, &buf, container.CopyToContainerOptions{}); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to copy %s into the container: %s", p, err)), nil
+	}
+	return NewTextResponse(fmt.Sprintf("wrote %d bytes to %s", len(content), p)), nil
+}
+
+type ListFilesArgs struct {
+	Path      string `json:"path,omitempty"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+type ListFilesTool struct{}
+
+func NewListFilesTool() BaseTool {
+	return &ListFilesTool{}
+}
+
+func (t *ListFilesTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ListFilesToolName,
+		Description: fmt.Sprintf("List a directory in the kali container. paths are relative to the workspace %s, only the workspace and %s are accessible.", docker.WorkspaceDir, docker.LootDir),
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "directory to list, defaults to the workspace",
+			},
+			"recursive": map[string]any{
+				"type":        "boolean",
+				"description": "list the subdirectories as well",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *ListFilesTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ListFilesArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse list_files arguments: " + err.Error()), nil
+	}
+	if args.Path == "" {
+		args.Path = docker.WorkspaceDir
+	}
+	p, err := confinePath(args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	executor, containerID, err := fileToolContainer(ctx)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	p, stat, err := statConfined(ctx, executor, containerID, p)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if !stat.Mode.IsDir() {
+		return NewTextResponse(fmt.Sprintf("%s\t%d bytes\t%s", p, stat.Size, stat.Mode)), nil
+	}
+
+	// NOTE: the copy API hands out the whole tree as a tarball, only the headers are read and the stream is dropped once we have enough.
+	reader, _, err := executor.client.CopyFromContainer(ctx, containerID, p+"/.")
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to list %s: %s", p, err)), nil
+	}
+	defer reader.Close()
+
+	var sb strings.Builder
+	entries := 0
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to list %s: %s", p, err)), nil
+		}
+		name := strings.TrimPrefix(path.Clean(header.Name), "./")
+		if name == "." || name == "" {
+			continue
+		}
+		if !args.Recursive && strings.Contains(name, "/") {
+			continue
+		}
+		if entries == maxListEntries {
+			fmt.Fprintf(&sb, "(listing stopped at %d entries)\n", maxListEntries)
+			break
+		}
+		entries++
+		switch header.Typeflag {
+		case tar.TypeDir:
+			fmt.Fprintf(&sb, "%s/\n", name)
+		case tar.TypeSymlink:
+			fmt.Fprintf(&sb, "%s -> %s\n", name, header.Linkname)
+		default:
+			fmt.Fprintf(&sb, "%s\t%d bytes\t%s\n", name, header.Size, os.FileMode(header.Mode).Perm())
+		}
+	}
+	if entries == 0 {
+		return NewTextResponse(fmt.Sprintf("%s is empty", p)), nil
+	}
+	return NewTextResponse(sb.String()), nil
+}
+
+type CopyOutArgs struct {
+	Path string `json:"path"`
+}
+
+// CopyOutResponseMetadata lists the artifacts pulled to the evidence directory along with their checksums.
+type CopyOutResponseMetadata struct {
+	Source      string            `json:"source"`
+	Destination string            `json:"destination"`
+	Files       map[string]string `json:"files"` // host path -> sha256
+}
+
+type CopyOutTool struct{}
+
+func NewCopyOutTool() BaseTool {
+	return &CopyOutTool{}
+}
+
+func (t *CopyOutTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        CopyOutToolName,
+		Description: "Copy a file or a directory out of the kali container into the evidence directory on the operator's host, e.g. scan results, dumps or loot. the sha256 of every copied file is reported.",
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": fmt.Sprintf("file or directory to copy, relative to the workspace %s. only the workspace and %s are accessible.", docker.WorkspaceDir, docker.LootDir),
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (t *CopyOutTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args CopyOutArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse copy_out arguments: " + err.Error()), nil
+	}
+	p, err := confinePath(args.Path)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	executor, containerID, err := fileToolContainer(ctx)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if p, _, err = statConfined(ctx, executor, containerID, p); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	reader, _, err := executor.client.CopyFromContainer(ctx, containerID, p)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to copy %s from the container: %s", p, err)), nil
+	}
+	defer reader.Close()
+
+	// NOTE: every copy lands in a directory of its own so that nothing collected earlier gets overwritten.
+	destination := filepath.Join(config.EvidenceDir(), time.Now().Format("20060102-150405")+"-"+fileSafeID(call.ID))
+	if err := os.MkdirAll(destination, evidenceDirMode); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to create the evidence directory: %s", err)), nil
+	}
+
+	files, err := extractTar(reader, destination)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to copy %s out: %s", p, err)), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "copied %s to %s\n", p, destination)
+	for _, hostPath := range slices.Sorted(maps.Keys(files)) {
+		rel, _ := filepath.Rel(destination, hostPath)
+		fmt.Fprintf(&sb, "%s  %s\n", files[hostPath], rel)
+	}
+	return WithResponseMetadata(NewTextResponse(sb.String()), CopyOutResponseMetadata{
+		Source:      p,
+		Destination: destination,
+		Files:       files,
+	}), nil
+}
+
+// extractTar unpacks the regular files and the directories of a tarball under destination. links are skipped so that nothing can point out of it.
+func extractTar(r io.Reader, destination string) (map[string]string, error) {
+	files := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+
+		target := filepath.Join(destination, filepath.FromSlash(path.Clean("/"+header.Name)))
+		if !strings.HasPrefix(target, destination+string(os.PathSeparator)) {
+			return files, fmt.Errorf("refusing to extract %s outside of the evidence directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, evidenceDirMode); err != nil {
+				return files, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), evidenceDirMode); err != nil {
+				return files, err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return files, err
+			}
+			hash := sha256.New()
+			_, err = io.Copy(io.MultiWriter(f, hash), tr)
+			f.Close()
+			if err != nil {
+				return files, err
+			}
+			files[target] = hex.EncodeToString(hash.Sum(nil))
+		}
+	}
+}
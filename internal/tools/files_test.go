@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/yyovil/tandem/internal/docker"
+)
+
+func TestConfinePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"notes.txt", docker.WorkspaceDir + "/notes.txt"},
+		{"./scans/../notes.txt", docker.WorkspaceDir + "/notes.txt"},
+		{docker.WorkspaceDir, docker.WorkspaceDir},
+		{docker.LootDir + "/hashes.txt", docker.LootDir + "/hashes.txt"},
+		{docker.WorkspaceDir + "/../../loot/hashes.txt", docker.LootDir + "/hashes.txt"},
+		{"../etc/passwd", ""},
+		{"../../../../etc/shadow", ""},
+		{"/etc/passwd", ""},
+		{docker.LootDir + "-other/file", ""},
+		{docker.WorkspaceDir + "/../root/.ssh/id_rsa", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		got, err := confinePath(test.path)
+		if test.want == "" && err == nil {
+			t.Errorf("%q: expected it to be refused, got %s", test.path, got)
+		}
+		if test.want != "" && (err != nil || got != test.want) {
+			t.Errorf("%q: expected %s, got %s, %v", test.path, test.want, got, err)
+		}
+	}
+}
+
+func TestResolveConfined(t *testing.T) {
+	if _, err := exec.LookPath("realpath"); err != nil {
+		t.Skip("realpath is not available")
+	}
+	// NOTE: a directory stands in for the root of the container, its paths are mapped back and forth.
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := func(p string) string { return filepath.Join(root, filepath.FromSlash(p)) }
+	for _, dir := range []string{docker.WorkspaceDir + "/scans", docker.LootDir, "/etc"} {
+		if err := os.MkdirAll(host(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(host(docker.WorkspaceDir+"/notes.txt"), []byte("10.10.10.5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		docker.LootDir + "/etc":           host("/etc"),
+		docker.WorkspaceDir + "/up":       "../..",
+		docker.WorkspaceDir + "/passwd":   host("/etc/passwd"),
+		docker.WorkspaceDir + "/loot":     host(docker.LootDir),
+		docker.WorkspaceDir + "/scans/me": "../notes.txt",
+	} {
+		if err := os.Symlink(target, host(link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	realpath := containerRealpath
+	t.Cleanup(func() { containerRealpath = realpath })
+	containerRealpath = func(ctx context.Context, executor *containerExecutor, containerID, p string) (string, error) {
+		out, err := exec.CommandContext(ctx, "realpath", "-m", "--", host(p)).Output()
+		if err != nil {
+			return "", err
+		}
+		resolved := strings.TrimSuffix(string(out), "\n")
+		if resolved == root {
+			return "/", nil
+		}
+		if rel, ok := strings.CutPrefix(resolved, root+"/"); ok {
+			return "/" + rel, nil
+		}
+		return resolved, nil
+	}
+
+	// NOTE: the stats are served the way the daemon does, from the directory standing in for the container.
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, err := os.Lstat(host(r.URL.Query().Get("path")))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		stat, _ := json.Marshal(container.PathStat{Name: info.Name(), Size: info.Size(), Mode: info.Mode(), Mtime: info.ModTime()})
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+	}))
+	defer daemon.Close()
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(daemon.URL, "http://")), client.WithVersion("1.43"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := &containerExecutor{client: cli}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"notes.txt", docker.WorkspaceDir + "/notes.txt"},
+		{"scans/me", docker.WorkspaceDir + "/notes.txt"},
+		{"loot/hashes.txt", docker.LootDir + "/hashes.txt"},
+		{"scans/new/report.md", docker.WorkspaceDir + "/scans/new/report.md"},
+		// the last component leads out of the workspace
+		{"passwd", ""},
+		// a directory on the way leads out, the rest of the path doesn't even exist
+		{docker.LootDir + "/etc/passwd", ""},
+		{docker.LootDir + "/etc/cron.d/backdoor", ""},
+		{"up/etc/passwd", ""},
+		{"up", ""},
+	}
+	for _, test := range tests {
+		p, err := confinePath(test.path)
+		if err != nil {
+			t.Fatalf("%q: %v", test.path, err)
+		}
+		got, err := resolveConfined(context.Background(), executor, "kali", p)
+		if test.want == "" && err == nil {
+			t.Errorf("%q: expected it to be refused, got %s", test.path, got)
+		}
+		if test.want != "" && (err != nil || got != test.want) {
+			t.Errorf("%q: expected %s, got %s, %v", test.path, test.want, got, err)
+		}
+	}
+
+	p, stat, err := statConfined(context.Background(), executor, "kali", docker.WorkspaceDir+"/scans/me")
+	if err != nil || p != docker.WorkspaceDir+"/notes.txt" || stat.Size != 11 || !stat.Mode.IsRegular() {
+		t.Fatalf("expected the stat of the file the symlink leads to, got %s %+v, %v", p, stat, err)
+	}
+	if _, _, err := statConfined(context.Background(), executor, "kali", docker.LootDir+"/etc/passwd"); err == nil {
+		t.Fatal("expected a path leading out through a symlinked directory to be refused before it's stat")
+	}
+}
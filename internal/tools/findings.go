@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/cvss"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/inventory"
+)
+
+const (
+	RecordFindingToolName = "record_finding"
+	ListFindingsToolName  = "list_findings"
+)
+
+type RecordFindingArgs struct {
+	Title       string   `json:"title"`
+	CVSSVector  string   `json:"cvss_vector"`
+	Host        string   `json:"host,omitempty"`
+	Port        int64    `json:"port,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Evidence    string   `json:"evidence,omitempty"`
+	Remediation string   `json:"remediation,omitempty"`
+	References  []string `json:"references,omitempty"`
+}
+
+type ListFindingsArgs struct {
+	MinSeverity string `json:"min_severity,omitempty"`
+}
+
+type FindingResponseMetadata struct {
+	ID         string           `json:"id"`
+	Title      string           `json:"title"`
+	Severity   finding.Severity `json:"severity"`
+	CVSSVector string           `json:"cvss_vector,omitempty"`
+	CVSSScore  float64          `json:"cvss_score"`
+}
+
+type recordFindingTool struct {
+	findings  finding.Service
+	inventory inventory.Service
+}
+
+type listFindingsTool struct {
+	findings  finding.Service
+	inventory inventory.Service
+}
+
+func NewRecordFindingTool(findings finding.Service, inventory inventory.Service) BaseTool {
+	return &recordFindingTool{findings: findings, inventory: inventory}
+}
+
+func NewListFindingsTool(findings finding.Service, inventory inventory.Service) BaseTool {
+	return &listFindingsTool{findings: findings, inventory: inventory}
+}
+
+func (t *recordFindingTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RecordFindingToolName,
+		Description: "Record a vulnerability or an observation worth reporting in the findings of the engagement. the severity follows from the CVSS vector, score the finding as it was demonstrated, not as it might be. recording a finding with the same title at the same place again updates it.",
+		Parameters: map[string]any{
+			"title": map[string]any{
+				"type":        "string",
+				"description": "short name of the issue, e.g. SQL injection in the login form",
+			},
+			"cvss_vector": map[string]any{
+				"type":        "string",
+				"description": "CVSS v3.1 or v4.0 vector of the finding, e.g. CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H or CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N. an observation without impact scores the impact metrics N",
+			},
+			"host": map[string]any{
+				"type":        "string",
+				"description": "IP address of the affected host",
+			},
+			"port": map[string]any{
+				"type":        "integer",
+				"description": "affected port",
+			},
+			"protocol": map[string]any{
+				"type":        "string",
+				"description": "transport protocol of the port, tcp by default",
+				"enum":        []string{"tcp", "udp"},
+			},
+			"location": map[string]any{
+				"type":        "string",
+				"description": "where exactly, e.g. the URL and parameter, the file or the share",
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "what the issue is and what an attacker gains from it, in markdown",
+			},
+			"evidence": map[string]any{
+				"type":        "string",
+				"description": "the request, command and output that demonstrate it",
+			},
+			"remediation": map[string]any{
+				"type":        "string",
+				"description": "how to fix it, in markdown",
+			},
+			"references": map[string]any{
+				"type":        "array",
+				"description": "CVE IDs, CWE IDs and links",
+				"items":       map[string]any{"type": "string"},
+			},
+		},
+		Required: []string{"title", "cvss_vector"},
+	}
+}
+
+func (t *recordFindingTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args RecordFindingArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse record_finding arguments: " + err.Error()), nil
+	}
+	if strings.TrimSpace(args.Title) == "" {
+		return NewTextErrorResponse("title is required"), nil
+	}
+	if args.CVSSVector == "" {
+		return NewTextErrorResponse("cvss_vector is required, score the finding with a CVSS v3.1 or v4.0 vector"), nil
+	}
+	if err := cvss.Validate(args.CVSSVector); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid cvss_vector: %s", err)), nil
+	}
+
+	agentName, _ := ctx.Value(AgentNameContextKey).(string)
+	f := finding.Finding{
+		Port:        args.Port,
+		Protocol:    args.Protocol,
+		Location:    args.Location,
+		Title:       strings.TrimSpace(args.Title),
+		Description: args.Description,
+		Evidence:    MaskSecrets(args.Evidence),
+		Remediation: args.Remediation,
+		References:  args.References,
+		Source:      agentName,
+		CVSSVector:  args.CVSSVector,
+	}
+	if f.Port > 0 && f.Protocol == "" {
+		f.Protocol = "tcp"
+	}
+	if host := strings.TrimSpace(args.Host); host != "" {
+		recorded, err := t.inventory.RecordHost(ctx, inventory.Host{Address: host, Source: agentName})
+		if err != nil {
+			return NewTextErrorResponse("failed to record the host: " + err.Error()), nil
+		}
+		f.HostID = recorded.ID
+	}
+
+	recorded, err := t.findings.Record(ctx, f)
+	if err != nil {
+		return NewTextErrorResponse("failed to record the finding: " + err.Error()), nil
+	}
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("recorded %q as %s, CVSS %.1f (%s)", recorded.Title, recorded.Severity, recorded.CVSSScore, recorded.CVSSVector)),
+		findingMetadata(recorded),
+	), nil
+}
+
+func (t *listFindingsTool) Info() ToolInfo {
+	severities := make([]string, len(finding.Severities))
+	for i, severity := range finding.Severities {
+		severities[i] = string(severity)
+	}
+	return ToolInfo{
+		Name:        ListFindingsToolName,
+		Description: "List the findings of the engagement from the most to the least severe, with their CVSS scores and where they are. check it before recording a finding to avoid duplicates.",
+		Parameters: map[string]any{
+			"min_severity": map[string]any{
+				"type":        "string",
+				"description": "leave out the findings below this severity",
+				"enum":        severities,
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *listFindingsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ListFindingsArgs
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+			return NewTextErrorResponse("failed to parse list_findings arguments: " + err.Error()), nil
+		}
+	}
+	minimum := finding.Severity(args.MinSeverity)
+	if minimum != "" && minimum.Rank() < 0 {
+		return NewTextErrorResponse(fmt.Sprintf("unknown severity %q", args.MinSeverity)), nil
+	}
+
+	findings, err := t.findings.List(ctx)
+	if err != nil {
+		return NewTextErrorResponse("failed to list the findings: " + err.Error()), nil
+	}
+	hosts, err := t.inventory.ListHosts(ctx)
+	if err != nil {
+		return NewTextErrorResponse("failed to list the hosts: " + err.Error()), nil
+	}
+	addresses := make(map[string]string, len(hosts))
+	for _, host := range hosts {
+		addresses[host.ID] = host.Address
+	}
+
+	slices.SortStableFunc(findings, finding.Compare)
+	var sb strings.Builder
+	var metadata []FindingResponseMetadata
+	for _, f := range findings {
+		if f.Severity.Rank() < minimum.Rank() {
+			continue
+		}
+		metadata = append(metadata, findingMetadata(f))
+		fmt.Fprintf(&sb, "- [%s", f.Severity)
+		if f.CVSSVector != "" {
+			fmt.Fprintf(&sb, " %.1f", f.CVSSScore)
+		}
+		fmt.Fprintf(&sb, "] %s", f.Title)
+		where := addresses[f.HostID]
+		if f.Port > 0 {
+			where = fmt.Sprintf("%s:%d/%s", where, f.Port, f.Protocol)
+		}
+		if f.Location != "" {
+			where = strings.TrimSpace(where + " " + f.Location)
+		}
+		if where != "" {
+			fmt.Fprintf(&sb, " at %s", where)
+		}
+		fmt.Fprintf(&sb, " (%s, by %s)\n", f.ID, f.Source)
+	}
+	if len(metadata) == 0 {
+		return NewTextResponse("no findings recorded yet"), nil
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimRight(sb.String(), "\n")), metadata), nil
+}
+
+func findingMetadata(f finding.Finding) FindingResponseMetadata {
+	return FindingResponseMetadata{
+		ID:         f.ID,
+		Title:      f.Title,
+		Severity:   f.Severity,
+		CVSSVector: f.CVSSVector,
+		CVSSScore:  f.CVSSScore,
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yyovil/tandem/internal/message"
+)
+
+const SearchHistoryToolName = "search_history"
+
+// maxHistoryMessageLength caps the message search_history reads back, a tool output can be megabytes long.
+const maxHistoryMessageLength = 20000
+
+type SearchHistoryArgs struct {
+	Query     string `json:"query"`
+	Limit     int    `json:"limit,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+}
+
+type SearchHistoryResponseMetadata struct {
+	Query     string `json:"query,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	Matches   int    `json:"matches"`
+}
+
+type searchHistoryTool struct {
+	messages message.Service
+}
+
+func NewSearchHistoryTool(messages message.Service) BaseTool {
+	return &searchHistoryTool{messages: messages}
+}
+
+func (t *searchHistoryTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        SearchHistoryToolName,
+		Description: "Search the messages, tool calls and tool outputs of every session of the engagement, including the ones of other agents and earlier sessions, best match first. look up what was already found before running a scan again, e.g. the open ports of a host, a banner, a credential or an error. every word of the query has to match, \"quoted phrases\" match as a whole, a word ending in * matches as a prefix and OR matches either side of it. each match shows a snippet with the matches between [ and ], read the whole message of a match by passing its message_id.",
+		Parameters: map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "what to search for, e.g. 10.10.10.5 \"anonymous login\" or smb*",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "number of matches to return, 10 by default and 50 at most",
+			},
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "ID of a message from an earlier search to read in full instead of searching",
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+func (t *searchHistoryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args SearchHistoryArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse search_history arguments: " + err.Error()), nil
+	}
+	if args.MessageID != "" {
+		return t.read(ctx, args.MessageID)
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return NewTextErrorResponse("query is required"), nil
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	limit = min(limit, 50)
+
+	// NOTE: the earlier searches match themselves, they are left out and made up for.
+	results, err := t.messages.Search(ctx, args.Query, message.SearchOptions{Limit: limit * 2, Tokens: 24})
+	if err != nil {
+		return NewTextErrorResponse("failed to search: " + err.Error()), nil
+	}
+	var b strings.Builder
+	matches := 0
+	for _, r := range results {
+		if r.Tool == SearchHistoryToolName || matches == limit {
+			continue
+		}
+		matches++
+		source := string(r.Role)
+		switch r.Kind {
+		case "tool_call":
+			source = fmt.Sprintf("call of %s", r.Tool)
+		case "tool_result":
+			source = fmt.Sprintf("output of %s", r.Tool)
+		}
+		snippet := strings.NewReplacer(message.MatchStart, "[", message.MatchEnd, "]").Replace(MaskSecrets(r.Snippet))
+		fmt.Fprintf(&b, "%d. %s in session %q at %s (message_id: %s)\n   %s\n",
+			matches, source, r.SessionTitle, time.Unix(r.CreatedAt, 0).Format(time.DateTime), r.MessageID,
+			strings.Join(strings.Fields(snippet), " "))
+	}
+	if matches == 0 {
+		b.WriteString("no matches")
+	}
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimSpace(b.String())),
+		SearchHistoryResponseMetadata{Query: args.Query, Matches: matches},
+	), nil
+}
+
+// read renders a whole message, its text, the tool calls it made and the tool outputs it carries.
+func (t *searchHistoryTool) read(ctx context.Context, id string) (ToolResponse, error) {
+	msg, err := t.messages.Get(ctx, id)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("message %s not found", id)), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s message at %s\n", msg.Role, time.Unix(msg.CreatedAt, 0).Format(time.DateTime))
+	for _, part := range msg.Parts {
+		switch p := part.(type) {
+		case message.TextContent:
+			fmt.Fprintf(&b, "\n%s\n", MaskSecrets(p.Text))
+		case message.ToolCall:
+			fmt.Fprintf(&b, "\ncall of %s: %s\n", p.Name, MaskInput(p.Name, p.Input))
+		case message.ToolResult:
+			fmt.Fprintf(&b, "\noutput of %s:\n%s\n", p.Name, MaskSecrets(p.Content))
+		}
+	}
+	content := b.String()
+	if len(content) > maxHistoryMessageLength {
+		content = content[:maxHistoryMessageLength] + fmt.Sprintf("\n\n[%d more characters left out]", len(content)-maxHistoryMessageLength)
+	}
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimSpace(content)),
+		SearchHistoryResponseMetadata{MessageID: id, Matches: 1},
+	), nil
+}
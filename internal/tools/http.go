@@ -0,0 +1,519 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/scope"
+)
+
+const (
+	HTTPRequestToolName = "http_request"
+
+	defaultHTTPTimeout = 30 * time.Second
+	maxHTTPRedirects   = 10
+	// maxHTTPBodyShown caps the part of the response body handed to the model, the evidence keeps up to maxHTTPBodySize.
+	maxHTTPBodyShown = 8 * 1024
+	maxHTTPBodySize  = 10 * 1024 * 1024
+)
+
+type HTTPRequestArgs struct {
+	Method          string            `json:"method,omitempty"`
+	URL             string            `json:"url,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	Raw             string            `json:"raw,omitempty"`
+	HTTPVersion     string            `json:"http_version,omitempty"`
+	FollowRedirects bool              `json:"follow_redirects,omitempty"`
+	UseProxy        bool              `json:"use_proxy,omitempty"`
+	Timeout         int               `json:"timeout,omitempty"` // seconds
+}
+
+// HTTPTiming breaks down where the time of a request went, in milliseconds.
+type HTTPTiming struct {
+	DNS       int64 `json:"dns_ms"`
+	Connect   int64 `json:"connect_ms"`
+	TLS       int64 `json:"tls_ms"`
+	FirstByte int64 `json:"first_byte_ms"`
+	Total     int64 `json:"total_ms"`
+}
+
+type HTTPRedirect struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+type HTTPResponseMetadata struct {
+	Method    string         `json:"method"`
+	URL       string         `json:"url"`
+	Proto     string         `json:"proto"`
+	Status    int            `json:"status"`
+	Headers   http.Header    `json:"headers"`
+	BodySize  int64          `json:"body_size"`
+	Truncated bool           `json:"truncated"`
+	Redirects []HTTPRedirect `json:"redirects,omitempty"`
+	Proxy     string         `json:"proxy,omitempty"`
+	Timing    HTTPTiming     `json:"timing"`
+	Evidence  string         `json:"evidence,omitempty"`
+}
+
+type httpRequestTool struct{}
+
+func NewHTTPRequestTool() BaseTool {
+	return &httpRequestTool{}
+}
+
+func (t *httpRequestTool) Info() ToolInfo {
+	return ToolInfo{
+		Name: HTTPRequestToolName,
+		Description: `Send an HTTP request to a web target and get back the status, headers, timing and the start of the body. prefer it over curl in the terminal for web testing.
+the request is sent from the operator's machine, not from the container, and only to hosts in the engagement's scope. the full exchange is saved as evidence.
+either give method, url, headers and body, or paste a raw request in raw, e.g. "POST /login HTTP/1.1\nHost: target\nContent-Type: application/x-www-form-urlencoded\n\nuser=admin&pass=admin", along with the url of the target to send it to.`,
+		Parameters: map[string]any{
+			"method": map[string]any{
+				"type":        "string",
+				"description": "HTTP method, any verb goes. defaults to GET.",
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "URL to request. with raw, only its scheme and host are used unless the raw request line carries an absolute URL.",
+			},
+			"headers": map[string]any{
+				"type":        "object",
+				"description": "request headers, a Host header overrides the host sent to the server",
+				"additionalProperties": map[string]any{
+					"type": "string",
+				},
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "request body",
+			},
+			"raw": map[string]any{
+				"type":        "string",
+				"description": "a raw HTTP request: request line, headers, a blank line and the body. Content-Length is recomputed.",
+			},
+			"http_version": map[string]any{
+				"type":        "string",
+				"enum":        []string{"1.1", "2"},
+				"description": "HTTP version to speak, 2 uses h2 over TLS and prior knowledge h2c over plain HTTP. defaults to 1.1.",
+			},
+			"follow_redirects": map[string]any{
+				"type":        "boolean",
+				"description": fmt.Sprintf("follow up to %d redirects as long as they stay in scope. off by default so that the redirect itself can be inspected.", maxHTTPRedirects),
+			},
+			"use_proxy": map[string]any{
+				"type":        "boolean",
+				"description": "send the request through the proxy configured for the engagement, e.g. burp",
+			},
+			"timeout": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("seconds to wait for the response. defaults to %d.", int(defaultHTTPTimeout.Seconds())),
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *httpRequestTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args HTTPRequestArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse http_request arguments: " + err.Error()), nil
+	}
+	if IsHalted() {
+		return NewTextErrorResponse("requests are halted by the operator (emergency stop). wait for the operator to resume."), nil
+	}
+	cfg := config.Get()
+	if cfg == nil {
+		return NewTextErrorResponse("config not loaded"), nil
+	}
+
+	req, body, err := buildHTTPRequest(args)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	engagementScope, err := scope.FromConfig()
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid scope: %s", err)), nil
+	}
+	if err := engagementScope.Check(ctx, req.URL.Host); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("refusing to send the request: %s", err)), nil
+	}
+
+	var proxy *url.URL
+	if args.UseProxy {
+		if cfg.HTTP.Proxy == "" {
+			return NewTextErrorResponse("no proxy is configured, set http.proxy in swarm.json"), nil
+		}
+		if proxy, err = url.Parse(cfg.HTTP.Proxy); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("invalid proxy: %s", err)), nil
+		}
+	}
+
+	timeout := defaultHTTPTimeout
+	if args.Timeout > 0 {
+		timeout = time.Duration(args.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	transport := newHTTPTransport(args.HTTPVersion, proxy)
+	// NOTE: a proxy connects on its own, without one every connection goes to an address the scope was checked with.
+	if proxy == nil {
+		transport.DialContext = scopedDialer(engagementScope)
+	}
+	var redirects []HTTPRedirect
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			if !args.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			previous := via[len(via)-1]
+			redirects = append(redirects, HTTPRedirect{Method: previous.Method, URL: previous.URL.String(), Status: next.Response.StatusCode})
+			if len(via) > maxHTTPRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxHTTPRedirects)
+			}
+			// NOTE: a redirect is a request of its own, an in scope target mustn't bounce the agent out of scope.
+			return engagementScope.Check(next.Context(), next.URL.Host)
+		},
+	}
+
+	// NOTE: the trace hooks may get called from the goroutines of the transport.
+	var mu sync.Mutex
+	var timing HTTPTiming
+	var dnsStart, connectStart, tlsStart time.Time
+	started := time.Now()
+	since := func(field *int64, start *time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		*field = time.Since(*start).Milliseconds()
+	}
+	mark := func(start *time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		*start = time.Now()
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { mark(&dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { since(&timing.DNS, &dnsStart) },
+		ConnectStart:         func(string, string) { mark(&connectStart) },
+		ConnectDone:          func(string, string, error) { since(&timing.Connect, &connectStart) },
+		TLSHandshakeStart:    func() { mark(&tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { since(&timing.TLS, &tlsStart) },
+		GotFirstResponseByte: func() { since(&timing.FirstByte, &started) },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, scope.ErrOutOfScope) && len(redirects) > 0 {
+			return NewTextErrorResponse(fmt.Sprintf("stopped following the redirects: %s", err)), nil
+		}
+		if errors.Is(err, scope.ErrOutOfScope) {
+			return NewTextErrorResponse(fmt.Sprintf("refusing to send the request: %s", err)), nil
+		}
+		return NewTextErrorResponse(fmt.Sprintf("request failed: %s", err)), nil
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize+1))
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to read the response body: %s", err)), nil
+	}
+	mu.Lock()
+	timing.Total = time.Since(started).Milliseconds()
+	mu.Unlock()
+	truncated := len(respBody) > maxHTTPBodySize
+	if truncated {
+		respBody = respBody[:maxHTTPBodySize]
+	}
+
+	metadata := HTTPResponseMetadata{
+		Method:    resp.Request.Method,
+		URL:       resp.Request.URL.String(),
+		Proto:     resp.Proto,
+		Status:    resp.StatusCode,
+		Headers:   resp.Header,
+		BodySize:  int64(len(respBody)),
+		Truncated: truncated,
+		Redirects: redirects,
+	}
+	mu.Lock()
+	metadata.Timing = timing
+	mu.Unlock()
+	if proxy != nil {
+		metadata.Proxy = proxy.Redacted()
+	}
+
+	var warnings []string
+	evidence, err := saveHTTPExchange(call.ID, resp.Request, body, resp, respBody, metadata)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("couldn't save the exchange as evidence: %s", err))
+	}
+	metadata.Evidence = evidence
+
+	return WithResponseMetadata(NewTextResponse(formatHTTPResponse(resp, respBody, metadata, warnings)), metadata), nil
+}
+
+// buildHTTPRequest turns the arguments into a request, returning the body separately for the evidence.
+func buildHTTPRequest(args HTTPRequestArgs) (*http.Request, []byte, error) {
+	method := strings.ToUpper(strings.TrimSpace(args.Method))
+	target := args.URL
+	headers := http.Header{}
+	for name, value := range args.Headers {
+		headers.Add(name, value)
+	}
+	body := []byte(args.Body)
+
+	if args.Raw != "" {
+		var err error
+		var rawTarget string
+		method, rawTarget, headers, body, err = parseRawHTTPRequest(args.Raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		if strings.HasPrefix(rawTarget, "http://") || strings.HasPrefix(rawTarget, "https://") {
+			target = rawTarget
+		} else {
+			if args.URL == "" {
+				return nil, nil, fmt.Errorf("url is required along with a raw request to know where to send it")
+			}
+			base, err := url.Parse(args.URL)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid url: %s", err)
+			}
+			target = base.Scheme + "://" + base.Host + rawTarget
+		}
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	if target == "" {
+		return nil, nil, fmt.Errorf("url is required")
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid url: %s", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, nil, fmt.Errorf("unsupported scheme %q, only http and https are", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, nil, fmt.Errorf("url %s has no host", target)
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	if host := headers.Get("Host"); host != "" {
+		req.Host = host
+		headers.Del("Host")
+	}
+	// NOTE: Content-Length and Transfer-Encoding are worked out by the transport from the body.
+	headers.Del("Content-Length")
+	headers.Del("Transfer-Encoding")
+	req.Header = headers
+	if len(body) == 0 {
+		req.Body = http.NoBody
+	}
+	return req, body, nil
+}
+
+// parseRawHTTPRequest splits a raw request into its method, request target, headers and body. it is lenient about
+// line endings since models rarely get the \r\n right.
+func parseRawHTTPRequest(raw string) (string, string, http.Header, []byte, error) {
+	raw = strings.TrimLeft(raw, "\r\n")
+	head, body, found := strings.Cut(raw, "\r\n\r\n")
+	if !found {
+		head, body, _ = strings.Cut(raw, "\n\n")
+	}
+	lines := strings.Split(strings.ReplaceAll(head, "\r\n", "\n"), "\n")
+
+	requestLine := strings.Fields(lines[0])
+	if len(requestLine) < 2 {
+		return "", "", nil, nil, fmt.Errorf("invalid request line %q, expected METHOD target HTTP/version", lines[0])
+	}
+	headers := http.Header{}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return "", "", nil, nil, fmt.Errorf("invalid header line %q", line)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return strings.ToUpper(requestLine[0]), requestLine[1], headers, []byte(body), nil
+}
+
+func newHTTPTransport(version string, proxy *url.URL) *http.Transport {
+	transport := &http.Transport{
+		// NOTE: targets of an engagement rarely have a valid certificate.
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+		DisableCompression:  true,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        1,
+		DisableKeepAlives:   true,
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	protocols := new(http.Protocols)
+	if version == "2" {
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	} else {
+		protocols.SetHTTP1(true)
+	}
+	transport.Protocols = protocols
+	return transport
+}
+
+// scopedDialer resolves the host of every connection once, checks it against the scope and connects to the addresses
+// it was checked with, so that a name resolving elsewhere by the time of the connection can't slip out of scope.
+func scopedDialer(engagementScope *scope.Scope) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		trace := httptrace.ContextClientTrace(ctx)
+		if trace != nil && trace.DNSStart != nil {
+			trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+		}
+		addrs, err := engagementScope.Resolve(ctx, host)
+		if trace != nil && trace.DNSDone != nil {
+			trace.DNSDone(httptrace.DNSDoneInfo{Err: err})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("%s didn't resolve to any address", host)
+		}
+		var dialer net.Dialer
+		for _, addr := range addrs {
+			var conn net.Conn
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// saveHTTPExchange writes the request and the response out as evidence, returning the path of the file.
+func saveHTTPExchange(callID string, req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, metadata HTTPResponseMetadata) (string, error) {
+	dir := config.EvidenceDir()
+	if err := os.MkdirAll(dir, evidenceDirMode); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s %s at %s\n", metadata.Method, metadata.URL, time.Now().Format(time.RFC3339))
+	for _, redirect := range metadata.Redirects {
+		fmt.Fprintf(&sb, "# redirected by %d from %s %s\n", redirect.Status, redirect.Method, redirect.URL)
+	}
+	if metadata.Proxy != "" {
+		fmt.Fprintf(&sb, "# via %s\n", metadata.Proxy)
+	}
+	sb.WriteString("\n")
+
+	// NOTE: the body only survives 307 and 308 redirects, the client drops it otherwise.
+	if req.ContentLength == 0 {
+		reqBody = nil
+	}
+	fmt.Fprintf(&sb, "%s %s %s\r\n", req.Method, req.URL.RequestURI(), resp.Proto)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(&sb, "Host: %s\r\n", host)
+	writeHTTPHeaders(&sb, req.Header)
+	sb.WriteString("\r\n")
+	sb.Write(reqBody)
+	sb.WriteString("\n\n")
+
+	fmt.Fprintf(&sb, "%s %s\r\n", resp.Proto, resp.Status)
+	writeHTTPHeaders(&sb, resp.Header)
+	sb.WriteString("\r\n")
+	sb.Write(respBody)
+	if metadata.Truncated {
+		fmt.Fprintf(&sb, "\n# body truncated at %d bytes\n", maxHTTPBodySize)
+	}
+
+	evidence := filepath.Join(dir, time.Now().Format("20060102-150405")+"-"+fileSafeID(callID)+".http")
+	if err := os.WriteFile(evidence, []byte(sb.String()), 0o644); err != nil {
+		return "", err
+	}
+	return evidence, nil
+}
+
+func writeHTTPHeaders(sb *strings.Builder, headers http.Header) {
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		for _, value := range headers[name] {
+			fmt.Fprintf(sb, "%s: %s\r\n", name, value)
+		}
+	}
+}
+
+func formatHTTPResponse(resp *http.Response, body []byte, metadata HTTPResponseMetadata, warnings []string) string {
+	var sb strings.Builder
+	for _, redirect := range metadata.Redirects {
+		fmt.Fprintf(&sb, "%d <- %s %s\n", redirect.Status, redirect.Method, redirect.URL)
+	}
+	fmt.Fprintf(&sb, "%s %s (%s %s)\n", resp.Proto, resp.Status, metadata.Method, metadata.URL)
+	t := metadata.Timing
+	fmt.Fprintf(&sb, "timing: total %dms, dns %dms, connect %dms, tls %dms, first byte %dms\n", t.Total, t.DNS, t.Connect, t.TLS, t.FirstByte)
+	if metadata.Evidence != "" {
+		fmt.Fprintf(&sb, "evidence: %s\n", metadata.Evidence)
+	}
+	sb.WriteString("\n")
+	writeHTTPHeaders(&sb, resp.Header)
+	sb.WriteString("\n")
+
+	switch {
+	case len(body) == 0:
+		sb.WriteString("<empty body>")
+	case !utf8.Valid(body):
+		fmt.Fprintf(&sb, "<binary body of %d bytes, see the evidence>", len(body))
+	case len(body) > maxHTTPBodyShown:
+		// NOTE: the body is cut on a character boundary, half a character is invalid UTF-8 to the providers.
+		shown := maxHTTPBodyShown
+		for shown > 0 && !utf8.RuneStart(body[shown]) {
+			shown--
+		}
+		sb.Write(body[:shown])
+		fmt.Fprintf(&sb, "\n... body truncated, showing %d of %d bytes", shown, len(body))
+	default:
+		sb.Write(body)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(&sb, "\n\nwarning: %s", warning)
+	}
+	return strings.ReplaceAll(sb.String(), "\r\n", "\n")
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/yyovil/tandem/internal/scope"
+)
+
+func TestBuildHTTPRequestRaw(t *testing.T) {
+	req, body, err := buildHTTPRequest(HTTPRequestArgs{
+		URL: "https://10.10.10.5:8443/ignored",
+		Raw: "post /login?next=%2F HTTP/1.1\nHost: app.lab\nContent-Type: application/x-www-form-urlencoded\nContent-Length: 999\n\nuser=admin&pass=admin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost || req.URL.String() != "https://10.10.10.5:8443/login?next=%2F" || req.Host != "app.lab" {
+		t.Fatalf("unexpected request: %s %s host %s", req.Method, req.URL, req.Host)
+	}
+	if string(body) != "user=admin&pass=admin" || req.ContentLength != int64(len(body)) || req.Header.Get("Content-Length") != "" {
+		t.Fatalf("unexpected body %q with content length %d", body, req.ContentLength)
+	}
+
+	if _, _, err := buildHTTPRequest(HTTPRequestArgs{Raw: "GET / HTTP/1.1\nHost: app.lab\n\n"}); err == nil {
+		t.Fatal("expected a relative raw request without a url to fail")
+	}
+	if _, _, err := buildHTTPRequest(HTTPRequestArgs{URL: "ftp://app.lab/"}); err == nil {
+		t.Fatal("expected a non http scheme to fail")
+	}
+}
+
+func TestFormatHTTPResponseTruncatesOnACharacter(t *testing.T) {
+	resp := &http.Response{Proto: "HTTP/1.1", Status: "200 OK", Header: http.Header{}}
+	body := []byte("x" + strings.Repeat("é", maxHTTPBodyShown))
+	formatted := formatHTTPResponse(resp, body, HTTPResponseMetadata{Method: http.MethodGet, URL: "http://10.10.10.5/"}, nil)
+	if !utf8.ValidString(formatted) {
+		t.Fatal("expected the truncated body to be valid UTF-8")
+	}
+	if !strings.Contains(formatted, fmt.Sprintf("showing %d of %d bytes", maxHTTPBodyShown-1, len(body))) {
+		t.Fatalf("expected the body to be cut before the split character, got %q", formatted[len(formatted)-80:])
+	}
+}
+
+func TestHTTPTransportVersions(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	for version, want := range map[string]string{"1.1": "HTTP/1.1", "2": "HTTP/2.0"} {
+		client := &http.Client{Transport: newHTTPTransport(version, nil)}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("http %s: %v", version, err)
+		}
+		proto, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(proto) != want {
+			t.Fatalf("http %s: server saw %s", version, proto)
+		}
+	}
+}
+
+func TestScopedDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	for include, inScope := range map[string]bool{"127.0.0.0/8": true, "10.0.0.0/8": false} {
+		engagementScope, err := scope.New([]string{include}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transport := newHTTPTransport("1.1", nil)
+		transport.DialContext = scopedDialer(engagementScope)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if inScope && err != nil {
+			t.Errorf("%s: %v", include, err)
+		}
+		if !inScope && !errors.Is(err, scope.ErrOutOfScope) {
+			t.Errorf("%s: expected the connection refused as out of scope, got %v", include, err)
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+	"github.com/yyovil/tandem/internal/version"
+)
+
+// mcpConnectTimeout bounds starting a server and listing its tools, a broken server shouldn't hold up the startup.
+const mcpConnectTimeout = 30 * time.Second
+
+// mcpServer is a connection to a configured MCP server, it's reopened on the next call once it breaks.
+type mcpServer struct {
+	name       string
+	definition config.MCPServer
+
+	mu     sync.Mutex
+	client *client.Client
+	tools  []mcp.Tool
+}
+
+type mcpTool struct {
+	server *mcpServer
+	tool   mcp.Tool
+}
+
+var mcpServers struct {
+	sync.Mutex
+	discovered bool
+	servers    map[string]*mcpServer
+	tools      []BaseTool
+}
+
+// InitMCPTools connects to the configured MCP servers and discovers their tools. servers that fail are logged and skipped.
+func InitMCPTools(ctx context.Context) []BaseTool {
+	mcpServers.Lock()
+	defer mcpServers.Unlock()
+	if mcpServers.discovered {
+		return mcpServers.tools
+	}
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.MCPServers))
+	for name := range cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	servers := make([]*mcpServer, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		servers[i] = &mcpServer{name: name, definition: cfg.MCPServers[name]}
+		wg.Add(1)
+		go func(server *mcpServer) {
+			defer wg.Done()
+			connectCtx, cancel := context.WithTimeout(ctx, mcpConnectTimeout)
+			defer cancel()
+			if _, err := server.connect(connectCtx); err != nil {
+				logging.ErrorPersist(fmt.Sprintf("mcp server %s: %v", server.name, err))
+			}
+		}(servers[i])
+	}
+	wg.Wait()
+
+	mcpServers.servers = map[string]*mcpServer{}
+	for _, server := range servers {
+		mcpServers.servers[server.name] = server
+		for _, tool := range server.tools {
+			tool := &mcpTool{server: server, tool: tool}
+			// NOTE: the server names its tools itself, a name the providers refuse would fail every request of the agent.
+			if !validToolName(tool.Info().Name) {
+				logging.WarnPersist(fmt.Sprintf("mcp server %s: skipping the tool %q, only letters, digits, - and _ are allowed in tool names, 64 at most", server.name, tool.tool.Name))
+				continue
+			}
+			mcpServers.tools = append(mcpServers.tools, tool)
+		}
+		logging.Info("discovered mcp tools", "server", server.name, "tools", len(server.tools))
+	}
+	mcpServers.discovered = true
+	return mcpServers.tools
+}
+
+// MCPToolsFor returns the MCP tools an agent lists in its tools, either by the name of their server or by their own name.
+func MCPToolsFor(ctx context.Context, agentName config.AgentName) []BaseTool {
+	cfg := config.Get()
+	if cfg == nil || len(cfg.MCPServers) == 0 {
+		return nil
+	}
+	wanted := cfg.Agents[agentName].Tools
+	var assigned []BaseTool
+	for _, tool := range InitMCPTools(ctx) {
+		if wantsMCPTool(wanted, tool.(*mcpTool)) {
+			assigned = append(assigned, tool)
+		}
+	}
+	return assigned
+}
+
+// wantsMCPTool tells whether the tools of an agent list the tool or its server.
+// NOTE: viper lowercases the names of the servers, the agents may list them as they were written.
+func wantsMCPTool(wanted []string, tool *mcpTool) bool {
+	return slices.ContainsFunc(wanted, func(name string) bool {
+		return strings.EqualFold(name, tool.server.name) || strings.EqualFold(name, tool.Info().Name)
+	})
+}
+
+// validToolName tells whether the providers take the name of a tool.
+func validToolName(name string) bool {
+	return name != "" && len(name) <= 64 && strings.Trim(name, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_") == ""
+}
+
+// CloseMCPServers shuts the MCP servers down, the stdio ones are child processes of tandem.
+func CloseMCPServers() {
+	mcpServers.Lock()
+	defer mcpServers.Unlock()
+	for _, server := range mcpServers.servers {
+		server.close()
+	}
+}
+
+// connect returns the client of the server, starting it and listing its tools if there's none yet.
+func (s *mcpServer) connect(ctx context.Context) (*client.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	var c *client.Client
+	var err error
+	switch s.definition.Type {
+	case config.MCPStdio:
+		c, err = client.NewStdioMCPClient(s.definition.Command, s.definition.Env, s.definition.Args...)
+		if err == nil {
+			// NOTE: stderr has to be drained, a server blocks once the pipe is full.
+			if stderr, ok := client.GetStderr(c); ok {
+				go func() {
+					scanner := bufio.NewScanner(stderr)
+					for scanner.Scan() {
+						logging.Debug("mcp server stderr", "server", s.name, "line", scanner.Text())
+					}
+				}()
+			}
+		}
+	case config.MCPSSE:
+		c, err = client.NewSSEMCPClient(s.definition.URL, client.WithHeaders(s.definition.Headers))
+	default:
+		c, err = client.NewStreamableHttpClient(s.definition.URL, transport.WithHTTPHeaders(s.definition.Headers))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "tandem", Version: version.Version}
+	if _, err := c.Initialize(ctx, request); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialise: %w", err)
+	}
+	listed, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to list the tools: %w", err)
+	}
+
+	// NOTE: the tools are only discovered once, a reconnect doesn't pick up the tools a server added since.
+	s.client, s.tools = c, listed.Tools
+	return c, nil
+}
+
+func (s *mcpServer) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+func (t *mcpTool) Info() ToolInfo {
+	required := t.tool.InputSchema.Required
+	if required == nil {
+		required = []string{}
+	}
+	properties := t.tool.InputSchema.Properties
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	return ToolInfo{
+		Name:        t.server.name + "_" + t.tool.Name,
+		Description: t.tool.Description,
+		Parameters:  properties,
+		Required:    required,
+	}
+}
+
+func (t *mcpTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	if IsHalted() {
+		return NewTextErrorResponse("tool calls are halted by the operator (emergency stop). wait for the operator to resume."), nil
+	}
+	var arguments map[string]any
+	if strings.TrimSpace(call.Input) != "" {
+		if err := json.Unmarshal([]byte(call.Input), &arguments); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to parse %s arguments: %s", t.Info().Name, err)), nil
+		}
+	}
+
+	c, err := t.server.connect(ctx)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("couldn't reach the %s mcp server: %s", t.server.name, err)), nil
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Name = t.tool.Name
+	request.Params.Arguments = arguments
+	result, err := c.CallTool(ctx, request)
+	if err != nil {
+		if ctx.Err() == nil {
+			// NOTE: the connection may be gone, e.g. the server crashed, the next call starts it again.
+			t.server.close()
+		}
+		return NewTextErrorResponse(fmt.Sprintf("%s failed: %s", t.Info().Name, err)), nil
+	}
+
+	response := NewTextResponse(MaskSecrets(formatMCPContent(result.Content)))
+	response.IsError = result.IsError
+	if result.StructuredContent != nil {
+		return WithResponseMetadata(response, result.StructuredContent), nil
+	}
+	return response, nil
+}
+
+// formatMCPContent renders the content of a tool result as text, only the text of resources and their URIs are kept.
+func formatMCPContent(contents []mcp.Content) string {
+	var parts []string
+	for _, content := range contents {
+		switch content := content.(type) {
+		case mcp.TextContent:
+			parts = append(parts, content.Text)
+		case mcp.ImageContent:
+			parts = append(parts, fmt.Sprintf("[%s image omitted]", content.MIMEType))
+		case mcp.AudioContent:
+			parts = append(parts, fmt.Sprintf("[%s audio omitted]", content.MIMEType))
+		case mcp.ResourceLink:
+			parts = append(parts, fmt.Sprintf("[resource %s: %s]", content.Name, content.URI))
+		case mcp.EmbeddedResource:
+			switch resource := content.Resource.(type) {
+			case mcp.TextResourceContents:
+				parts = append(parts, resource.Text)
+			case mcp.BlobResourceContents:
+				parts = append(parts, fmt.Sprintf("[%s resource %s omitted]", resource.MIMEType, resource.URI))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "the tool returned no content"
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPToolNames(t *testing.T) {
+	// NOTE: viper hands the server names over lowercased.
+	scan := &mcpTool{server: &mcpServer{name: "burpsuite"}, tool: mcp.Tool{Name: "scan"}}
+	for _, wanted := range [][]string{{"BurpSuite"}, {"burpsuite"}, {"terminal", "BurpSuite_scan"}} {
+		if !wantsMCPTool(wanted, scan) {
+			t.Errorf("expected %v to include %s", wanted, scan.Info().Name)
+		}
+	}
+	if wantsMCPTool([]string{"burp", "scan"}, scan) {
+		t.Errorf("expected %s to be left out", scan.Info().Name)
+	}
+
+	tests := map[string]bool{
+		"burpsuite_scan":                       true,
+		"burpsuite_active-scan":                true,
+		"burpsuite_scan.start":                 false,
+		"burpsuite_scan target":                false,
+		"burpsuite_" + strings.Repeat("a", 60): false,
+	}
+	for name, valid := range tests {
+		if validToolName(name) != valid {
+			t.Errorf("%s: expected valid to be %v", name, valid)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yyovil/tandem/internal/inventory"
+	"github.com/yyovil/tandem/internal/logging"
+	"github.com/yyovil/tandem/internal/nmap"
+)
+
+const (
+	NmapToolName = "nmap"
+
+	// maxScriptOutput caps the output of a single NSE script in the summary handed to the model.
+	maxScriptOutput = 300
+)
+
+type NmapArgs struct {
+	Targets         []string `json:"targets"`
+	Ports           string   `json:"ports,omitempty"`
+	Flags           []string `json:"flags,omitempty"`
+	Timeout         int      `json:"timeout,omitempty"` // seconds
+	RecordInventory bool     `json:"record_inventory,omitempty"`
+}
+
+// NmapResponseMetadata carries the parsed scan for the TUI and for whoever wants more than the summary.
+type NmapResponseMetadata struct {
+	Command  string      `json:"command"`
+	Executor string      `json:"executor"`
+	Duration int64       `json:"duration_ms"`
+	Hosts    []nmap.Host `json:"hosts"`
+	Recorded int         `json:"recorded"`
+}
+
+type NmapTool struct {
+	inventory inventory.Service
+}
+
+func NewNmapTool(inventory inventory.Service) BaseTool {
+	return &NmapTool{
+		inventory: inventory,
+	}
+}
+
+func (t *NmapTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        NmapToolName,
+		Description: "Run an nmap scan and get back a structured summary of the hosts, ports, services and NSE script results. prefer it over running nmap in the terminal.",
+		Parameters: map[string]any{
+			"targets": map[string]any{
+				"type":        "array",
+				"description": "hosts, networks in CIDR notation or ranges to scan",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"ports": map[string]any{
+				"type":        "string",
+				"description": "ports to scan as passed to -p, e.g. 22,80,443 or 1-65535. nmap's top 1000 ports by default.",
+			},
+			"flags": map[string]any{
+				"type":        "array",
+				"description": "extra nmap flags, e.g. -sV, -sC, -O, -T4, --script=vuln. output flags (-o*) aren't allowed.",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"timeout": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("seconds after which the scan is killed. defaults to %d.", int(DefaultTerminalTimeout.Seconds())),
+			},
+			"record_inventory": map[string]any{
+				"type":        "boolean",
+				"description": "record the hosts that are up and their ports in the engagement's asset inventory",
+			},
+		},
+		Required: []string{"targets"},
+	}
+}
+
+func (t *NmapTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args NmapArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse nmap arguments: " + err.Error()), nil
+	}
+	if len(args.Targets) == 0 {
+		return NewTextErrorResponse("at least one target is required"), nil
+	}
+
+	cmd := []string{"nmap"}
+	for _, flag := range args.Flags {
+		// NOTE: the XML report has to be the only thing nmap writes on stdout.
+		if strings.HasPrefix(flag, "-o") || strings.HasPrefix(flag, "--stylesheet") || flag == "--webxml" {
+			return NewTextErrorResponse(fmt.Sprintf("output flag %s isn't allowed, the tool takes care of the output", flag)), nil
+		}
+		cmd = append(cmd, flag)
+	}
+	if args.Ports != "" {
+		cmd = append(cmd, "-p", args.Ports)
+	}
+	cmd = append(cmd, "-oX", "-", "--")
+	cmd = append(cmd, args.Targets...)
+
+	timeout := DefaultTerminalTimeout
+	if args.Timeout > 0 {
+		timeout = time.Duration(args.Timeout) * time.Second
+	}
+
+	if terminal == nil {
+		return NewTextErrorResponse("terminal is not initialised"), nil
+	}
+	execution, err := terminal.execute(ctx, call.ID, cmd, timeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	run, err := nmap.Parse(strings.NewReader(execution.Stdout))
+	if err != nil {
+		execution.Stdout = ""
+		return NewTextErrorResponse(fmt.Sprintf("%s\n%s", err, formatTerminalOutput(execution))), nil
+	}
+
+	metadata := NmapResponseMetadata{
+		Command:  execution.Command,
+		Executor: execution.Executor,
+		Duration: execution.Duration,
+		Hosts:    run.Hosts,
+	}
+
+	var warnings []string
+	if args.RecordInventory {
+		recorded, err := t.record(ctx, run)
+		if err != nil {
+			logging.Error("failed to record the nmap results", "error", err)
+			warnings = append(warnings, fmt.Sprintf("couldn't record the results in the inventory: %s", err))
+		}
+		metadata.Recorded = recorded
+	}
+
+	summary := summarizeNmapRun(run, execution.Stderr, warnings)
+	if args.RecordInventory && metadata.Recorded > 0 {
+		summary += fmt.Sprintf("\nrecorded %d hosts in the inventory.", metadata.Recorded)
+	}
+	return WithResponseMetadata(ToolResponse{
+		Type:    ToolResponseTypeText,
+		Content: summary,
+		IsError: execution.ExitCode != 0 || run.RunStats.Finished.Exit == "error",
+	}, metadata), nil
+}
+
+// record saves the hosts that are up along with their ports in the inventory.
+func (t *NmapTool) record(ctx context.Context, run *nmap.Run) (int, error) {
+	if t.inventory == nil {
+		return 0, fmt.Errorf("no inventory available")
+	}
+	recorded := 0
+	for _, host := range run.Hosts {
+		if host.Status.State != "up" || host.Address() == "" {
+			continue
+		}
+		inventoryHost := inventory.Host{
+			Address:  host.Address(),
+			Hostname: host.Hostname(),
+			OS:       host.OS(),
+			Status:   host.Status.State,
+			Source:   NmapToolName,
+		}
+		for _, port := range host.Ports {
+			inventoryHost.Ports = append(inventoryHost.Ports, inventory.Port{
+				Number:   int64(port.PortID),
+				Protocol: port.Protocol,
+				State:    port.State.State,
+				Service:  port.Service.FullName(),
+				Product:  port.Service.Product,
+				Version:  port.Service.Version,
+				Source:   NmapToolName,
+			})
+		}
+		if _, err := t.inventory.RecordHost(ctx, inventoryHost); err != nil {
+			return recorded, err
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// summarizeNmapRun renders a compact, line oriented summary of the scan. hosts that are down are only counted.
+func summarizeNmapRun(run *nmap.Run, stderr string, warnings []string) string {
+	var sb strings.Builder
+	stats := run.RunStats
+	fmt.Fprintf(&sb, "nmap %s finished in %.1fs: %d up, %d down of %d hosts\n", run.Version, stats.Finished.Elapsed, stats.Hosts.Up, stats.Hosts.Down, stats.Hosts.Total)
+	if stats.Finished.ErrMsg != "" {
+		fmt.Fprintf(&sb, "error: %s\n", stats.Finished.ErrMsg)
+	}
+
+	for _, host := range run.Hosts {
+		if host.Status.State != "up" {
+			continue
+		}
+		sb.WriteString("\n")
+		sb.WriteString(host.Address())
+		if hostname := host.Hostname(); hostname != "" {
+			fmt.Fprintf(&sb, " (%s)", hostname)
+		}
+		if osName := host.OS(); osName != "" {
+			fmt.Fprintf(&sb, " os: %s", osName)
+		}
+		sb.WriteString("\n")
+
+		for _, port := range host.Ports {
+			fmt.Fprintf(&sb, "  %d/%s %s %s", port.PortID, port.Protocol, port.State.State, port.Service.FullName())
+			if description := port.Service.Describe(); description != "" {
+				fmt.Fprintf(&sb, " %s", description)
+			}
+			sb.WriteString("\n")
+			for _, script := range port.Scripts {
+				fmt.Fprintf(&sb, "    %s: %s\n", script.ID, compactScriptOutput(script.Output))
+			}
+		}
+		for _, extra := range host.ExtraPorts {
+			fmt.Fprintf(&sb, "  %d %s ports not shown\n", extra.Count, extra.State)
+		}
+		for _, script := range host.Scripts {
+			fmt.Fprintf(&sb, "  %s: %s\n", script.ID, compactScriptOutput(script.Output))
+		}
+	}
+
+	if stderr = strings.TrimSpace(stderr); stderr != "" {
+		fmt.Fprintf(&sb, "\n<stderr>\n%s\n</stderr>\n", stderr)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(&sb, "\nwarning: %s\n", warning)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// compactScriptOutput folds the multi line output of an NSE script into a single truncated line.
+func compactScriptOutput(output string) string {
+	output = strings.Join(strings.Fields(output), " ")
+	if len(output) > maxScriptOutput {
+		return output[:maxScriptOutput] + "..."
+	}
+	return output
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// NOTE: every command started by an agent records the pid of its process group leader in here, inside the container or on the remote host.
+const containerPidDir = "/tmp/tandem/pids"
+
+// trackedProcess is a command started by an agent that is still running on an executor.
+type trackedProcess struct {
+	executor string
+	// target is where the command runs, the container ID or the remote host.
+	target  string
+	pidFile string
+	command string
+}
+
+// processRegistry keeps track of the agent started processes across all the executors.
+type processRegistry struct {
+	mu        sync.Mutex
+	processes map[string]trackedProcess
+}
+
+var processes = &processRegistry{
+	processes: make(map[string]trackedProcess),
+}
+
+func (r *processRegistry) add(callID string, process trackedProcess) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processes[callID] = process
+}
+
+func (r *processRegistry) remove(callID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.processes, callID)
+}
+
+func (r *processRegistry) list() []trackedProcess {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]trackedProcess, 0, len(r.processes))
+	for _, process := range r.processes {
+		list = append(list, process)
+	}
+	return list
+}
+
+// pidFileFor returns the path of the pid file for a tool call.
+func pidFileFor(callID string) string {
+	return path.Join(containerPidDir, fileSafeID(callID)+".pid")
+}
+
+// wrapTrackedCommand runs cmd as the leader of a new session so that the whole process tree can be killed at once.
+// the pid file is removed by the wrapper itself once the command exits on its own.
+func wrapTrackedCommand(pidFile string, cmd []string) []string {
+	script := fmt.Sprintf(`mkdir -p %s && echo $$ > "$0"; "$@"; rc=$?; rm -f "$0"; exit $rc`, containerPidDir)
+	wrapped := []string{"setsid", "-w", "sh", "-c", script, pidFile}
+	return append(wrapped, cmd...)
+}
+
+// killTreeCommand kills the process group recorded in the pid file. like wrapTrackedCommand, it hands the pid file to
+// the script as an argument rather than writing it into the script.
+func killTreeCommand(pidFile string) []string {
+	script := `[ -f "$0" ] && kill -KILL -- -$(cat "$0") 2>/dev/null; rm -f "$0"`
+	return []string{"sh", "-c", script, pidFile}
+}
+
+// killAllCommand kills every tracked process group.
+// NOTE: it goes through the pid files instead of the registry so that stragglers from a previous run are killed too.
+func killAllCommand() []string {
+	script := fmt.Sprintf(`for f in %s/*.pid; do [ -f "$f" ] && kill -KILL -- -$(cat "$f") 2>/dev/null; rm -f "$f"; done`, containerPidDir)
+	return []string{"sh", "-c", script}
+}
+
+// EmergencyStop kills every process started by the agents on every executor and freezes the containers.
+// commands are refused until Resume is called.
+func EmergencyStop(ctx context.Context) error {
+	if terminal == nil {
+		return fmt.Errorf("terminal is not initialised")
+	}
+	return terminal.emergencyStop(ctx)
+}
+
+// Resume unfreezes the executors after an EmergencyStop and allows commands to run again.
+func Resume(ctx context.Context) error {
+	if terminal == nil {
+		return fmt.Errorf("terminal is not initialised")
+	}
+	return terminal.resume(ctx)
+}
+
+// IsHalted reports whether the terminal is halted by an EmergencyStop.
+func IsHalted() bool {
+	return terminal != nil && terminal.halted.Load()
+}
+
+func (term *Terminal) emergencyStop(ctx context.Context) error {
+	term.halted.Store(true)
+
+	var errs []error
+	for name, executor := range term.activeExecutors() {
+		if err := executor.Halt(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	for _, process := range processes.list() {
+		logging.Info("killed agent process", "executor", process.executor, "target", process.target, "command", MaskSecrets(process.command))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("emergency stop incomplete: %v", errs)
+	}
+	return nil
+}
+
+func (term *Terminal) resume(ctx context.Context) error {
+	defer term.halted.Store(false)
+
+	var errs []error
+	for name, executor := range term.activeExecutors() {
+		if err := executor.Resume(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("resume incomplete: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/report"
+)
+
+const WriteReportSectionToolName = "write_report_section"
+
+type WriteReportSectionArgs struct {
+	Section string `json:"section"`
+	Content string `json:"content"`
+}
+
+type ReportSectionResponseMetadata struct {
+	Section string `json:"section"`
+	Length  int    `json:"length"`
+}
+
+type writeReportSectionTool struct {
+	sections report.Service
+}
+
+func NewWriteReportSectionTool(sections report.Service) BaseTool {
+	return &writeReportSectionTool{sections: sections}
+}
+
+func (t *writeReportSectionTool) Info() ToolInfo {
+	names := make([]string, len(report.SectionNames))
+	for i, name := range report.SectionNames {
+		names[i] = string(name)
+	}
+	return ToolInfo{
+		Name:        WriteReportSectionToolName,
+		Description: "Write a narrative section of the engagement report in markdown, writing a section again replaces it. the findings, the scope, the commands and the evidence are filled into the report from the engagement data, the sections tell the story around them: the executive summary for a non-technical reader, the scope and methodology as tested, the attack narrative from foothold to impact and the conclusion. refer to findings by their titles, don't repeat their details.",
+		Parameters: map[string]any{
+			"section": map[string]any{
+				"type":        "string",
+				"description": "the section to write",
+				"enum":        names,
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "the markdown of the section, without its heading",
+			},
+		},
+		Required: []string{"section", "content"},
+	}
+}
+
+func (t *writeReportSectionTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args WriteReportSectionArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse write_report_section arguments: " + err.Error()), nil
+	}
+	name := report.SectionName(strings.ToLower(strings.TrimSpace(args.Section)))
+	if !slices.Contains(report.SectionNames, name) {
+		return NewTextErrorResponse(fmt.Sprintf("unknown report section %q", args.Section)), nil
+	}
+	if strings.TrimSpace(args.Content) == "" {
+		return NewTextErrorResponse("content is required"), nil
+	}
+
+	agentName, _ := ctx.Value(AgentNameContextKey).(string)
+	section, err := t.sections.Write(ctx, name, args.Content, agentName)
+	if err != nil {
+		return NewTextErrorResponse("failed to write the section: " + err.Error()), nil
+	}
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("the %s section of the report is written", strings.ToLower(section.Name.Title()))),
+		ReportSectionResponseMetadata{Section: string(section.Name), Length: len(section.Content)},
+	), nil
+}
@@ -0,0 +1,329 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/exploitdb"
+	"github.com/yyovil/tandem/internal/nvd"
+)
+
+const (
+	CVELookupToolName     = "cve_lookup"
+	ExploitSearchToolName = "exploit_search"
+
+	maxCVEIDs           = 20
+	maxCPEsShown        = 25
+	maxReferencesShown  = 15
+	defaultExploitLimit = 25
+	maxExploitLimit     = 200
+)
+
+type CVELookupArgs struct {
+	IDs []string `json:"ids"`
+}
+
+type CVELookupResponseMetadata struct {
+	CVEs     []nvd.CVE                      `json:"cves"`
+	Missing  []string                       `json:"missing,omitempty"`
+	Exploits map[string][]exploitdb.Exploit `json:"exploits,omitempty"`
+}
+
+type ExploitSearchArgs struct {
+	Query    string `json:"query,omitempty"`
+	CVE      string `json:"cve,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+type ExploitSearchResponseMetadata struct {
+	Total    int                 `json:"total"`
+	Exploits []exploitdb.Exploit `json:"exploits"`
+}
+
+type cveLookupTool struct{}
+
+type exploitSearchTool struct{}
+
+func NewCVELookupTool() BaseTool {
+	return &cveLookupTool{}
+}
+
+func NewExploitSearchTool() BaseTool {
+	return &exploitSearchTool{}
+}
+
+func (t *cveLookupTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        CVELookupToolName,
+		Description: "Look CVEs up in the offline NVD mirror of the engagement. returns the CVSS scores, CWEs, affected CPEs with their version ranges, references and the exploitdb entries for each CVE. works without internet access.",
+		Parameters: map[string]any{
+			"ids": map[string]any{
+				"type":        "array",
+				"description": fmt.Sprintf("CVE IDs to look up, e.g. CVE-2021-44228. up to %d at once.", maxCVEIDs),
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+		Required: []string{"ids"},
+	}
+}
+
+func (t *cveLookupTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args CVELookupArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse cve_lookup arguments: " + err.Error()), nil
+	}
+	if len(args.IDs) == 0 {
+		return NewTextErrorResponse("at least one CVE ID is required"), nil
+	}
+	if len(args.IDs) > maxCVEIDs {
+		return NewTextErrorResponse(fmt.Sprintf("at most %d CVEs can be looked up at once", maxCVEIDs)), nil
+	}
+	cfg := config.Get()
+	if cfg == nil {
+		return NewTextErrorResponse("config not loaded"), nil
+	}
+
+	dir := config.ResolvePath(cfg.Research.NVD)
+	if _, err := os.Stat(dir); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("no NVD mirror at %s, point research.nvd in swarm.json at a directory of NVD JSON feeds", dir)), nil
+	}
+	found, err := nvd.Lookup(ctx, dir, args.IDs)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	// NOTE: exploits are a bonus, a missing exploitdb doesn't fail the lookup.
+	index, _ := loadExploitIndex()
+
+	metadata := CVELookupResponseMetadata{Exploits: map[string][]exploitdb.Exploit{}}
+	var sb strings.Builder
+	for _, id := range args.IDs {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		cve, ok := found[id]
+		if !ok {
+			metadata.Missing = append(metadata.Missing, id)
+			continue
+		}
+		metadata.CVEs = append(metadata.CVEs, cve)
+		var exploits []exploitdb.Exploit
+		if index != nil {
+			exploits = index.Search(exploitdb.Query{CVE: id})
+			if len(exploits) > 0 {
+				metadata.Exploits[id] = exploits
+			}
+		}
+		formatCVE(&sb, cve, exploits)
+		sb.WriteString("\n")
+	}
+	if len(metadata.Missing) > 0 {
+		fmt.Fprintf(&sb, "not in the NVD mirror: %s\n", strings.Join(metadata.Missing, ", "))
+	}
+	if index == nil {
+		sb.WriteString("exploitdb isn't available, exploits weren't looked up.\n")
+	}
+
+	response := NewTextResponse(strings.TrimRight(sb.String(), "\n"))
+	response.IsError = len(metadata.CVEs) == 0
+	return WithResponseMetadata(response, metadata), nil
+}
+
+func formatCVE(sb *strings.Builder, cve nvd.CVE, exploits []exploitdb.Exploit) {
+	fmt.Fprintf(sb, "%s (published %s, modified %s)\n", cve.ID, shortDate(cve.Published), shortDate(cve.Modified))
+	for _, metric := range cve.Metrics {
+		fmt.Fprintf(sb, "  cvss %s: %.1f %s %s", metric.Version, metric.Score, strings.ToLower(metric.Severity), metric.Vector)
+		if metric.Source != "" {
+			fmt.Fprintf(sb, " (%s)", metric.Source)
+		}
+		sb.WriteString("\n")
+	}
+	if len(cve.CWEs) > 0 {
+		fmt.Fprintf(sb, "  weaknesses: %s\n", strings.Join(cve.CWEs, ", "))
+	}
+	fmt.Fprintf(sb, "  %s\n", cve.Description)
+
+	if len(cve.CPEs) > 0 {
+		sb.WriteString("  affected:\n")
+		for i, cpe := range cve.CPEs {
+			if i == maxCPEsShown {
+				fmt.Fprintf(sb, "    and %d more\n", len(cve.CPEs)-maxCPEsShown)
+				break
+			}
+			fmt.Fprintf(sb, "    %s%s\n", cpe.Criteria, versionRange(cpe))
+		}
+	}
+	if len(cve.References) > 0 {
+		sb.WriteString("  references:\n")
+		for i, reference := range cve.References {
+			if i == maxReferencesShown {
+				fmt.Fprintf(sb, "    and %d more\n", len(cve.References)-maxReferencesShown)
+				break
+			}
+			fmt.Fprintf(sb, "    %s", reference.URL)
+			if len(reference.Tags) > 0 {
+				fmt.Fprintf(sb, " [%s]", strings.Join(reference.Tags, ", "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(exploits) > 0 {
+		sb.WriteString("  exploits:\n")
+		for _, exploit := range exploits {
+			fmt.Fprintf(sb, "    %s\n", formatExploit(exploit))
+		}
+	}
+}
+
+// versionRange renders the version bounds of a CPE match, e.g. " >= 2.0.1, < 2.15.0".
+func versionRange(cpe nvd.CPEMatch) string {
+	var bounds []string
+	for _, bound := range []struct{ op, version string }{
+		{">=", cpe.VersionStartIncluding},
+		{">", cpe.VersionStartExcluding},
+		{"<=", cpe.VersionEndIncluding},
+		{"<", cpe.VersionEndExcluding},
+	} {
+		if bound.version != "" {
+			bounds = append(bounds, bound.op+" "+bound.version)
+		}
+	}
+	if len(bounds) == 0 {
+		return ""
+	}
+	return " " + strings.Join(bounds, ", ")
+}
+
+func shortDate(timestamp string) string {
+	if len(timestamp) >= 10 {
+		return timestamp[:10]
+	}
+	return timestamp
+}
+
+func (t *exploitSearchTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ExploitSearchToolName,
+		Description: "Search the offline exploitdb index like searchsploit does and get back the matching exploits with their paths. copy one into the workspace with searchsploit -m <id> in the terminal. works without internet access.",
+		Parameters: map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "words that all have to appear in the exploit title, e.g. apache 2.4.49 or vsftpd 2.3.4",
+			},
+			"cve": map[string]any{
+				"type":        "string",
+				"description": "only exploits for this CVE, e.g. CVE-2021-41773",
+			},
+			"platform": map[string]any{
+				"type":        "string",
+				"description": "only exploits for this platform, e.g. linux, windows, php, multiple",
+			},
+			"type": map[string]any{
+				"type":        "string",
+				"description": "only exploits of this type: remote, local, webapps or dos",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("maximum number of exploits to return, %d by default", defaultExploitLimit),
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (t *exploitSearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ExploitSearchArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse exploit_search arguments: " + err.Error()), nil
+	}
+	if strings.TrimSpace(args.Query) == "" && strings.TrimSpace(args.CVE) == "" {
+		return NewTextErrorResponse("either a query or a cve is required"), nil
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultExploitLimit
+	}
+	limit = min(limit, maxExploitLimit)
+
+	index, err := loadExploitIndex()
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	exploits := index.Search(exploitdb.Query{
+		Terms:    strings.Fields(args.Query),
+		CVE:      args.CVE,
+		Platform: args.Platform,
+		Type:     args.Type,
+	})
+
+	metadata := ExploitSearchResponseMetadata{Total: len(exploits), Exploits: exploits[:min(limit, len(exploits))]}
+	if len(exploits) == 0 {
+		return WithResponseMetadata(NewTextResponse("no exploits found"), metadata), nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d exploits found", len(exploits))
+	if len(exploits) > limit {
+		fmt.Fprintf(&sb, ", showing the %d latest", limit)
+	}
+	sb.WriteString("\n")
+	for _, exploit := range metadata.Exploits {
+		fmt.Fprintf(&sb, "%s\n", formatExploit(exploit))
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimRight(sb.String(), "\n")), metadata), nil
+}
+
+func formatExploit(exploit exploitdb.Exploit) string {
+	line := fmt.Sprintf("edb-%s  %s  %s/%s  %s  %s", exploit.ID, exploit.Published, exploit.Platform, exploit.Type, exploit.Description, exploit.Path)
+	if exploit.Verified {
+		line += "  (verified)"
+	}
+	if len(exploit.Codes) > 0 {
+		line += "  [" + strings.Join(exploit.Codes, ", ") + "]"
+	}
+	return line
+}
+
+var exploitIndex struct {
+	sync.Mutex
+	path    string
+	modTime time.Time
+	index   *exploitdb.Index
+}
+
+// loadExploitIndex parses the configured exploitdb index once and again only when it changes on disk.
+func loadExploitIndex() (*exploitdb.Index, error) {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil, fmt.Errorf("config not loaded")
+	}
+	path := config.ResolvePath(cfg.Research.ExploitDB)
+	file := path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		file = filepath.Join(path, exploitdb.IndexFile)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("no exploitdb index at %s, point research.exploitdb in swarm.json at an exploitdb checkout", file)
+	}
+
+	exploitIndex.Lock()
+	defer exploitIndex.Unlock()
+	if exploitIndex.index != nil && exploitIndex.path == file && exploitIndex.modTime.Equal(info.ModTime()) {
+		return exploitIndex.index, nil
+	}
+	index, err := exploitdb.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	exploitIndex.path, exploitIndex.modTime, exploitIndex.index = file, info.ModTime(), index
+	return index, nil
+}
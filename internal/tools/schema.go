@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// validateArguments checks the arguments of a call against an object schema. it understands the part of JSON schema
+// tool parameters get declared with: type, enum, pattern, the length and range bounds, items and required.
+func validateArguments(schema map[string]any, arguments map[string]any) error {
+	properties, _ := schema["properties"].(map[string]any)
+	for _, name := range schemaStrings(schema["required"]) {
+		if value, ok := arguments[name]; !ok || value == nil {
+			return fmt.Errorf("%s is required", name)
+		}
+	}
+
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("unknown parameter %s", name)
+		}
+		if arguments[name] == nil {
+			continue
+		}
+		if err := validateValue(property, arguments[name], name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateValue(schema map[string]any, value any, path string) error {
+	if schemaType, ok := schema["type"].(string); ok && !hasType(value, schemaType) {
+		return fmt.Errorf("%s has to be of type %s", path, schemaType)
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(allowed any) bool { return reflect.DeepEqual(allowed, value) }) {
+		return fmt.Errorf("%s has to be one of %s", path, formatEnum(enum))
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := float64(utf8.RuneCountInString(value))
+		if limit, ok := schemaNumber(schema["minLength"]); ok && length < limit {
+			return fmt.Errorf("%s has to be at least %v characters long", path, limit)
+		}
+		if limit, ok := schemaNumber(schema["maxLength"]); ok && length > limit {
+			return fmt.Errorf("%s has to be at most %v characters long", path, limit)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("the pattern of %s is invalid: %s", path, err)
+			}
+			if !re.MatchString(value) {
+				return fmt.Errorf("%s has to match %s", path, pattern)
+			}
+		}
+	case float64:
+		if limit, ok := schemaNumber(schema["minimum"]); ok && value < limit {
+			return fmt.Errorf("%s has to be at least %v", path, limit)
+		}
+		if limit, ok := schemaNumber(schema["maximum"]); ok && value > limit {
+			return fmt.Errorf("%s has to be at most %v", path, limit)
+		}
+	case []any:
+		if limit, ok := schemaNumber(schema["minItems"]); ok && float64(len(value)) < limit {
+			return fmt.Errorf("%s needs at least %v items", path, limit)
+		}
+		if limit, ok := schemaNumber(schema["maxItems"]); ok && float64(len(value)) > limit {
+			return fmt.Errorf("%s takes at most %v items", path, limit)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				if err := validateValue(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether a value decoded by encoding/json is of the JSON schema type.
+func hasType(value any, schemaType string) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == float64(int64(number))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func schemaStrings(v any) []string {
+	var values []string
+	switch v := v.(type) {
+	case []string:
+		values = v
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+func formatEnum(enum []any) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		encoded, _ := json.Marshal(value)
+		values[i] = string(encoded)
+	}
+	return strings.Join(values, ", ")
+}
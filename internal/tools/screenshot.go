@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/scope"
+)
+
+const (
+	ScreenshotToolName = "screenshot"
+
+	defaultScreenshotWidth  = 1280
+	defaultScreenshotHeight = 800
+	maxScreenshotSide       = 4096
+	defaultScreenshotWait   = 5
+	maxScreenshotWait       = 60
+	screenshotTimeout       = 2 * time.Minute
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+type ScreenshotArgs struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Wait   int    `json:"wait,omitempty"` // seconds
+}
+
+type ScreenshotResponseMetadata struct {
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Evidence string `json:"evidence"`
+	SHA256   string `json:"sha256"`
+}
+
+type ScreenshotTool struct{}
+
+func NewScreenshotTool() BaseTool {
+	return &ScreenshotTool{}
+}
+
+func (t *ScreenshotTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ScreenshotToolName,
+		Description: "Take a screenshot of a web page rendered by headless chromium in the kali container, e.g. to look at a login portal, an admin panel or a defaced page. the image is returned to you if your model can see images and is saved as evidence either way. only targets in the engagement's scope can be captured.",
+		Parameters: map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "http or https URL of the page",
+			},
+			"width": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("width of the browser window in pixels, %d by default", defaultScreenshotWidth),
+			},
+			"height": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("height of the browser window in pixels, %d by default", defaultScreenshotHeight),
+			},
+			"wait": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("seconds the page gets to run its scripts before it's captured, %d by default and at most %d", defaultScreenshotWait, maxScreenshotWait),
+			},
+		},
+		Required: []string{"url"},
+	}
+}
+
+func (t *ScreenshotTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args ScreenshotArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse screenshot arguments: " + err.Error()), nil
+	}
+	target, err := url.Parse(args.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return NewTextErrorResponse(fmt.Sprintf("%q isn't an http or https URL", args.URL)), nil
+	}
+	if args.Width <= 0 {
+		args.Width = defaultScreenshotWidth
+	}
+	if args.Height <= 0 {
+		args.Height = defaultScreenshotHeight
+	}
+	if args.Width > maxScreenshotSide || args.Height > maxScreenshotSide {
+		return NewTextErrorResponse(fmt.Sprintf("the window can be at most %dx%d pixels", maxScreenshotSide, maxScreenshotSide)), nil
+	}
+	if args.Wait <= 0 {
+		args.Wait = defaultScreenshotWait
+	}
+	args.Wait = min(args.Wait, maxScreenshotWait)
+
+	engagementScope, err := scope.FromConfig()
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid scope: %s", err)), nil
+	}
+	if err := engagementScope.Check(ctx, target.Host); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("refusing to take the screenshot: %s", err)), nil
+	}
+
+	executor, containerID, err := fileToolContainer(ctx)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	shot := fmt.Sprintf("/tmp/tandem-screenshot-%s.png", call.ID)
+	// NOTE: chromium runs as root in the container, which it only agrees to without its sandbox.
+	metadata, err := terminal.execute(ctx, call.ID, []string{
+		"chromium", "--headless", "--no-sandbox", "--disable-gpu", "--disable-dev-shm-usage", "--hide-scrollbars",
+		"--ignore-certificate-errors",
+		fmt.Sprintf("--window-size=%d,%d", args.Width, args.Height),
+		fmt.Sprintf("--virtual-time-budget=%d", args.Wait*1000),
+		"--screenshot=" + shot,
+		target.String(),
+	}, screenshotTimeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	defer executor.Exec(context.Background(), ExecRequest{ID: call.ID + "-cleanup", Command: []string{"rm", "-f", shot}}, io.Discard, io.Discard)
+
+	image, err := readContainerFile(ctx, executor, containerID, shot)
+	if err != nil || !bytes.HasPrefix(image, pngSignature) {
+		return NewTextErrorResponse(fmt.Sprintf("chromium didn't produce a screenshot of %s:\n%s", target, formatTerminalOutput(metadata))), nil
+	}
+
+	dir := config.EvidenceDir()
+	if err := os.MkdirAll(dir, evidenceDirMode); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to create the evidence directory: %s", err)), nil
+	}
+	evidence := filepath.Join(dir, time.Now().Format("20060102-150405")+"-"+call.ID+".png")
+	if err := os.WriteFile(evidence, image, 0o644); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to save the screenshot as evidence: %s", err)), nil
+	}
+	sum := sha256.Sum256(image)
+
+	content := fmt.Sprintf("screenshot of %s at %dx%d, saved as evidence to %s (sha256 %s)", target, args.Width, args.Height, evidence, hex.EncodeToString(sum[:]))
+	return WithResponseMetadata(NewImageResponse(content, "image/png", image), ScreenshotResponseMetadata{
+		URL:      target.String(),
+		Width:    args.Width,
+		Height:   args.Height,
+		Evidence: evidence,
+		SHA256:   hex.EncodeToString(sum[:]),
+	}), nil
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/logging"
+)
+
+// placeholderPattern matches the {{name}} placeholders of a command template. the credential handles like {{cred:3}}
+// don't match, they are left for the terminal to substitute.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// scriptTool runs the command template of a script tool declared in swarm.json.
+type scriptTool struct {
+	name       string
+	definition config.ScriptTool
+	segments   []scriptSegment
+}
+
+// scriptSegment is either a literal part of the template or a placeholder, quoted when it's within double quotes.
+type scriptSegment struct {
+	literal string
+	param   string
+	quoted  bool
+}
+
+var scriptTools struct {
+	sync.Once
+	tools []BaseTool
+}
+
+// ScriptToolsFor returns the script tools an agent lists in its tools. the broken ones are reported once and skipped.
+func ScriptToolsFor(agentName config.AgentName) []BaseTool {
+	cfg := config.Get()
+	if cfg == nil || len(cfg.ScriptTools) == 0 {
+		return nil
+	}
+	scriptTools.Do(func() {
+		names := make([]string, 0, len(cfg.ScriptTools))
+		for name := range cfg.ScriptTools {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			tool, err := newScriptTool(name, cfg.ScriptTools[name])
+			if err != nil {
+				logging.ErrorPersist(fmt.Sprintf("script tool %s: %v", name, err))
+				continue
+			}
+			scriptTools.tools = append(scriptTools.tools, tool)
+		}
+	})
+
+	wanted := cfg.Agents[agentName].Tools
+	var assigned []BaseTool
+	for _, tool := range scriptTools.tools {
+		// NOTE: viper lowercases the names of the tools, the agents may list them as they were written.
+		if slices.ContainsFunc(wanted, func(name string) bool { return strings.EqualFold(name, tool.Info().Name) }) {
+			assigned = append(assigned, tool)
+		}
+	}
+	return assigned
+}
+
+func newScriptTool(name string, definition config.ScriptTool) (*scriptTool, error) {
+	for _, tool := range PenetrationTestingAgentTools {
+		if tool.Info().Name == name {
+			return nil, fmt.Errorf("the name is taken by a built-in tool")
+		}
+	}
+	properties, _ := definition.Parameters["properties"].(map[string]any)
+	segments, err := parseScriptTemplate(definition.Command, properties)
+	if err != nil {
+		return nil, err
+	}
+	return &scriptTool{name: name, definition: definition, segments: segments}, nil
+}
+
+// parseScriptTemplate splits the command at its placeholders and keeps track of the shell quoting around them. the
+// values are handed to the shell as positional parameters, a placeholder within single quotes would never expand.
+func parseScriptTemplate(command string, properties map[string]any) ([]scriptSegment, error) {
+	var segments []scriptSegment
+	var quote byte
+	escaped := false
+	start := 0
+	matches := placeholderPattern.FindAllStringSubmatchIndex(command, -1)
+	for i := 0; i < len(command); {
+		if len(matches) > 0 && i == matches[0][0] {
+			name := command[matches[0][2]:matches[0][3]]
+			property, ok := properties[name].(map[string]any)
+			switch {
+			case !ok:
+				return nil, fmt.Errorf("{{%s}} isn't one of the parameters", name)
+			case quote == '\'':
+				return nil, fmt.Errorf("{{%s}} is within single quotes, use double quotes around it", name)
+			case property["type"] == "object":
+				return nil, fmt.Errorf("{{%s}} is an object, only strings, numbers, booleans and arrays of them can be substituted", name)
+			case property["type"] == "array" && quote == '"':
+				return nil, fmt.Errorf("{{%s}} is an array, it can't be within quotes", name)
+			}
+			segments = append(segments, scriptSegment{literal: command[start:i]}, scriptSegment{param: name, quoted: quote == '"'})
+			i = matches[0][1]
+			start = i
+			matches = matches[1:]
+			continue
+		}
+
+		c := command[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case quote != 0 && c == quote:
+			quote = 0
+		}
+		i++
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("the command has an unterminated %c quote", quote)
+	}
+	return append(segments, scriptSegment{literal: command[start:]}), nil
+}
+
+func (t *scriptTool) Info() ToolInfo {
+	properties, _ := t.definition.Parameters["properties"].(map[string]any)
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	required := schemaStrings(t.definition.Parameters["required"])
+	if required == nil {
+		required = []string{}
+	}
+	description := t.definition.Description
+	if description == "" {
+		description = fmt.Sprintf("Runs %s", t.definition.Command)
+	}
+	return ToolInfo{
+		Name:        t.name,
+		Description: description,
+		Parameters:  properties,
+		Required:    required,
+	}
+}
+
+func (t *scriptTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	arguments := map[string]any{}
+	if strings.TrimSpace(call.Input) != "" {
+		if err := json.Unmarshal([]byte(call.Input), &arguments); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("failed to parse %s arguments: %s", t.name, err)), nil
+		}
+	}
+	if err := validateArguments(t.definition.Parameters, arguments); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid %s arguments: %s", t.name, err)), nil
+	}
+	cmd, display, err := t.render(arguments)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("invalid %s arguments: %s", t.name, err)), nil
+	}
+
+	timeout := DefaultTerminalTimeout
+	if t.definition.Timeout > 0 {
+		timeout = time.Duration(t.definition.Timeout) * time.Second
+	}
+	metadata, err := terminal.execute(ctx, call.ID, cmd, timeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	metadata.Command = display
+
+	output := formatTerminalOutput(metadata)
+	logging.Debug(fmt.Sprintf("script tool output (%s, exit code %d): %s", display, metadata.ExitCode, truncateForLog(output)))
+	return WithResponseMetadata(ToolResponse{
+		Type:    ToolResponseTypeText,
+		Content: output,
+		IsError: metadata.ExitCode != 0,
+	}, metadata), nil
+}
+
+// render builds the command run for the arguments along with how it's shown. the template becomes the script of
+// sh -c and every value one of its positional parameters, so no value is ever parsed by the shell.
+func (t *scriptTool) render(arguments map[string]any) ([]string, string, error) {
+	properties, _ := t.definition.Parameters["properties"].(map[string]any)
+	var script, display strings.Builder
+	var positional []string
+	for _, segment := range t.segments {
+		if segment.param == "" {
+			script.WriteString(segment.literal)
+			display.WriteString(segment.literal)
+			continue
+		}
+		value, ok := arguments[segment.param]
+		if !ok || value == nil {
+			property, _ := properties[segment.param].(map[string]any)
+			value = property["default"]
+		}
+		values, err := scriptValues(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s %s", segment.param, err)
+		}
+
+		refs := make([]string, len(values))
+		for i, value := range values {
+			positional = append(positional, value)
+			refs[i] = fmt.Sprintf("${%d}", len(positional))
+		}
+		if segment.quoted {
+			script.WriteString(strings.Join(refs, ""))
+			display.WriteString(strings.Join(values, ""))
+			continue
+		}
+		for i, ref := range refs {
+			refs[i] = `"` + ref + `"`
+		}
+		script.WriteString(strings.Join(refs, " "))
+		if len(values) > 0 {
+			display.WriteString(shellQuote(values))
+		}
+	}
+	return append([]string{"sh", "-c", script.String(), t.name}, positional...), display.String(), nil
+}
+
+// scriptValues turns an argument into the strings substituted for its placeholder, none for a missing one.
+func scriptValues(value any) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}, nil
+	case bool:
+		return []string{strconv.FormatBool(value)}, nil
+	case []any:
+		var values []string
+		for _, item := range value {
+			if _, nested := item.([]any); nested {
+				return nil, fmt.Errorf("can't hold nested arrays")
+			}
+			itemValues, err := scriptValues(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, itemValues...)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("can only be a string, a number, a boolean or an array of them")
+}
@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/yyovil/tandem/internal/config"
+)
+
+func scriptParameters(t *testing.T, schema string) map[string]any {
+	t.Helper()
+	var parameters map[string]any
+	if err := json.Unmarshal([]byte(schema), &parameters); err != nil {
+		t.Fatal(err)
+	}
+	return parameters
+}
+
+func TestScriptToolTemplate(t *testing.T) {
+	parameters := scriptParameters(t, `{
+		"type": "object",
+		"properties": {
+			"target": {"type": "string"},
+			"ports": {"type": "array", "items": {"type": "integer"}},
+			"details": {"type": "object"}
+		}
+	}`)
+	tests := []struct {
+		command string
+		valid   bool
+	}{
+		{"enum4linux-ng -A {{target}}", true},
+		{`echo "scanning {{ target }}" && nmap -p {{ports}} {{target}}`, true},
+		{"echo '{{target}}'", false},
+		{`echo "{{ports}}"`, false},
+		{"echo {{details}}", false},
+		{"echo {{unknown}}", false},
+		{`echo "{{target}}`, false},
+		{"hydra -p {{cred:3}} {{target}}", true},
+	}
+	for _, test := range tests {
+		_, err := newScriptTool("enum", config.ScriptTool{Command: test.command, Parameters: parameters})
+		if test.valid && err != nil {
+			t.Errorf("%s: expected it to be valid, got %v", test.command, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected it to be rejected", test.command)
+		}
+	}
+}
+
+func TestScriptToolArguments(t *testing.T) {
+	parameters := scriptParameters(t, `{
+		"type": "object",
+		"properties": {
+			"target": {"type": "string", "pattern": "^[a-z0-9.:-]+$"},
+			"mode": {"type": "string", "enum": ["fast", "full"], "default": "fast"},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535}
+		},
+		"required": ["target"]
+	}`)
+	tests := []struct {
+		arguments string
+		valid     bool
+	}{
+		{`{"target": "10.10.10.5"}`, true},
+		{`{"target": "10.10.10.5", "mode": "full", "port": 445}`, true},
+		{`{}`, false},
+		{`{"target": "10.10.10.5; id"}`, false},
+		{`{"target": "10.10.10.5", "mode": "slow"}`, false},
+		{`{"target": "10.10.10.5", "port": 0}`, false},
+		{`{"target": "10.10.10.5", "port": 4.5}`, false},
+		{`{"target": "10.10.10.5", "extra": true}`, false},
+	}
+	for _, test := range tests {
+		var arguments map[string]any
+		if err := json.Unmarshal([]byte(test.arguments), &arguments); err != nil {
+			t.Fatal(err)
+		}
+		err := validateArguments(parameters, arguments)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected it to be valid, got %v", test.arguments, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected it to be rejected", test.arguments)
+		}
+	}
+}
+
+func TestScriptToolRender(t *testing.T) {
+	tool, err := newScriptTool("echo", config.ScriptTool{
+		Command: `printf '%s\n' {{word}} "quoted: {{word}}" {{words}} {{mode}} {{missing}}`,
+		Parameters: scriptParameters(t, `{
+			"type": "object",
+			"properties": {
+				"word": {"type": "string"},
+				"words": {"type": "array", "items": {"type": "string"}},
+				"mode": {"type": "string", "default": "fast"},
+				"missing": {"type": "string"}
+			}
+		}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	injection := `$(echo pwned) "; echo pwned; '`
+	cmd, display, err := tool.render(map[string]any{"word": injection, "words": []any{"a b", "`id`"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(display, "'a b'") {
+		t.Errorf("expected the values to be quoted in %s", display)
+	}
+
+	out, err := exec.Command(cmd[0], cmd[1:]...).Output()
+	if err != nil {
+		t.Skip("sh is not available: " + err.Error())
+	}
+	want := strings.Join([]string{injection, "quoted: " + injection, "a b", "`id`", "fast"}, "\n") + "\n"
+	if string(out) != want {
+		t.Fatalf("expected the values to be passed verbatim, got %q want %q", out, want)
+	}
+}
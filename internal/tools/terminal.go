@@ -1,69 +1,125 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"maps"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/client"
+	"github.com/yyovil/tandem/internal/config"
 	"github.com/yyovil/tandem/internal/logging"
+	"github.com/yyovil/tandem/internal/scope"
 )
 
 const (
 	TerminalToolName = "terminal"
-	DockerImage      = "kali:headless"
+
+	// DefaultTerminalTimeout is applied when the model doesn't ask for a timeout explicitly.
+	DefaultTerminalTimeout = 10 * time.Minute
 )
 
 type TerminalArgs struct {
 	Command string   `json:"command"`
 	Args    []string `json:"args,omitempty"`
+	Timeout int      `json:"timeout,omitempty"` // seconds
+}
+
+// TerminalResponseMetadata is attached to every terminal tool response so that the TUI can render the exit status and timing of a command.
+type TerminalResponseMetadata struct {
+	Command     string `json:"command"`
+	Executor    string `json:"executor"`
+	ContainerID string `json:"container_id,omitempty"`
+	ExitCode    int    `json:"exit_code"`
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	StartedAt   int64  `json:"started_at"`  // Unix timestamp in milliseconds
+	Duration    int64  `json:"duration_ms"` // wall clock time in milliseconds
 }
 
 type Terminal struct {
-	hijackedResponse *types.HijackedResponse
-	client           *client.Client
-	init             sync.Once
-	initErr          error
-	containerId      string
+	halted atomic.Bool
+
+	// NOTE: executors are created on first use because the tools are instantiated before the config is loaded.
+	mu        sync.Mutex
+	executors map[string]Executor
 }
 
 var terminal *Terminal
 
-func (term *Terminal) initialise() error {
-	term.init.Do(func() {
-		term.client, term.initErr = client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-		if term.initErr != nil {
-			term.initErr = fmt.Errorf("failed to create docker client: %w", term.initErr)
-		}
-	})
+// executorFor returns the executor of the agent that is running the command.
+func (term *Terminal) executorFor(ctx context.Context) (string, Executor, error) {
+	agentName, _ := ctx.Value(AgentNameContextKey).(string)
+	name, definition := config.ExecutorFor(config.AgentName(agentName))
+	executor, err := term.executor(name, definition)
+	return name, executor, err
+}
+
+func (term *Terminal) executor(name string, definition config.Executor) (Executor, error) {
+	term.mu.Lock()
+	defer term.mu.Unlock()
+
+	if executor, ok := term.executors[name]; ok {
+		return executor, nil
+	}
+	executor, err := newExecutor(name, definition)
+	if err != nil {
+		return nil, err
+	}
+	term.executors[name] = executor
+	return executor, nil
+}
+
+// activeExecutors returns the executors that have been used so far.
+func (term *Terminal) activeExecutors() map[string]Executor {
+	term.mu.Lock()
+	defer term.mu.Unlock()
+	return maps.Clone(term.executors)
+}
 
-	return term.initErr
+// ReleaseSession removes the isolated containers of a finished task session. it's a no-op unless the isolation level is per session.
+func ReleaseSession(ctx context.Context, sessionID string) error {
+	if terminal == nil || config.Get() == nil || config.Get().Container.Isolation != config.IsolationSession {
+		return nil
+	}
+	var errs []error
+	for _, executor := range terminal.activeExecutors() {
+		if executor, ok := executor.(*containerExecutor); ok {
+			if err := executor.releaseSession(ctx, sessionID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// Client returns the APIClient
-func Client() *client.Client {
-	if err := terminal.initialise(); err != nil {
-		logging.Error("initialisation failed", err)
+// ReleaseSessions removes the isolated containers of every session, the ones of the top-level sessions included. it's
+// a no-op unless the isolation level is per session.
+func ReleaseSessions(ctx context.Context) error {
+	if terminal == nil || config.Get() == nil || config.Get().Container.Isolation != config.IsolationSession {
+		return nil
+	}
+	var errs []error
+	for _, executor := range terminal.activeExecutors() {
+		if executor, ok := executor.(*containerExecutor); ok {
+			if err := executor.releaseSessions(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
-	return terminal.client
+	return errors.Join(errs...)
 }
 
 func NewDockerCli() BaseTool {
 	terminal = &Terminal{
-		init:             sync.Once{},
-		initErr:          nil,
-		containerId:      "",
-		hijackedResponse: nil,
+		executors: make(map[string]Executor),
 	}
 
-	terminal.client = Client()
-
 	return terminal
 }
 
@@ -84,6 +140,10 @@ func (term *Terminal) Info() ToolInfo {
 					"description": "argument for the command",
 				},
 			},
+			"timeout": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("seconds after which the command and all of its child processes are killed. defaults to %d.", int(DefaultTerminalTimeout.Seconds())),
+			},
 		},
 		Required: []string{"command", "args"},
 	}
@@ -99,94 +159,142 @@ func (term *Terminal) Run(ctx context.Context, call ToolCall) (ToolResponse, err
 		return NewTextErrorResponse("command is required for DockerCli tool"), nil
 	}
 
+	timeout := DefaultTerminalTimeout
+	if args.Timeout > 0 {
+		timeout = time.Duration(args.Timeout) * time.Second
+	}
+
 	// Build command slice (no trailing newline; we will exec directly)
 	cmd := []string{args.Command}
 	if len(args.Args) != 0 {
 		cmd = append(cmd, args.Args...)
 	}
 
-	if term.containerId == "" {
-		summaries, err := term.client.ContainerList(ctx, container.ListOptions{
-			All:     true,
-			Filters: filters.NewArgs(filters.Arg("ancestor", DockerImage)),
-		})
-		if err != nil {
-			return NewTextErrorResponse("Failed to list containers: " + err.Error()), nil
-		}
+	metadata, err := term.execute(ctx, call.ID, cmd, timeout)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
-		for _, summary := range summaries {
-			if summary.Image == DockerImage && summary.State == container.StateRunning {
-				term.containerId = summary.ID
-				break
-			}
+	output := formatTerminalOutput(metadata)
+	logging.Debug(fmt.Sprintf("terminal exec output (%s, exit code %d): %s", metadata.Command, metadata.ExitCode, truncateForLog(output)))
 
-			if summary.Image == DockerImage {
-				term.containerId = summary.ID
-				break
-			}
-		}
-	}
+	return WithResponseMetadata(ToolResponse{
+		Type:    ToolResponseTypeText,
+		Content: output,
+		IsError: metadata.ExitCode != 0,
+	}, metadata), nil
+}
 
-	// NOTE: we are not creating a container if not found in the summaries because it should be created during the installation.
-	if term.containerId == "" {
-		return NewTextErrorResponse(fmt.Sprintf("couldn't find a container using %s image.", DockerImage)), nil
+// scopedTerminal is the terminal handed to callers outside of the swarm, e.g. an MCP client. commands naming a target
+// outside of the engagement's scope are refused before they reach the executor.
+type scopedTerminal struct {
+	*Terminal
+}
+
+func NewScopedTerminal() BaseTool {
+	return &scopedTerminal{Terminal: terminal}
+}
+
+func (term *scopedTerminal) Info() ToolInfo {
+	info := term.Terminal.Info()
+	info.Description += " commands naming a host, address or network outside of the engagement's scope are refused."
+	return info
+}
+
+func (term *scopedTerminal) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args TerminalArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("Failed to parse docker cli arguments: " + err.Error()), nil
 	}
 
-	inspectRes, err := term.client.ContainerInspect(ctx, term.containerId)
+	engagementScope, err := scope.FromConfig()
 	if err != nil {
-		return NewTextErrorResponse("Failed to inspect container: " + err.Error()), nil
+		return NewTextErrorResponse(fmt.Sprintf("invalid scope: %s", err)), nil
 	}
+	if err := engagementScope.CheckCommand(ctx, append([]string{args.Command}, args.Args...)); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("refusing to run the command: %s", err)), nil
+	}
+	return term.Terminal.Run(ctx, call)
+}
 
-	if !inspectRes.State.Running {
-		if err := term.GetRunning(ctx, term.containerId, inspectRes.State.Status); err != nil {
-			return NewTextErrorResponse(fmt.Sprintf("couldn't get the container: %s running.", term.containerId)), nil
-		}
+// execute runs cmd on the executor of the calling agent and kills its process tree once the timeout is up.
+// the returned errors are worded for the model.
+func (term *Terminal) execute(ctx context.Context, id string, cmd []string, timeout time.Duration) (TerminalResponseMetadata, error) {
+	if term.halted.Load() {
+		return TerminalResponseMetadata{}, fmt.Errorf("command execution is halted by the operator (emergency stop). wait for the operator to resume.")
 	}
 
-	// Execute the command inside the container (avoids interactive TTY read loop issues)
-	execResp, err := term.client.ContainerExecCreate(ctx, term.containerId, container.ExecOptions{
-		AttachStdout: true,
-		AttachStderr: true,
-		Cmd:          cmd,
-	})
-	if err != nil {
-		return NewTextErrorResponse("Failed to create exec: " + err.Error()), nil
+	if config.Get() == nil {
+		return TerminalResponseMetadata{}, fmt.Errorf("couldn't find a container for the engagement: config not loaded")
 	}
 
-	attachResp, err := term.client.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{})
+	executorName, executor, err := term.executorFor(ctx)
 	if err != nil {
-		return NewTextErrorResponse("Failed to attach exec: " + err.Error()), nil
+		return TerminalResponseMetadata{}, fmt.Errorf("couldn't set up the %s executor: %s", executorName, err)
 	}
-	defer attachResp.Close()
 
-	outputBytes, err := io.ReadAll(attachResp.Reader)
+	// NOTE: the secrets only exist in the command handed to the executor, the model keeps seeing the handles.
+	resolved, err := substituteCredentials(ctx, cmd)
 	if err != nil {
-		return NewTextErrorResponse("Failed to read exec output: " + err.Error()), nil
+		return TerminalResponseMetadata{}, err
 	}
 
-	output := string(outputBytes)
-	logging.Debug(fmt.Sprintf("terminal exec output (%s): %s", strings.Join(cmd, " "), truncateForLog(output)))
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	return ToolResponse{
-		Type:    ToolResponseTypeText,
-		Content: output,
-		IsError: false,
+	var stdout, stderr bytes.Buffer
+	startedAt := time.Now()
+	result, err := executor.Exec(execCtx, ExecRequest{ID: id, Command: resolved}, &stdout, &stderr)
+	switch {
+	case ctx.Err() != nil:
+		return TerminalResponseMetadata{}, fmt.Errorf("command cancelled, killed its process tree.\n%s", formatPartialOutput(MaskSecrets(stdout.String()), MaskSecrets(stderr.String())))
+	case errors.Is(err, context.DeadlineExceeded):
+		return TerminalResponseMetadata{}, fmt.Errorf("command timed out after %s, killed its process tree.\n%s", timeout, formatPartialOutput(MaskSecrets(stdout.String()), MaskSecrets(stderr.String())))
+	case err != nil:
+		return TerminalResponseMetadata{}, errors.New(MaskSecrets(err.Error()))
+	}
+
+	return TerminalResponseMetadata{
+		Command:     strings.Join(cmd, " "),
+		Executor:    executorName,
+		ContainerID: result.ContainerID,
+		ExitCode:    result.ExitCode,
+		Stdout:      MaskSecrets(stdout.String()),
+		Stderr:      MaskSecrets(stderr.String()),
+		StartedAt:   startedAt.UnixMilli(),
+		Duration:    time.Since(startedAt).Milliseconds(),
 	}, nil
 }
 
-// NOTE: GetRunning gets a docker container to container.StateRunning.
-func (term *Terminal) GetRunning(ctx context.Context, containerId string, currentState container.ContainerState) error {
-	switch currentState {
-	case container.StateExited, container.StateCreated:
-		if err := term.client.ContainerStart(ctx, containerId, container.StartOptions{}); err != nil {
-			return fmt.Errorf("failed to start container %s: %w", containerId, err)
+// formatTerminalOutput renders the demuxed streams for the model. stderr and a non-zero exit code are called out explicitly so that a failed scan can be told apart from an empty one.
+func formatTerminalOutput(metadata TerminalResponseMetadata) string {
+	var sb strings.Builder
+	sb.WriteString(metadata.Stdout)
+	if metadata.Stderr != "" {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
 		}
-	case container.StatePaused:
-		if err := term.client.ContainerUnpause(ctx, containerId); err != nil {
-			return fmt.Errorf("failed to unpause container %s: %w", containerId, err)
+		sb.WriteString("<stderr>\n")
+		sb.WriteString(metadata.Stderr)
+		if !strings.HasSuffix(metadata.Stderr, "\n") {
+			sb.WriteString("\n")
 		}
+		sb.WriteString("</stderr>\n")
+	}
+	if metadata.ExitCode != 0 {
+		fmt.Fprintf(&sb, "command exited with code %d", metadata.ExitCode)
+	} else if sb.Len() == 0 {
+		sb.WriteString("command exited with code 0 and produced no output")
+	}
+	return sb.String()
+}
+
+// formatPartialOutput renders whatever the command printed before it was stopped.
+func formatPartialOutput(stdout, stderr string) string {
+	if stdout == "" && stderr == "" {
+		return "no output was produced before it was stopped."
 	}
-	return nil
+	return formatTerminalOutput(TerminalResponseMetadata{Stdout: stdout, Stderr: stderr})
 }
 
 func truncateForLog(s string) string {
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/yyovil/tandem/internal/config"
 )
 
 // Integration-style test for DockerCli.Run executing an nmap command inside the kali:withtools container.
@@ -75,3 +77,20 @@ func TestDockerCliRunNmap(t *testing.T) {
 		}
 	}
 }
+
+func TestReachesTargets(t *testing.T) {
+	script, err := newScriptTool("enum", config.ScriptTool{Command: "enum4linux-ng -A 10.10.10.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range Audited(&Terminal{}, &scopedTerminal{Terminal: &Terminal{}}, NewNmapTool(nil), NewHTTPRequestTool(), NewScreenshotTool(), script, &mcpTool{server: &mcpServer{name: "burp"}}) {
+		if !ReachesTargets(tool) {
+			t.Errorf("expected %s to reach the targets", tool.Info().Name)
+		}
+	}
+	for _, tool := range Audited(NewReadFileTool(), NewListFilesTool(), NewCVELookupTool(), NewSearchHistoryTool(nil), NewStoreCredentialTool(nil)) {
+		if ReachesTargets(tool) {
+			t.Errorf("expected %s to stay off the targets", tool.Info().Name)
+		}
+	}
+}
@@ -18,6 +18,7 @@ type toolResponseType string
 type (
 	sessionIDContextKey string
 	messageIDContextKey string
+	agentNameContextKey string
 )
 
 const (
@@ -26,6 +27,7 @@ const (
 
 	SessionIDContextKey sessionIDContextKey = "session_id"
 	MessageIDContextKey messageIDContextKey = "message_id"
+	AgentNameContextKey agentNameContextKey = "agent_name"
 )
 
 type ToolResponse struct {
@@ -33,6 +35,9 @@ type ToolResponse struct {
 	Content  string           `json:"content"`
 	Metadata string           `json:"metadata,omitempty"`
 	IsError  bool             `json:"is_error"`
+	// MIMEType and Data hold the image of an image response, Content describes it for models that can't see it.
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`
 }
 
 func NewTextResponse(content string) ToolResponse {
@@ -42,6 +47,15 @@ func NewTextResponse(content string) ToolResponse {
 	}
 }
 
+func NewImageResponse(content, mimeType string, data []byte) ToolResponse {
+	return ToolResponse{
+		Type:     ToolResponseTypeImage,
+		Content:  content,
+		MIMEType: mimeType,
+		Data:     data,
+	}
+}
+
 func WithResponseMetadata(response ToolResponse, metadata any) ToolResponse {
 	if metadata != nil {
 		metadataBytes, err := json.Marshal(metadata)
@@ -84,7 +98,32 @@ func GetContextValues(ctx context.Context) (string, string) {
 	return sessionID.(string), messageID.(string)
 }
 
+// ReachesTargets tells whether a tool sends anything to the targets of the engagement, the testing windows only hold
+// these. the script and MCP tools are assumed to, there's no telling what they do.
+func ReachesTargets(tool BaseTool) bool {
+	if audited, ok := tool.(auditedTool); ok {
+		tool = audited.BaseTool
+	}
+	switch tool.(type) {
+	case *scriptTool, *mcpTool:
+		return true
+	}
+	switch tool.Info().Name {
+	case TerminalToolName, NmapToolName, HTTPRequestToolName, ScreenshotToolName:
+		return true
+	}
+	return false
+}
+
 // NOTE: concatenate it with the role specific tools
 var PenetrationTestingAgentTools = []BaseTool{
 	NewDockerCli(),
+	NewReadFileTool(),
+	NewWriteFileTool(),
+	NewListFilesTool(),
+	NewCopyOutTool(),
+	NewHTTPRequestTool(),
+	NewCVELookupTool(),
+	NewExploitSearchTool(),
+	NewScreenshotTool(),
 }
@@ -0,0 +1,338 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyovil/tandem/internal/vault"
+)
+
+const (
+	StoreCredentialToolName = "store_credential"
+	ListCredentialsToolName = "list_credentials"
+)
+
+// credentials is the vault the terminal resolves the credential handles with, commands run as written without one.
+var credentials vault.Service
+
+// UseVault lets the terminal substitute the credential handles in commands and mask the secrets in their output.
+func UseVault(v vault.Service) {
+	credentials = v
+}
+
+// withheldSecret stands in for the secret given to store_credential when it can't be replaced with its handle.
+const withheldSecret = "[withheld]"
+
+// MaskSecrets replaces the secrets of the vault with their handles before anything reaches the model or the logs.
+func MaskSecrets(s string) string {
+	if credentials == nil {
+		return s
+	}
+	return credentials.Mask(s)
+}
+
+// MaskInput masks the secrets of the vault in the input of a tool call, it's returned as it is when there's nothing to
+// mask. the secret given to store_credential is withheld even when the vault doesn't know it, it wasn't stored or is too
+// short to be masked.
+func MaskInput(name, input string) string {
+	var v any
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		if name == StoreCredentialToolName {
+			return "{}"
+		}
+		return MaskSecrets(input)
+	}
+	masked := false
+	v = maskValue(v, &masked)
+	if args, ok := v.(map[string]any); ok && name == StoreCredentialToolName {
+		if secret, ok := args["secret"].(string); ok && vault.HandlePattern.FindString(secret) != secret {
+			args["secret"], masked = withheldSecret, true
+		}
+	}
+	if !masked {
+		return input
+	}
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return MaskSecrets(input)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func maskValue(v any, masked *bool) any {
+	switch v := v.(type) {
+	case string:
+		s := MaskSecrets(v)
+		*masked = *masked || s != v
+		return s
+	case []any:
+		for i := range v {
+			v[i] = maskValue(v[i], masked)
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = maskValue(v[key], masked)
+		}
+	}
+	return v
+}
+
+type StoreCredentialArgs struct {
+	Kind     string `json:"kind,omitempty"`
+	Username string `json:"username,omitempty"`
+	Secret   string `json:"secret"`
+	Target   string `json:"target,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+type CredentialResponseMetadata struct {
+	Handle   string `json:"handle"`
+	Kind     string `json:"kind"`
+	Username string `json:"username,omitempty"`
+	Target   string `json:"target,omitempty"`
+}
+
+type storeCredentialTool struct {
+	vault vault.Service
+}
+
+type listCredentialsTool struct {
+	vault vault.Service
+}
+
+func NewStoreCredentialTool(vault vault.Service) BaseTool {
+	return &storeCredentialTool{vault: vault}
+}
+
+func NewListCredentialsTool(vault vault.Service) BaseTool {
+	return &listCredentialsTool{vault: vault}
+}
+
+func (t *storeCredentialTool) Info() ToolInfo {
+	kinds := make([]string, len(vault.Kinds))
+	for i, kind := range vault.Kinds {
+		kinds[i] = string(kind)
+	}
+	return ToolInfo{
+		Name:        StoreCredentialToolName,
+		Description: "Store a discovered credential in the encrypted vault of the engagement and get back a handle like {{cred:3}} for it. use the handle instead of the secret in terminal commands, it's replaced with the secret right before the command runs, quoted for the shell when it's within the script of sh -c or bash -c. the secret is masked with its handle in all command output from then on.",
+		Parameters: map[string]any{
+			"kind": map[string]any{
+				"type":        "string",
+				"description": "what the secret is, password by default",
+				"enum":        kinds,
+			},
+			"username": map[string]any{
+				"type":        "string",
+				"description": "the user the secret belongs to, if any",
+			},
+			"secret": map[string]any{
+				"type":        "string",
+				"description": "the password, hash, token, key or cookie itself",
+			},
+			"target": map[string]any{
+				"type":        "string",
+				"description": "where the credential works, e.g. ssh://10.10.10.5, https://app.lab/login or the domain",
+			},
+			"notes": map[string]any{
+				"type":        "string",
+				"description": "where it was found and anything else worth knowing about it",
+			},
+		},
+		Required: []string{"secret"},
+	}
+}
+
+func (t *storeCredentialTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var args StoreCredentialArgs
+	if err := json.Unmarshal([]byte(call.Input), &args); err != nil {
+		return NewTextErrorResponse("failed to parse store_credential arguments: " + err.Error()), nil
+	}
+	if args.Secret == "" {
+		return NewTextErrorResponse("secret is required"), nil
+	}
+	kind := vault.Kind(strings.ToLower(strings.TrimSpace(args.Kind)))
+	if kind != "" && !slices.Contains(vault.Kinds, kind) {
+		return NewTextErrorResponse(fmt.Sprintf("unknown credential kind %q", args.Kind)), nil
+	}
+
+	agentName, _ := ctx.Value(AgentNameContextKey).(string)
+	credential, err := t.vault.Store(ctx, vault.Credential{
+		Kind:     kind,
+		Username: args.Username,
+		Target:   args.Target,
+		Notes:    args.Notes,
+		Source:   agentName,
+	}, args.Secret)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	response := fmt.Sprintf("stored as %s", credential.Handle())
+	if credential.Username != "" {
+		response += fmt.Sprintf(", the username is %s", credential.Username)
+	}
+	return WithResponseMetadata(NewTextResponse(response), credentialMetadata(credential)), nil
+}
+
+func (t *listCredentialsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ListCredentialsToolName,
+		Description: "List the credentials in the vault of the engagement with their handles, kinds, usernames and targets. the secrets themselves are never shown, use the handles in terminal commands instead.",
+		Parameters:  map[string]any{},
+		Required:    []string{},
+	}
+}
+
+func (t *listCredentialsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	stored, err := t.vault.List(ctx)
+	if err != nil {
+		return NewTextErrorResponse("failed to list the credentials: " + err.Error()), nil
+	}
+	if len(stored) == 0 {
+		return NewTextResponse("the vault is empty"), nil
+	}
+
+	metadata := make([]CredentialResponseMetadata, len(stored))
+	var sb strings.Builder
+	for i, credential := range stored {
+		metadata[i] = credentialMetadata(credential)
+		fmt.Fprintf(&sb, "%s  %s", credential.Handle(), credential.Kind)
+		if credential.Username != "" {
+			fmt.Fprintf(&sb, "  user %s", credential.Username)
+		}
+		if credential.Target != "" {
+			fmt.Fprintf(&sb, "  for %s", credential.Target)
+		}
+		if credential.Notes != "" {
+			fmt.Fprintf(&sb, "  (%s)", credential.Notes)
+		}
+		sb.WriteString("\n")
+	}
+	if !t.vault.Unlocked() {
+		sb.WriteString("the vault is locked, the handles can't be used until the operator unlocks it.\n")
+	}
+	return WithResponseMetadata(NewTextResponse(strings.TrimRight(sb.String(), "\n")), metadata), nil
+}
+
+func credentialMetadata(credential vault.Credential) CredentialResponseMetadata {
+	return CredentialResponseMetadata{
+		Handle:   credential.Handle(),
+		Kind:     string(credential.Kind),
+		Username: credential.Username,
+		Target:   credential.Target,
+	}
+}
+
+// substituteCredentials swaps the credential handles in cmd for their secrets. the errors are worded for the model.
+func substituteCredentials(ctx context.Context, cmd []string) ([]string, error) {
+	for _, arg := range cmd {
+		if handle := vault.ForeignHandlePattern.FindString(arg); handle != "" {
+			return nil, fmt.Errorf("%s is a credential of the installation the history was imported from, it isn't in this vault. store it again if the operator hands it over, the command wasn't run", handle)
+		}
+	}
+	if !slices.ContainsFunc(cmd, vault.HandlePattern.MatchString) {
+		return cmd, nil
+	}
+	if credentials == nil {
+		return nil, fmt.Errorf("the command refers to credential handles but there is no credential vault")
+	}
+	// NOTE: a shell parses the secrets that go into the script of sh -c, they're quoted for where the handle sits.
+	cmd = slices.Clone(cmd)
+	var err error
+	for _, i := range shellScripts(cmd) {
+		if cmd[i], err = substituteInScript(ctx, cmd[i]); err != nil {
+			break
+		}
+	}
+	substituted := cmd
+	if err == nil {
+		substituted, err = credentials.Substitute(ctx, cmd)
+	}
+	if errors.Is(err, vault.ErrLocked) {
+		return nil, fmt.Errorf("%s, the command wasn't run", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't substitute the credential handles: %s", err)
+	}
+	return substituted, nil
+}
+
+// shells are the shells whose -c script the credential handles are quoted in.
+var shells = []string{"sh", "bash", "dash", "zsh", "ash", "ksh"}
+
+// shellScripts returns the indexes of the arguments of cmd that a shell runs as a script, the ones following a -c.
+func shellScripts(cmd []string) []int {
+	var scripts []int
+	for i := 2; i < len(cmd); i++ {
+		option := cmd[i-1]
+		if !strings.HasPrefix(option, "-") || strings.HasPrefix(option, "--") || !strings.Contains(option, "c") {
+			continue
+		}
+		if slices.ContainsFunc(cmd[:i-1], func(arg string) bool { return slices.Contains(shells, path.Base(arg)) }) {
+			scripts = append(scripts, i)
+		}
+	}
+	return scripts
+}
+
+// substituteInScript swaps the credential handles in a shell script for their secrets, quoted so that the shell reads
+// them back as they are, within single quotes, double quotes or none.
+// NOTE: the quoting is followed through the script as written, a handle within a command substitution or a here
+// document is quoted as if it weren't in one.
+func substituteInScript(ctx context.Context, script string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, match := range vault.HandlePattern.FindAllStringSubmatchIndex(script, -1) {
+		id, err := strconv.ParseInt(script[match[2]:match[3]], 10, 64)
+		if err != nil {
+			return "", err
+		}
+		secret, err := credentials.Reveal(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(script[last:match[0]])
+		switch shellQuoting(script[:match[0]]) {
+		case '\'':
+			b.WriteString(strings.ReplaceAll(secret, "'", `'\''`))
+		case '"':
+			b.WriteString(strings.NewReplacer(`\`, `\\`, `$`, `\$`, "`", "\\`", `"`, `\"`).Replace(secret))
+		default:
+			b.WriteString(shellQuote([]string{secret}))
+		}
+		last = match[1]
+	}
+	b.WriteString(script[last:])
+	return b.String(), nil
+}
+
+// shellQuoting tells which quotes are open at the end of a piece of a shell script, ' or " or none.
+func shellQuoting(script string) byte {
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		}
+	}
+	return quote
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"os/exec"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/vault"
+)
+
+func TestSubstituteCredentialsInScripts(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	vaultService := vault.NewService(db.New(conn))
+	if err := vaultService.Unlock(ctx, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	UseVault(vaultService)
+	t.Cleanup(func() { UseVault(nil) })
+
+	const secret = "it's $HOME; \"a  b\" \\ `id`"
+	stored, err := vaultService.Store(ctx, vault.Credential{Username: "admin"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle := vault.Handle(stored.ID)
+
+	tests := []struct {
+		script string
+		want   string
+	}{
+		{"printf '%s' " + handle, secret},
+		{"printf '%s' \"pass=" + handle + "\"", "pass=" + secret},
+		{"printf '%s' 'pass=" + handle + "'", "pass=" + secret},
+		{"printf '%s' \"it's\" " + handle + " 'x'\\''y'", "it's" + secret + "x'y"},
+		{"printf '%s' " + handle + handle, secret + secret},
+	}
+	for _, test := range tests {
+		for _, shell := range [][]string{{"sh", "-c"}, {"/bin/sh", "-ec"}} {
+			cmd, err := substituteCredentials(ctx, append(shell, test.script))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := exec.Command(cmd[0], cmd[1:]...).Output()
+			if err != nil {
+				t.Errorf("%s: the script broke: %v", test.script, err)
+				continue
+			}
+			if string(out) != test.want {
+				t.Errorf("%s: expected %q, got %q", test.script, test.want, out)
+			}
+		}
+	}
+
+	// NOTE: outside of a script the secret is an argument of its own, it's substituted as it is.
+	cmd, err := substituteCredentials(ctx, []string{"printf", "%s", handle})
+	if err != nil || cmd[2] != secret {
+		t.Fatalf("expected the secret as it is, got %q, %v", cmd, err)
+	}
+}
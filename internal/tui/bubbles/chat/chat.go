@@ -21,6 +21,17 @@ type SessionSelectedMsg = session.Session
 
 type SessionClearedMsg struct{}
 
+// ForkSessionMsg forks a session at one of its messages.
+type ForkSessionMsg struct {
+	SessionID string
+	MessageID string
+}
+
+// ScrollToMessageMsg scrolls the messages of the selected session to one of them, once they are rendered.
+type ScrollToMessageMsg struct {
+	MessageID string
+}
+
 type EditorFocusMsg bool
 
 func header(width int) string {
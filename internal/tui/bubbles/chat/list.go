@@ -35,6 +35,10 @@ type messagesCmp struct {
 	spinner       spinner.Model
 	rendering     bool
 	attachments   viewport.Model
+	// offsets holds the index of the first ui message of every rendered message.
+	offsets map[string]int
+	// scrollTo is the message to scroll to once the session is rendered, instead of the bottom.
+	scrollTo string
 }
 type renderFinishedMsg struct{}
 
@@ -43,6 +47,7 @@ type MessageKeys struct {
 	PageUp       key.Binding
 	HalfPageUp   key.Binding
 	HalfPageDown key.Binding
+	Fork         key.Binding
 }
 
 var messageKeys = MessageKeys{
@@ -62,6 +67,10 @@ var messageKeys = MessageKeys{
 		key.WithKeys("ctrl+d", "ctrl+d"),
 		key.WithHelp("ctrl+d", "½ page down"),
 	),
+	Fork: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "fork at the last message shown"),
+	),
 }
 
 func (m *messagesCmp) Init() tea.Cmd {
@@ -92,10 +101,27 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport = u
 			cmds = append(cmds, cmd)
 		}
+		if key.Matches(msg, messageKeys.Fork) && m.session.ID != "" && len(m.messages) > 0 {
+			if m.IsAgentWorking() {
+				return m, utils.ReportWarn("Wait for the agent to finish before forking the session")
+			}
+			return m, utils.CmdHandler(ForkSessionMsg{SessionID: m.session.ID, MessageID: m.lastShownMessage()})
+		}
+
+	case ScrollToMessageMsg:
+		m.scrollTo = msg.MessageID
+		if !m.rendering {
+			m.scrollToMessage()
+		}
+		return m, nil
 
 	case renderFinishedMsg:
 		m.rendering = false
-		m.viewport.GotoBottom()
+		if m.scrollTo != "" {
+			m.scrollToMessage()
+		} else {
+			m.viewport.GotoBottom()
+		}
 	case pubsub.Event[session.Session]:
 		if msg.Type == pubsub.UpdatedEvent && msg.Payload.ID == m.session.ID {
 			m.session = msg.Payload
@@ -172,6 +198,7 @@ func (m *messagesCmp) IsAgentWorking() bool {
 // TODO: fix the rendering. it has to do something with the width of the viewport. an extra line appears while rendering the assistant message.
 func (m *messagesCmp) renderView() {
 	m.uiMessages = make([]uiMessage, 0)
+	m.offsets = make(map[string]int)
 	pos := 0
 	baseStyle := styles.BaseStyle()
 	// NOTE: accounts for the padding in the viewport containing all the rendered messages.
@@ -180,6 +207,7 @@ func (m *messagesCmp) renderView() {
 		return
 	}
 	for _, msg := range m.messages {
+		m.offsets[msg.ID] = len(m.uiMessages)
 		switch msg.Role {
 		case message.User:
 			if cache, ok := m.cachedContent[msg.ID]; ok && cache.width == width {
@@ -242,6 +270,62 @@ func (m *messagesCmp) renderView() {
 	)
 }
 
+// scrollToMessage puts the message to scroll to at the top of the viewport. tool results are shown along with the
+// calls they answer, so for those it's the message making the call.
+func (m *messagesCmp) scrollToMessage() {
+	id := m.scrollTo
+	m.scrollTo = ""
+	for _, msg := range m.messages {
+		if msg.ID != id || msg.Role != message.Tool {
+			continue
+		}
+		for _, r := range msg.ToolResults() {
+			for _, v := range m.messages {
+				for _, c := range v.ToolCalls() {
+					if c.ID == r.ToolCallID {
+						id = v.ID
+					}
+				}
+			}
+		}
+	}
+	if line, ok := m.lineOf(id); ok {
+		m.viewport.SetYOffset(line)
+	}
+}
+
+// lineOf is the line of the rendered content a message starts at.
+func (m *messagesCmp) lineOf(id string) (int, bool) {
+	index, ok := m.offsets[id]
+	if !ok {
+		return 0, false
+	}
+	// NOTE: the top padding of the content.
+	line := 1
+	for _, v := range m.uiMessages[:index] {
+		line += lipgloss.Height(v.content)
+	}
+	return line, true
+}
+
+// lastShownMessage is the last message starting above the bottom of the viewport, the one to fork at. scrolled to the
+// bottom, it's the last message of the session.
+func (m *messagesCmp) lastShownMessage() string {
+	bottom := m.viewport.YOffset + m.viewport.Height
+	id := m.messages[0].ID
+	for _, msg := range m.messages {
+		line, ok := m.lineOf(msg.ID)
+		if !ok {
+			continue
+		}
+		if line >= bottom {
+			break
+		}
+		id = msg.ID
+	}
+	return id
+}
+
 func (m *messagesCmp) View() string {
 	baseStyle := styles.BaseStyle()
 
@@ -444,6 +528,7 @@ func (m *messagesCmp) BindingKeys() []key.Binding {
 		m.viewport.KeyMap.PageUp,
 		m.viewport.KeyMap.HalfPageUp,
 		m.viewport.KeyMap.HalfPageDown,
+		messageKeys.Fork,
 	}
 }
 
@@ -459,6 +544,7 @@ func NewMessagesCmp(app *app.App) tea.Model {
 	return &messagesCmp{
 		app:           app,
 		cachedContent: make(map[string]cacheItem),
+		offsets:       make(map[string]int),
 		viewport:      vp,
 		spinner:       s,
 		attachments:   attachmets,
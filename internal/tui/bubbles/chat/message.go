@@ -332,6 +332,24 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 	t := theme.CurrentTheme()
 	baseStyle := styles.BaseStyle()
 
+	if response.IsError && toolCall.Name == tools.TerminalToolName {
+		// NOTE: a command that ran but exited non-zero still carries output worth showing.
+		metadata := tools.TerminalResponseMetadata{}
+		if err := json.Unmarshal([]byte(response.Metadata), &metadata); err == nil && metadata.ExitCode != 0 {
+			resultContent := truncateHeight(response.Content, maxResultHeight)
+			resultContent = fmt.Sprintf("```bash\n%s\n```", resultContent)
+			exitContent := baseStyle.
+				Width(width).
+				Foreground(t.Error()).
+				Render(fmt.Sprintf("exit code %d after %s", metadata.ExitCode, time.Duration(metadata.Duration)*time.Millisecond))
+			return lipgloss.JoinVertical(
+				lipgloss.Left,
+				styles.ForceReplaceBackgroundWithLipgloss(toMarkdown(resultContent, width), t.Background()),
+				exitContent,
+			)
+		}
+	}
+
 	if response.IsError {
 		errContent := fmt.Sprintf("Error: %s", strings.ReplaceAll(response.Content, "\n", " "))
 		errContent = ansi.Truncate(errContent, width-1, "...")
@@ -0,0 +1,248 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yyovil/tandem/internal/app"
+	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/tui/layout"
+	"github.com/yyovil/tandem/internal/tui/styles"
+	"github.com/yyovil/tandem/internal/tui/theme"
+	"github.com/yyovil/tandem/internal/utils"
+	"github.com/yyovil/tandem/internal/vault"
+)
+
+type TableComponent interface {
+	tea.Model
+	layout.Sizeable
+	layout.Bindings
+}
+
+type tableKeyMap struct {
+	Reveal key.Binding
+	Lock   key.Binding
+	Unlock key.Binding
+}
+
+var keys = tableKeyMap{
+	Reveal: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reveal / hide the secret"),
+	),
+	Lock: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "lock the vault"),
+	),
+	Unlock: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "unlock the vault"),
+	),
+}
+
+type unlockedMsg struct {
+	err error
+}
+
+type tableCmp struct {
+	app           *app.App
+	width, height int
+	table         table.Model
+	passphrase    textinput.Model
+	initialized   bool
+	// revealed is the handle of the credential whose secret is shown below the table.
+	revealed int64
+	secret   string
+}
+
+func (v *tableCmp) Init() tea.Cmd {
+	return v.refresh()
+}
+
+func (v *tableCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pubsub.Event[vault.Credential]:
+		return v, v.refresh()
+	case unlockedMsg:
+		if msg.err != nil {
+			v.passphrase.SetValue("")
+			return v, utils.ReportError(msg.err)
+		}
+		v.passphrase.SetValue("")
+		v.passphrase.Blur()
+		return v, tea.Batch(v.refresh(), utils.ReportInfo("Credential vault unlocked"))
+	case tea.KeyMsg:
+		if !v.app.Vault.Unlocked() {
+			if key.Matches(msg, keys.Unlock) {
+				passphrase := v.passphrase.Value()
+				return v, func() tea.Msg {
+					return unlockedMsg{err: v.app.Vault.Unlock(context.Background(), passphrase)}
+				}
+			}
+			var cmd tea.Cmd
+			v.passphrase, cmd = v.passphrase.Update(msg)
+			return v, cmd
+		}
+		switch {
+		case key.Matches(msg, keys.Reveal):
+			return v, v.toggleReveal()
+		case key.Matches(msg, keys.Lock):
+			v.app.Vault.Lock()
+			v.revealed, v.secret = 0, ""
+			return v, tea.Batch(v.refresh(), utils.ReportInfo("Credential vault locked"))
+		}
+	}
+
+	previous := v.table.SelectedRow()
+	var cmd tea.Cmd
+	v.table, cmd = v.table.Update(msg)
+	if selected := v.table.SelectedRow(); v.revealed != 0 && (selected == nil || previous == nil || selected[0] != previous[0]) {
+		v.revealed, v.secret = 0, ""
+	}
+	return v, cmd
+}
+
+func (v *tableCmp) View() string {
+	t := theme.CurrentTheme()
+	if !v.app.Vault.Unlocked() {
+		title := "Unlock the credential vault"
+		hint := "the agents can store credentials and use their handles once it's unlocked."
+		if !v.initialized {
+			title = "Create the credential vault"
+			hint = "the passphrase encrypts the credentials the agents discover, it can't be recovered."
+		}
+		content := lipgloss.JoinVertical(lipgloss.Left,
+			lipgloss.NewStyle().Bold(true).Foreground(t.Primary()).Render(title),
+			lipgloss.NewStyle().Foreground(t.TextMuted()).Render(hint),
+			"",
+			v.passphrase.View(),
+			"",
+			lipgloss.NewStyle().Foreground(t.TextMuted()).Render(fmt.Sprintf("%d credentials stored, set %s to unlock it on startup.", len(v.table.Rows()), vault.PassphraseEnv)),
+		)
+		return styles.BaseStyle().Width(v.width).Height(v.height).Padding(1, 2).Render(content)
+	}
+
+	defaultStyles := table.DefaultStyles()
+	defaultStyles.Selected = defaultStyles.Selected.Foreground(t.Primary())
+	v.table.SetStyles(defaultStyles)
+
+	footer := lipgloss.NewStyle().Foreground(t.TextMuted()).Render("r reveals the secret of the selected credential, L locks the vault")
+	if v.revealed != 0 {
+		footer = lipgloss.NewStyle().Foreground(t.Warning()).Render(fmt.Sprintf("%s  %s", vault.Handle(v.revealed), v.secret))
+	}
+	return styles.ForceReplaceBackgroundWithLipgloss(lipgloss.JoinVertical(lipgloss.Left, v.table.View(), footer), t.Background())
+}
+
+func (v *tableCmp) GetSize() (int, int) {
+	return v.width, v.height
+}
+
+func (v *tableCmp) SetSize(width int, height int) tea.Cmd {
+	v.width, v.height = width, height
+	v.table.SetWidth(width)
+	v.table.SetHeight(height - 1)
+	columns := v.table.Columns()
+	for i, col := range columns {
+		col.Width = (width / len(columns)) - 2
+		columns[i] = col
+	}
+	v.table.SetColumns(columns)
+	v.passphrase.Width = min(width-8, 60)
+	return nil
+}
+
+func (v *tableCmp) BindingKeys() []key.Binding {
+	if !v.app.Vault.Unlocked() {
+		return []key.Binding{keys.Unlock}
+	}
+	return append([]key.Binding{keys.Reveal, keys.Lock}, utils.KeyMapToSlice(v.table.KeyMap)...)
+}
+
+func (v *tableCmp) refresh() tea.Cmd {
+	ctx := context.Background()
+	initialized, err := v.app.Vault.Initialized(ctx)
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	v.initialized = initialized
+	if !v.app.Vault.Unlocked() {
+		v.passphrase.Focus()
+	}
+
+	stored, err := v.app.Vault.List(ctx)
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	rows := make([]table.Row, 0, len(stored))
+	for _, credential := range stored {
+		rows = append(rows, table.Row{
+			strconv.FormatInt(credential.ID, 10),
+			credential.Handle(),
+			string(credential.Kind),
+			credential.Username,
+			credential.Target,
+			credential.Source,
+			time.Unix(credential.CreatedAt, 0).Format("01-02 15:04"),
+			credential.Notes,
+		})
+	}
+	v.table.SetRows(rows)
+	return nil
+}
+
+func (v *tableCmp) toggleReveal() tea.Cmd {
+	selected := v.table.SelectedRow()
+	if selected == nil {
+		return nil
+	}
+	id, _ := strconv.ParseInt(selected[0], 10, 64)
+	if v.revealed == id {
+		v.revealed, v.secret = 0, ""
+		return nil
+	}
+	secret, err := v.app.Vault.Reveal(context.Background(), id)
+	if errors.Is(err, vault.ErrLocked) {
+		return utils.ReportWarn("The credential vault is locked")
+	}
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	v.revealed, v.secret = id, secret
+	return nil
+}
+
+func NewCredentialsTable(app *app.App) TableComponent {
+	columns := []table.Column{
+		{Title: "ID", Width: 4},
+		{Title: "Handle", Width: 10},
+		{Title: "Kind", Width: 10},
+		{Title: "Username", Width: 10},
+		{Title: "Target", Width: 10},
+		{Title: "Source", Width: 10},
+		{Title: "Stored", Width: 10},
+		{Title: "Notes", Width: 10},
+	}
+	tableModel := table.New(
+		table.WithColumns(columns),
+	)
+	tableModel.Focus()
+
+	passphrase := textinput.New()
+	passphrase.Placeholder = "passphrase"
+	passphrase.EchoMode = textinput.EchoPassword
+	passphrase.EchoCharacter = '•'
+	passphrase.Prompt = "> "
+	return &tableCmp{
+		app:        app,
+		table:      tableModel,
+		passphrase: passphrase,
+	}
+}
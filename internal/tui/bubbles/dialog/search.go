@@ -0,0 +1,271 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yyovil/tandem/internal/message"
+	"github.com/yyovil/tandem/internal/tui/layout"
+	"github.com/yyovil/tandem/internal/tui/styles"
+	"github.com/yyovil/tandem/internal/tui/theme"
+	"github.com/yyovil/tandem/internal/utils"
+)
+
+// SearchResultSelectedMsg is sent when a match of the search is selected
+type SearchResultSelectedMsg struct {
+	Result message.SearchResult
+}
+
+// CloseSearchDialogMsg is sent when the search dialog is closed
+type CloseSearchDialogMsg struct{}
+
+// searchResultsMsg carries the matches of a query, they are dropped when the query changed in the meantime.
+type searchResultsMsg struct {
+	query   string
+	results []message.SearchResult
+	err     error
+}
+
+// SearchDialog interface for the dialog searching the messages of all sessions
+type SearchDialog interface {
+	tea.Model
+	layout.Bindings
+	Reset() tea.Cmd
+}
+
+type searchDialogCmp struct {
+	messages    message.Service
+	input       textinput.Model
+	results     []message.SearchResult
+	selectedIdx int
+	width       int
+	height      int
+	err         error
+}
+
+type searchKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Enter  key.Binding
+	Escape key.Binding
+}
+
+var searchKeys = searchKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous match"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next match"),
+	),
+	Enter: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "jump to message"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+const maxVisibleMatches = 6
+
+func (s *searchDialogCmp) Init() tea.Cmd {
+	return nil
+}
+
+func (s *searchDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case searchResultsMsg:
+		if msg.query != s.input.Value() {
+			return s, nil
+		}
+		s.results, s.err = msg.results, msg.err
+		s.selectedIdx = 0
+		return s, nil
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, searchKeys.Up):
+			if s.selectedIdx > 0 {
+				s.selectedIdx--
+			}
+			return s, nil
+		case key.Matches(msg, searchKeys.Down):
+			if s.selectedIdx < len(s.results)-1 {
+				s.selectedIdx++
+			}
+			return s, nil
+		case key.Matches(msg, searchKeys.Enter):
+			if len(s.results) > 0 {
+				return s, utils.CmdHandler(SearchResultSelectedMsg{
+					Result: s.results[s.selectedIdx],
+				})
+			}
+			return s, nil
+		case key.Matches(msg, searchKeys.Escape):
+			return s, utils.CmdHandler(CloseSearchDialogMsg{})
+		}
+		query := s.input.Value()
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		if s.input.Value() != query {
+			return s, tea.Batch(cmd, s.search(s.input.Value()))
+		}
+		return s, cmd
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return s, cmd
+}
+
+// search looks up the query off the UI goroutine, the index answers well within a keystroke.
+func (s *searchDialogCmp) search(query string) tea.Cmd {
+	if strings.TrimSpace(query) == "" {
+		return utils.CmdHandler(searchResultsMsg{query: query})
+	}
+	return func() tea.Msg {
+		results, err := s.messages.Search(context.Background(), query, message.SearchOptions{Limit: 50})
+		return searchResultsMsg{query: query, results: results, err: err}
+	}
+}
+
+func (s *searchDialogCmp) View() string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	width := max(40, min(90, s.width-15))
+	s.input.Width = width - 4
+
+	title := baseStyle.
+		Foreground(t.Primary()).
+		Bold(true).
+		Width(width).
+		Padding(0, 1).
+		Render("Search History")
+
+	items := []string{}
+	switch {
+	case s.err != nil:
+		items = append(items, baseStyle.Width(width).Padding(0, 1).Foreground(t.Error()).Render(s.err.Error()))
+	case len(s.results) == 0 && strings.TrimSpace(s.input.Value()) != "":
+		items = append(items, baseStyle.Width(width).Padding(0, 1).Foreground(t.TextMuted()).Render("No matches"))
+	}
+
+	startIdx := 0
+	if s.selectedIdx >= maxVisibleMatches {
+		startIdx = s.selectedIdx - maxVisibleMatches + 1
+	}
+	endIdx := min(startIdx+maxVisibleMatches, len(s.results))
+	for i := startIdx; i < endIdx; i++ {
+		items = append(items, s.renderResult(s.results[i], i == s.selectedIdx, width))
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		baseStyle.Width(width).Render(""),
+		baseStyle.Width(width).Padding(0, 1).Render(s.input.View()),
+		baseStyle.Width(width).Render(""),
+		baseStyle.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, items...)),
+	)
+	if len(s.results) > maxVisibleMatches {
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			baseStyle.Width(width).Padding(0, 1).Foreground(t.TextMuted()).Render(fmt.Sprintf("%d of %d matches", s.selectedIdx+1, len(s.results))),
+		)
+	}
+
+	return baseStyle.Padding(1, 2).
+		Border(lipgloss.NormalBorder()).
+		BorderBackground(t.Background()).
+		BorderForeground(t.TextMuted()).
+		Width(lipgloss.Width(content) + 4).
+		Render(content)
+}
+
+// renderResult shows where a match is from on the first line and the snippet around it on the second, with the
+// matching words emphasized.
+func (s *searchDialogCmp) renderResult(r message.SearchResult, selected bool, width int) string {
+	t := theme.CurrentTheme()
+	baseStyle := styles.BaseStyle()
+
+	source := string(r.Role)
+	switch r.Kind {
+	case "tool_call":
+		source = r.Tool + " call"
+	case "tool_result":
+		source = r.Tool + " output"
+	}
+	header := fmt.Sprintf("%s · %s · %s", r.SessionTitle, source, time.Unix(r.CreatedAt, 0).Format("Jan 2 15:04"))
+
+	headerStyle := baseStyle.Foreground(t.TextMuted())
+	textStyle := baseStyle.Foreground(t.Text())
+	matchStyle := baseStyle.Foreground(t.Primary()).Bold(true)
+	marker := "  "
+	if selected {
+		headerStyle = headerStyle.Foreground(t.Primary()).Bold(true)
+		marker = baseStyle.Foreground(t.Primary()).Render("▌ ")
+	}
+
+	var snippet strings.Builder
+	text := strings.Join(strings.Fields(r.Snippet), " ")
+	for text != "" {
+		start := strings.Index(text, message.MatchStart)
+		if start < 0 {
+			snippet.WriteString(textStyle.Render(text))
+			break
+		}
+		snippet.WriteString(textStyle.Render(text[:start]))
+		text = text[start+len(message.MatchStart):]
+		end := strings.Index(text, message.MatchEnd)
+		if end < 0 {
+			end = len(text)
+		}
+		snippet.WriteString(matchStyle.Render(text[:end]))
+		text = strings.TrimPrefix(text[end:], message.MatchEnd)
+	}
+
+	lines := lipgloss.JoinVertical(
+		lipgloss.Left,
+		marker+headerStyle.Render(ansi.Truncate(header, width-4, "…")),
+		marker+ansi.Truncate(snippet.String(), width-4, "…"),
+	)
+	return baseStyle.Width(width).Padding(0, 1, 1, 0).Render(lines)
+}
+
+func (s *searchDialogCmp) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(searchKeys)
+}
+
+// Reset clears the query and the matches of the previous search and focuses the input.
+func (s *searchDialogCmp) Reset() tea.Cmd {
+	s.input.SetValue("")
+	s.results = nil
+	s.selectedIdx = 0
+	s.err = nil
+	return s.input.Focus()
+}
+
+// NewSearchDialogCmp creates a new dialog searching the messages of all sessions
+func NewSearchDialogCmp(messages message.Service) SearchDialog {
+	input := textinput.New()
+	input.Placeholder = "words, \"phrases\", prefix*"
+	input.CharLimit = 200
+	input.Prompt = "> "
+	return &searchDialogCmp{
+		messages: messages,
+		input:    input,
+	}
+}
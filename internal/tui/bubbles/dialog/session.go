@@ -16,6 +16,16 @@ type SessionSelectedMsg struct {
 	Session session.Session
 }
 
+// ExportSessionMsg is sent when the transcript of a session is to be exported
+type ExportSessionMsg struct {
+	Session session.Session
+}
+
+// ForkSessionMsg is sent when a session is to be forked with all of its history
+type ForkSessionMsg struct {
+	Session session.Session
+}
+
 // CloseSessionDialogMsg is sent when the session dialog is closed
 type CloseSessionDialogMsg struct{}
 
@@ -39,6 +49,8 @@ type sessionKeyMap struct {
 	Up     key.Binding
 	Down   key.Binding
 	Enter  key.Binding
+	Export key.Binding
+	Fork   key.Binding
 	Escape key.Binding
 	J      key.Binding
 	K      key.Binding
@@ -57,6 +69,14 @@ var sessionKeys = sessionKeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "select session"),
 	),
+	Export: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "export transcript"),
+	),
+	Fork: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "fork session"),
+	),
 	Escape: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "close"),
@@ -95,6 +115,18 @@ func (s *sessionDialogCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					Session: s.sessions[s.selectedIdx],
 				})
 			}
+		case key.Matches(msg, sessionKeys.Export):
+			if len(s.sessions) > 0 {
+				return s, utils.CmdHandler(ExportSessionMsg{
+					Session: s.sessions[s.selectedIdx],
+				})
+			}
+		case key.Matches(msg, sessionKeys.Fork):
+			if len(s.sessions) > 0 {
+				return s, utils.CmdHandler(ForkSessionMsg{
+					Session: s.sessions[s.selectedIdx],
+				})
+			}
 		case key.Matches(msg, sessionKeys.Escape):
 			return s, utils.CmdHandler(CloseSessionDialogMsg{})
 		}
@@ -121,8 +153,8 @@ func (s *sessionDialogCmp) View() string {
 	// Calculate max width needed for session titles
 	maxWidth := 40 // Minimum width
 	for _, sess := range s.sessions {
-		if len(sess.Title) > maxWidth-4 { // Account for padding
-			maxWidth = len(sess.Title) + 4
+		if len(sessionTitle(sess)) > maxWidth-4 { // Account for padding
+			maxWidth = len(sessionTitle(sess)) + 4
 		}
 	}
 
@@ -159,7 +191,7 @@ func (s *sessionDialogCmp) View() string {
 				Bold(true)
 		}
 
-		sessionItems = append(sessionItems, itemStyle.Padding(0, 1).Render(sess.Title))
+		sessionItems = append(sessionItems, itemStyle.Padding(0, 1).Render(sessionTitle(sess)))
 	}
 
 	title := baseStyle.
@@ -185,6 +217,14 @@ func (s *sessionDialogCmp) View() string {
 		Render(content)
 }
 
+// sessionTitle marks the forks of other sessions.
+func sessionTitle(sess session.Session) string {
+	if sess.ForkedFrom != "" {
+		return "⑂ " + sess.Title
+	}
+	return sess.Title
+}
+
 func (s *sessionDialogCmp) BindingKeys() []key.Binding {
 	return utils.KeyMapToSlice(sessionKeys)
 }
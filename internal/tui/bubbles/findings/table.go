@@ -0,0 +1,146 @@
+package findings
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yyovil/tandem/internal/app"
+	"github.com/yyovil/tandem/internal/finding"
+	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/tui/layout"
+	"github.com/yyovil/tandem/internal/tui/styles"
+	"github.com/yyovil/tandem/internal/tui/theme"
+	"github.com/yyovil/tandem/internal/utils"
+)
+
+type TableComponent interface {
+	tea.Model
+	layout.Sizeable
+	layout.Bindings
+}
+
+type tableCmp struct {
+	app           *app.App
+	width, height int
+	table         table.Model
+	// findings are in the order of the rows, the most severe first.
+	findings []finding.Finding
+}
+
+func (v *tableCmp) Init() tea.Cmd {
+	return v.refresh()
+}
+
+func (v *tableCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case pubsub.Event[finding.Finding]:
+		return v, v.refresh()
+	}
+	var cmd tea.Cmd
+	v.table, cmd = v.table.Update(msg)
+	return v, cmd
+}
+
+func (v *tableCmp) View() string {
+	t := theme.CurrentTheme()
+	defaultStyles := table.DefaultStyles()
+	defaultStyles.Selected = defaultStyles.Selected.Foreground(t.Primary())
+	v.table.SetStyles(defaultStyles)
+
+	footer := lipgloss.NewStyle().Foreground(t.TextMuted()).Render(fmt.Sprintf("%d findings, the most severe first", len(v.findings)))
+	if cursor := v.table.Cursor(); cursor >= 0 && cursor < len(v.findings) {
+		if selected := v.findings[cursor]; selected.CVSSVector != "" {
+			footer = lipgloss.NewStyle().Foreground(t.TextMuted()).Render(selected.CVSSVector)
+		}
+	}
+	return styles.ForceReplaceBackgroundWithLipgloss(lipgloss.JoinVertical(lipgloss.Left, v.table.View(), footer), t.Background())
+}
+
+func (v *tableCmp) GetSize() (int, int) {
+	return v.width, v.height
+}
+
+func (v *tableCmp) SetSize(width int, height int) tea.Cmd {
+	v.width, v.height = width, height
+	v.table.SetWidth(width)
+	v.table.SetHeight(height - 1)
+	columns := v.table.Columns()
+	for i, col := range columns {
+		col.Width = (width / len(columns)) - 2
+		columns[i] = col
+	}
+	v.table.SetColumns(columns)
+	return nil
+}
+
+func (v *tableCmp) BindingKeys() []key.Binding {
+	return utils.KeyMapToSlice(v.table.KeyMap)
+}
+
+func (v *tableCmp) refresh() tea.Cmd {
+	ctx := context.Background()
+	findings, err := v.app.Findings.List(ctx)
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	hosts, err := v.app.Inventory.ListHosts(ctx)
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	addresses := make(map[string]string, len(hosts))
+	for _, host := range hosts {
+		addresses[host.ID] = host.Address
+	}
+
+	slices.SortStableFunc(findings, finding.Compare)
+	rows := make([]table.Row, 0, len(findings))
+	for _, f := range findings {
+		score := ""
+		if f.CVSSVector != "" {
+			score = fmt.Sprintf("%.1f", f.CVSSScore)
+		}
+		where := addresses[f.HostID]
+		if f.Port > 0 {
+			where = fmt.Sprintf("%s:%d", where, f.Port)
+		}
+		rows = append(rows, table.Row{
+			strings.ToUpper(string(f.Severity)),
+			score,
+			f.Title,
+			where,
+			f.Location,
+			f.Source,
+			time.Unix(f.CreatedAt, 0).Format("01-02 15:04"),
+		})
+	}
+	v.findings = findings
+	v.table.SetRows(rows)
+	return nil
+}
+
+func NewFindingsTable(app *app.App) TableComponent {
+	columns := []table.Column{
+		{Title: "Severity", Width: 10},
+		{Title: "CVSS", Width: 4},
+		{Title: "Title", Width: 10},
+		{Title: "Host", Width: 10},
+		{Title: "Location", Width: 10},
+		{Title: "Source", Width: 10},
+		{Title: "Found", Width: 10},
+	}
+	tableModel := table.New(
+		table.WithColumns(columns),
+	)
+	tableModel.Focus()
+	return &tableCmp{
+		app:   app,
+		table: tableModel,
+	}
+}
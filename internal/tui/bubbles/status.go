@@ -10,6 +10,7 @@ import (
 	"github.com/yyovil/tandem/internal/config"
 	"github.com/yyovil/tandem/internal/models"
 	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/schedule"
 	"github.com/yyovil/tandem/internal/session"
 	"github.com/yyovil/tandem/internal/tui/bubbles/chat"
 	"github.com/yyovil/tandem/internal/tui/styles"
@@ -26,6 +27,19 @@ type statusCmp struct {
 	width      int
 	messageTTL time.Duration
 	session    session.Session
+	schedule   *schedule.Schedule
+}
+
+// windowTickMsg refreshes the time left in the testing window.
+type windowTickMsg struct{}
+
+func (m statusCmp) windowTickCmd() tea.Cmd {
+	if m.schedule == nil || !m.schedule.Defined() {
+		return nil
+	}
+	return tea.Tick(30*time.Second, func(time.Time) tea.Msg {
+		return windowTickMsg{}
+	})
 }
 
 // clearMessageCmd is a command that clears status messages after a timeout
@@ -36,7 +50,7 @@ func (m statusCmp) clearMessageCmd(ttl time.Duration) tea.Cmd {
 }
 
 func (m statusCmp) Init() tea.Cmd {
-	return nil
+	return m.windowTickCmd()
 }
 
 func (m statusCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -63,6 +77,8 @@ func (m statusCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.clearMessageCmd(ttl)
 	case utils.ClearStatusMsg:
 		m.info = utils.InfoMsg{}
+	case windowTickMsg:
+		return m, m.windowTickCmd()
 	}
 	return m, nil
 }
@@ -136,6 +152,11 @@ func (m statusCmp) View() string {
 		status += tokensStyle.Render(tokens)
 	}
 
+	if window := m.window(); window != "" {
+		tokenInfoWidth += lipgloss.Width(window)
+		status += window
+	}
+
 	availableWidth := max(0, m.width-lipgloss.Width(helpWidget)-lipgloss.Width(m.model())-tokenInfoWidth)
 
 	if m.info.Msg != "" {
@@ -171,6 +192,26 @@ func (m statusCmp) View() string {
 	return status
 }
 
+// window shows the time left in the testing window of the rules of engagement, or until the next one opens.
+func (m statusCmp) window() string {
+	if m.schedule == nil || !m.schedule.Defined() {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	now := time.Now()
+	state := m.schedule.At(now)
+	style := styles.Padded().Foreground(t.Background())
+	switch {
+	case state.Open && state.Until.IsZero():
+		return style.Background(t.Success()).Render("window open")
+	case state.Open:
+		return style.Background(t.Success()).Render(fmt.Sprintf("window: %s left", schedule.FormatRemaining(state.Until.Sub(now))))
+	case state.Until.IsZero():
+		return style.Background(t.Error()).Render("window closed")
+	}
+	return style.Background(t.Warning()).Render(fmt.Sprintf("paused: opens in %s", schedule.FormatRemaining(state.Until.Sub(now))))
+}
+
 func (m statusCmp) model() string {
 	t := theme.CurrentTheme()
 
@@ -191,7 +232,10 @@ func (m statusCmp) model() string {
 func NewStatusCmp() StatusCmp {
 	helpWidget = getHelpWidget()
 
+	// NOTE: the schedule was validated when the app started, a broken one can't get this far.
+	testingWindows, _ := schedule.FromConfig()
 	return &statusCmp{
 		messageTTL: 10 * time.Second,
+		schedule:   testingWindows,
 	}
 }
@@ -117,7 +117,7 @@ func (p *chatPage) sendMessage(text string, attachments []message.Attachment) te
 		cmds = append(cmds, utils.CmdHandler(chat.SessionSelectedMsg(session)))
 	}
 
-	_, err := p.app.Orchestrator.Run(context.Background(), p.session.ID, text, attachments...)
+	_, err := p.app.RunOrchestrator(context.Background(), p.session.ID, text, attachments...)
 	if err != nil {
 		return utils.ReportError(err)
 	}
@@ -0,0 +1,55 @@
+package page
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yyovil/tandem/internal/app"
+	"github.com/yyovil/tandem/internal/tui/bubbles/findings"
+	"github.com/yyovil/tandem/internal/tui/layout"
+	"github.com/yyovil/tandem/internal/tui/styles"
+)
+
+var FindingsPage PageID = "findings"
+
+type findingsPage struct {
+	width, height int
+	table         layout.Container
+}
+
+func (p *findingsPage) Init() tea.Cmd {
+	return p.table.Init()
+}
+
+func (p *findingsPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return p, p.SetSize(msg.Width, msg.Height)
+	}
+	table, cmd := p.table.Update(msg)
+	p.table = table.(layout.Container)
+	return p, cmd
+}
+
+func (p *findingsPage) View() string {
+	return styles.BaseStyle().Width(p.width).Height(p.height).Render(p.table.View())
+}
+
+func (p *findingsPage) BindingKeys() []key.Binding {
+	return p.table.BindingKeys()
+}
+
+func (p *findingsPage) GetSize() (int, int) {
+	return p.width, p.height
+}
+
+func (p *findingsPage) SetSize(width int, height int) tea.Cmd {
+	p.width = width
+	p.height = height
+	return p.table.SetSize(width, height)
+}
+
+func NewFindingsPage(app *app.App) tea.Model {
+	return &findingsPage{
+		table: layout.NewContainer(findings.NewFindingsTable(app), layout.WithBorderAll()),
+	}
+}
@@ -0,0 +1,55 @@
+package page
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yyovil/tandem/internal/app"
+	"github.com/yyovil/tandem/internal/tui/bubbles/credentials"
+	"github.com/yyovil/tandem/internal/tui/layout"
+	"github.com/yyovil/tandem/internal/tui/styles"
+)
+
+var VaultPage PageID = "vault"
+
+type vaultPage struct {
+	width, height int
+	table         layout.Container
+}
+
+func (p *vaultPage) Init() tea.Cmd {
+	return p.table.Init()
+}
+
+func (p *vaultPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return p, p.SetSize(msg.Width, msg.Height)
+	}
+	table, cmd := p.table.Update(msg)
+	p.table = table.(layout.Container)
+	return p, cmd
+}
+
+func (p *vaultPage) View() string {
+	return styles.BaseStyle().Width(p.width).Height(p.height).Render(p.table.View())
+}
+
+func (p *vaultPage) BindingKeys() []key.Binding {
+	return p.table.BindingKeys()
+}
+
+func (p *vaultPage) GetSize() (int, int) {
+	return p.width, p.height
+}
+
+func (p *vaultPage) SetSize(width int, height int) tea.Cmd {
+	p.width = width
+	p.height = height
+	return p.table.SetSize(width, height)
+}
+
+func NewVaultPage(app *app.App) tea.Model {
+	return &vaultPage{
+		table: layout.NewContainer(credentials.NewCredentialsTable(app), layout.WithBorderAll()),
+	}
+}
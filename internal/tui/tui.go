@@ -3,6 +3,9 @@ package tui
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,9 +13,13 @@ import (
 	"github.com/yyovil/tandem/internal/agent"
 	"github.com/yyovil/tandem/internal/app"
 	"github.com/yyovil/tandem/internal/config"
+	"github.com/yyovil/tandem/internal/finding"
 	"github.com/yyovil/tandem/internal/logging"
+	"github.com/yyovil/tandem/internal/message"
 	"github.com/yyovil/tandem/internal/pubsub"
+	"github.com/yyovil/tandem/internal/report"
 	"github.com/yyovil/tandem/internal/session"
+	"github.com/yyovil/tandem/internal/tools"
 	"github.com/yyovil/tandem/internal/tui/bubbles"
 	"github.com/yyovil/tandem/internal/tui/bubbles/chat"
 	"github.com/yyovil/tandem/internal/tui/bubbles/dialog"
@@ -20,6 +27,7 @@ import (
 	"github.com/yyovil/tandem/internal/tui/page"
 	"github.com/yyovil/tandem/internal/tui/theme"
 	"github.com/yyovil/tandem/internal/utils"
+	"github.com/yyovil/tandem/internal/vault"
 )
 
 const (
@@ -33,6 +41,10 @@ type keyMap struct {
 	SwitchSession key.Binding
 	Filepicker    key.Binding
 	Models        key.Binding
+	EmergencyStop key.Binding
+	Vault         key.Binding
+	Findings      key.Binding
+	Search        key.Binding
 }
 
 var keys = keyMap{
@@ -63,6 +75,22 @@ var keys = keyMap{
 		key.WithKeys("ctrl+o"),
 		key.WithHelp("ctrl+o", "model selection"),
 	),
+	EmergencyStop: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "emergency stop / resume"),
+	),
+	Vault: key.NewBinding(
+		key.WithKeys("ctrl+k"),
+		key.WithHelp("ctrl+k", "credential vault"),
+	),
+	Findings: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "findings"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "search history"),
+	),
 }
 
 var returnKey = key.NewBinding(
@@ -100,6 +128,9 @@ type appModel struct {
 	showFilepicker bool
 	filepicker     dialog.FilepickerCmp
 
+	showSearchDialog bool
+	searchDialog     dialog.SearchDialog
+
 	isCompacting      bool
 	compactingMessage string
 }
@@ -114,10 +145,13 @@ func New(app *app.App) tea.Model {
 		quit:          dialog.NewQuitCmp(),
 		sessionDialog: dialog.NewSessionDialogCmp(),
 		modelDialog:   dialog.NewModelDialogCmp(),
+		searchDialog:  dialog.NewSearchDialogCmp(app.Messages),
 		app:           app,
 		pages: map[page.PageID]tea.Model{
-			page.ChatPage: page.NewChatPage(app),
-			page.LogsPage: page.NewLogsPage(),
+			page.ChatPage:     page.NewChatPage(app),
+			page.LogsPage:     page.NewLogsPage(),
+			page.VaultPage:    page.NewVaultPage(app),
+			page.FindingsPage: page.NewFindingsPage(app),
 		},
 		filepicker: dialog.NewFilepickerCmp(app),
 	}
@@ -127,6 +161,10 @@ func New(app *app.App) tea.Model {
 
 type startCompactSessionMsg struct{}
 
+type sessionForkedMsg struct {
+	Session session.Session
+}
+
 func (a appModel) Init() tea.Cmd {
 	var cmds []tea.Cmd
 	cmd := a.pages[a.currentPage].Init()
@@ -142,6 +180,8 @@ func (a appModel) Init() tea.Cmd {
 	cmds = append(cmds, cmd)
 	cmd = a.modelDialog.Init()
 	cmds = append(cmds, cmd)
+	cmd = a.searchDialog.Init()
+	cmds = append(cmds, cmd)
 
 	cmd = a.filepicker.Init()
 	cmds = append(cmds, cmd)
@@ -174,6 +214,10 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.filepicker = filepicker.(dialog.FilepickerCmp)
 		cmds = append(cmds, filepickerCmd)
 
+		search, searchCmd := a.searchDialog.Update(msg)
+		a.searchDialog = search.(dialog.SearchDialog)
+		cmds = append(cmds, searchCmd)
+
 		return a, tea.Batch(cmds...)
 	// Status
 	case utils.InfoMsg:
@@ -224,6 +268,16 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		s, _ := a.status.Update(msg)
 		a.status = s.(bubbles.StatusCmp)
 
+	// NOTE: the vault page keeps its table current even while it isn't shown.
+	case pubsub.Event[vault.Credential]:
+		a.pages[page.VaultPage], cmd = a.pages[page.VaultPage].Update(msg)
+		return a, cmd
+
+	// NOTE: and so does the findings page.
+	case pubsub.Event[finding.Finding]:
+		a.pages[page.FindingsPage], cmd = a.pages[page.FindingsPage].Update(msg)
+		return a, cmd
+
 	case page.PageChangeMsg:
 		return a, a.moveToPage(msg.ID)
 
@@ -304,6 +358,34 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case dialog.ExportSessionMsg:
+		a.showSessionDialog = false
+		return a, a.exportSession(msg.Session)
+
+	case dialog.ForkSessionMsg:
+		a.showSessionDialog = false
+		return a, a.forkSession(msg.Session.ID, "")
+
+	case chat.ForkSessionMsg:
+		return a, a.forkSession(msg.SessionID, msg.MessageID)
+
+	case sessionForkedMsg:
+		if a.currentPage != page.ChatPage {
+			return a, utils.ReportInfo("Session forked as " + msg.Session.Title)
+		}
+		return a, tea.Batch(
+			utils.CmdHandler(chat.SessionSelectedMsg(msg.Session)),
+			utils.ReportInfo("Session forked as "+msg.Session.Title),
+		)
+
+	case dialog.CloseSearchDialogMsg:
+		a.showSearchDialog = false
+		return a, nil
+
+	case dialog.SearchResultSelectedMsg:
+		a.showSearchDialog = false
+		return a, a.jumpToMessage(msg.Result)
+
 	case tea.KeyMsg:
 		switch {
 
@@ -322,6 +404,9 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if a.showModelDialog {
 				a.showModelDialog = false
 			}
+			if a.showSearchDialog {
+				a.showSearchDialog = false
+			}
 
 			return a, nil
 		case key.Matches(msg, keys.SwitchSession):
@@ -340,6 +425,17 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return a, nil
 
+		case key.Matches(msg, keys.Search):
+			if a.showSearchDialog {
+				a.showSearchDialog = false
+				return a, nil
+			}
+			if !a.showQuit && !a.showSessionDialog && !a.showModelDialog {
+				a.showSearchDialog = true
+				return a, a.searchDialog.Reset()
+			}
+			return a, nil
+
 		case key.Matches(msg, keys.Models):
 			if a.showModelDialog {
 				a.showModelDialog = false
@@ -370,12 +466,37 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					a.filepicker.ToggleFilepicker(a.showFilepicker)
 					return a, nil
 				}
-				if a.currentPage == page.LogsPage {
+				if a.currentPage == page.LogsPage || a.currentPage == page.VaultPage || a.currentPage == page.FindingsPage {
 					return a, a.moveToPage(page.ChatPage)
 				}
 			}
+		case key.Matches(msg, keys.EmergencyStop):
+			if tools.IsHalted() {
+				return a, func() tea.Msg {
+					if err := a.app.ResumeExecution(context.Background()); err != nil {
+						return utils.InfoMsg{Type: utils.InfoTypeError, Msg: err.Error()}
+					}
+					return nil
+				}
+			}
+			return a, func() tea.Msg {
+				if err := a.app.EmergencyStop(context.Background()); err != nil {
+					return utils.InfoMsg{Type: utils.InfoTypeError, Msg: err.Error()}
+				}
+				return nil
+			}
 		case key.Matches(msg, keys.Logs):
 			return a, a.moveToPage(page.LogsPage)
+		case key.Matches(msg, keys.Vault):
+			if a.currentPage == page.VaultPage {
+				return a, a.moveToPage(page.ChatPage)
+			}
+			return a, a.moveToPage(page.VaultPage)
+		case key.Matches(msg, keys.Findings):
+			if a.currentPage == page.FindingsPage {
+				return a, a.moveToPage(page.ChatPage)
+			}
+			return a, a.moveToPage(page.FindingsPage)
 		case key.Matches(msg, keys.Help):
 
 			if a.showQuit {
@@ -436,8 +557,19 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	s, _ := a.status.Update(msg)
+	if a.showSearchDialog {
+		d, searchCmd := a.searchDialog.Update(msg)
+		a.searchDialog = d.(dialog.SearchDialog)
+		cmds = append(cmds, searchCmd)
+		// Only block key messages send all other messages down
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return a, tea.Batch(cmds...)
+		}
+	}
+
+	s, statusCmd := a.status.Update(msg)
 	a.status = s.(bubbles.StatusCmp)
+	cmds = append(cmds, statusCmd)
 	a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
 	cmds = append(cmds, cmd)
 	return a, tea.Batch(cmds...)
@@ -497,7 +629,7 @@ func (a appModel) View() string {
 			bindings = append(bindings, p.BindingKeys()...)
 		}
 
-		if a.currentPage == page.LogsPage {
+		if a.currentPage == page.LogsPage || a.currentPage == page.VaultPage || a.currentPage == page.FindingsPage {
 			bindings = append(bindings, logsKeyReturnKey)
 		}
 
@@ -558,6 +690,20 @@ func (a appModel) View() string {
 		)
 	}
 
+	if a.showSearchDialog {
+		overlay := a.searchDialog.View()
+		row := lipgloss.Height(appView) / 2
+		row -= lipgloss.Height(overlay) / 2
+		col := lipgloss.Width(appView) / 2
+		col -= lipgloss.Width(overlay) / 2
+		appView = layout.PlaceOverlay(
+			col,
+			row,
+			overlay,
+			appView,
+		)
+	}
+
 	return appView
 }
 
@@ -582,3 +728,74 @@ func (a *appModel) moveToPage(pageID page.PageID) tea.Cmd {
 
 	return tea.Batch(cmds...)
 }
+
+// exportSession writes the transcript of a session, with those of its subagents, as markdown and HTML in the
+// transcripts directory without blocking the UI.
+func (a *appModel) exportSession(s session.Session) tea.Cmd {
+	return func() tea.Msg {
+		transcript, err := report.CollectTranscript(context.Background(), a.app.Sessions, a.app.Messages, s.ID, false)
+		if err != nil {
+			return utils.InfoMsg{Type: utils.InfoTypeError, Msg: "failed to export the transcript: " + err.Error()}
+		}
+		transcript.Engagement = config.Get().Engagement
+		paths, err := report.WriteTranscript(transcript, report.Formats, filepath.Join(config.DataDir(), "transcripts"), config.ResolvePath(config.Get().Report.Templates))
+		if err != nil {
+			return utils.InfoMsg{Type: utils.InfoTypeError, Msg: "failed to export the transcript: " + err.Error()}
+		}
+		return utils.InfoMsg{Type: utils.InfoTypeInfo, Msg: "transcript exported to " + strings.Join(paths, ", ")}
+	}
+}
+
+// jumpToMessage opens the session of a search match in the chat and scrolls to the message. the sessions of the
+// subagents are shown within the tool calls that started them, so a match in one of those opens the session on top and
+// scrolls to the call.
+func (a *appModel) jumpToMessage(r message.SearchResult) tea.Cmd {
+	ctx := context.Background()
+	s, err := a.app.Sessions.Get(ctx, r.SessionID)
+	if err != nil {
+		return utils.ReportError(err)
+	}
+	messageID := r.MessageID
+	for s.ParentSessionID != "" {
+		messages, err := a.app.Messages.List(ctx, s.ParentSessionID)
+		if err != nil {
+			return utils.ReportError(err)
+		}
+		for _, m := range messages {
+			if slices.ContainsFunc(m.ToolCalls(), func(c message.ToolCall) bool { return c.ID == s.ID }) {
+				messageID = m.ID
+				break
+			}
+		}
+		if s, err = a.app.Sessions.Get(ctx, s.ParentSessionID); err != nil {
+			return utils.ReportError(err)
+		}
+	}
+
+	var cmds []tea.Cmd
+	if a.currentPage != page.ChatPage {
+		cmds = append(cmds, a.moveToPage(page.ChatPage))
+		// NOTE: the page stays put while the agent is busy, moveToPage warns about it.
+		if a.currentPage != page.ChatPage {
+			return tea.Batch(cmds...)
+		}
+	}
+	return tea.Sequence(append(cmds,
+		utils.CmdHandler(chat.SessionSelectedMsg(s)),
+		utils.CmdHandler(chat.ScrollToMessageMsg{MessageID: messageID}),
+	)...)
+}
+
+// forkSession forks a session at one of its messages, the last one when messageID is empty, and opens the fork.
+func (a *appModel) forkSession(sessionID, messageID string) tea.Cmd {
+	if a.app.Orchestrator.IsSessionBusy(sessionID) {
+		return utils.ReportWarn("Wait for the agent to finish before forking the session")
+	}
+	return func() tea.Msg {
+		fork, err := session.Fork(context.Background(), a.app.Sessions, a.app.Messages, sessionID, messageID)
+		if err != nil {
+			return utils.InfoMsg{Type: utils.InfoTypeError, Msg: "failed to fork the session: " + err.Error()}
+		}
+		return sessionForkedMsg{Session: fork}
+	}
+}
@@ -0,0 +1,344 @@
+// Package vault keeps the credentials discovered during the engagement encrypted at rest. the agents only ever see a
+// handle like {{cred:3}} which the terminal swaps for the secret right before a command runs.
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yyovil/tandem/internal/db"
+	"github.com/yyovil/tandem/internal/pubsub"
+	"golang.org/x/crypto/argon2"
+)
+
+// PassphraseEnv unlocks the vault on startup when it's set.
+const PassphraseEnv = "TANDEM_VAULT_PASSPHRASE"
+
+// minMaskedLength keeps short secrets from being masked all over the output, a password like "a" would be everywhere.
+const minMaskedLength = 4
+
+var (
+	ErrLocked          = errors.New("the credential vault is locked, the operator has to unlock it first")
+	ErrWrongPassphrase = errors.New("wrong vault passphrase")
+	ErrNotFound        = errors.New("no such credential in the vault")
+)
+
+// HandlePattern matches the handles of the credentials in a string.
+var HandlePattern = regexp.MustCompile(`\{\{\s*cred:(\d+)\s*\}\}`)
+
+// ForeignHandlePattern matches the handles of another installation's credentials, see Disown.
+var ForeignHandlePattern = regexp.MustCompile(`\{\{\s*foreign-cred:(\d+)\s*\}\}`)
+
+// verifierPlaintext is sealed with the key on creation, opening it again tells a wrong passphrase apart.
+var verifierPlaintext = []byte("tandem credential vault")
+
+type Kind string
+
+const (
+	KindPassword Kind = "password"
+	KindHash     Kind = "hash"
+	KindToken    Kind = "token"
+	KindKey      Kind = "key"
+	KindCookie   Kind = "cookie"
+	KindOther    Kind = "other"
+)
+
+var Kinds = []Kind{KindPassword, KindHash, KindToken, KindKey, KindCookie, KindOther}
+
+// Credential is what's known about a credential besides its secret.
+type Credential struct {
+	ID        int64
+	Kind      Kind
+	Username  string
+	Target    string
+	Notes     string
+	Source    string
+	CreatedAt int64
+}
+
+// Handle is how the agents refer to the credential.
+func (c Credential) Handle() string {
+	return Handle(c.ID)
+}
+
+func Handle(id int64) string {
+	return fmt.Sprintf("{{cred:%d}}", id)
+}
+
+// Disown rewrites the handles in s, which comes from another installation, so that they're never resolved with the
+// credentials of this vault. the IDs are only unique within a vault, {{cred:3}} of another one is a different secret.
+func Disown(s string) string {
+	return HandlePattern.ReplaceAllString(s, "{{foreign-cred:$1}}")
+}
+
+type Service interface {
+	pubsub.Subscriber[Credential]
+	// Unlock derives the key from the passphrase, the first unlock creates the vault with it.
+	Unlock(ctx context.Context, passphrase string) error
+	Lock()
+	Unlocked() bool
+	Initialized(ctx context.Context) (bool, error)
+	// Store seals the secret and returns the credential it's stored as. storing a known secret again returns the known credential.
+	Store(ctx context.Context, credential Credential, secret string) (Credential, error)
+	List(ctx context.Context) ([]Credential, error)
+	Reveal(ctx context.Context, id int64) (string, error)
+	// Substitute replaces the handles in args with their secrets.
+	Substitute(ctx context.Context, args []string) ([]string, error)
+	// Mask replaces the secrets of the vault in s with their handles.
+	Mask(s string) string
+}
+
+type service struct {
+	*pubsub.Broker[Credential]
+	q db.Querier
+
+	mu      sync.RWMutex
+	aead    cipher.AEAD
+	secrets map[int64]string
+}
+
+func NewService(q db.Querier) Service {
+	return &service{
+		Broker: pubsub.NewBroker[Credential](),
+		q:      q,
+	}
+}
+
+func (s *service) Initialized(ctx context.Context) (bool, error) {
+	_, err := s.q.GetVault(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *service) Unlock(ctx context.Context, passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("the vault passphrase can't be empty")
+	}
+	stored, err := s.q.GetVault(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		aead, err := newAEAD(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		verifier, err := seal(aead, verifierPlaintext)
+		if err != nil {
+			return err
+		}
+		if err := s.q.CreateVault(ctx, db.CreateVaultParams{Salt: salt, Verifier: verifier}); err != nil {
+			return err
+		}
+		stored = db.Vault{Salt: salt, Verifier: verifier}
+	case err != nil:
+		return err
+	}
+
+	aead, err := newAEAD(passphrase, stored.Salt)
+	if err != nil {
+		return err
+	}
+	plaintext, err := open(aead, stored.Verifier)
+	if err != nil || subtle.ConstantTimeCompare(plaintext, verifierPlaintext) != 1 {
+		return ErrWrongPassphrase
+	}
+
+	dbCredentials, err := s.q.ListCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	secrets := make(map[int64]string, len(dbCredentials))
+	for _, dbCredential := range dbCredentials {
+		secret, err := open(aead, dbCredential.Secret)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt credential %d: %w", dbCredential.ID, err)
+		}
+		secrets[dbCredential.ID] = string(secret)
+	}
+
+	s.mu.Lock()
+	s.aead, s.secrets = aead, secrets
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *service) Lock() {
+	s.mu.Lock()
+	s.aead, s.secrets = nil, nil
+	s.mu.Unlock()
+}
+
+func (s *service) Unlocked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.aead != nil
+}
+
+func (s *service) Store(ctx context.Context, credential Credential, secret string) (Credential, error) {
+	if secret == "" {
+		return Credential{}, fmt.Errorf("the secret can't be empty")
+	}
+	if credential.Kind == "" {
+		credential.Kind = KindPassword
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aead == nil {
+		return Credential{}, ErrLocked
+	}
+	for id, known := range s.secrets {
+		if known != secret {
+			continue
+		}
+		dbCredential, err := s.q.GetCredential(ctx, id)
+		if err != nil {
+			return Credential{}, err
+		}
+		if dbCredential.Username == credential.Username && dbCredential.Target == credential.Target {
+			return fromDBItem(dbCredential), nil
+		}
+	}
+
+	sealed, err := seal(s.aead, []byte(secret))
+	if err != nil {
+		return Credential{}, err
+	}
+	dbCredential, err := s.q.CreateCredential(ctx, db.CreateCredentialParams{
+		Kind:     string(credential.Kind),
+		Username: credential.Username,
+		Target:   credential.Target,
+		Secret:   sealed,
+		Notes:    credential.Notes,
+		Source:   credential.Source,
+	})
+	if err != nil {
+		return Credential{}, err
+	}
+	s.secrets[dbCredential.ID] = secret
+
+	stored := fromDBItem(dbCredential)
+	s.Publish(pubsub.CreatedEvent, stored)
+	return stored, nil
+}
+
+func (s *service) List(ctx context.Context) ([]Credential, error) {
+	dbCredentials, err := s.q.ListCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]Credential, len(dbCredentials))
+	for i, dbCredential := range dbCredentials {
+		credentials[i] = fromDBItem(dbCredential)
+	}
+	return credentials, nil
+}
+
+func (s *service) Reveal(ctx context.Context, id int64) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.aead == nil {
+		return "", ErrLocked
+	}
+	secret, ok := s.secrets[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, Handle(id))
+	}
+	return secret, nil
+}
+
+func (s *service) Substitute(ctx context.Context, args []string) ([]string, error) {
+	substituted := make([]string, len(args))
+	for i, arg := range args {
+		var err error
+		substituted[i] = HandlePattern.ReplaceAllStringFunc(arg, func(handle string) string {
+			if err != nil {
+				return handle
+			}
+			id, _ := strconv.ParseInt(HandlePattern.FindStringSubmatch(handle)[1], 10, 64)
+			var secret string
+			secret, err = s.Reveal(ctx, id)
+			return secret
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return substituted, nil
+}
+
+func (s *service) Mask(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.secrets) == 0 {
+		return text
+	}
+	// NOTE: the longest secrets go first so that a secret containing another one is masked as a whole.
+	ids := make([]int64, 0, len(s.secrets))
+	for id := range s.secrets {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return len(s.secrets[ids[i]]) > len(s.secrets[ids[j]])
+	})
+	for _, id := range ids {
+		if secret := s.secrets[id]; len(secret) >= minMaskedLength {
+			text = strings.ReplaceAll(text, secret, Handle(id))
+		}
+	}
+	return text
+}
+
+func fromDBItem(item db.Credential) Credential {
+	return Credential{
+		ID:        item.ID,
+		Kind:      Kind(item.Kind),
+		Username:  item.Username,
+		Target:    item.Target,
+		Notes:     item.Notes,
+		Source:    item.Source,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+// newAEAD derives the vault key from the passphrase with argon2id and returns an AES-256-GCM cipher for it.
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, 3, 64*1024, 4, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce which it prepends to the ciphertext.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed secret is too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+}
@@ -0,0 +1,89 @@
+package vault
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/pressly/goose/v3"
+	"github.com/yyovil/tandem/internal/db"
+)
+
+func newTestService(t *testing.T) (Service, *sql.DB) {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetBaseFS(db.FS)
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := goose.Up(conn, "migrations"); err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db.New(conn)), conn
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	service, conn := newTestService(t)
+
+	if _, err := service.Store(ctx, Credential{Username: "admin"}, "s3cr3t-pass"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected a locked vault, got %v", err)
+	}
+	if err := service.Unlock(ctx, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := service.Store(ctx, Credential{Username: "admin", Target: "ssh://10.10.10.5"}, "s3cr3t-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Handle() != "{{cred:1}}" || stored.Kind != KindPassword {
+		t.Fatalf("unexpected credential: %+v", stored)
+	}
+	again, err := service.Store(ctx, Credential{Username: "admin", Target: "ssh://10.10.10.5"}, "s3cr3t-pass")
+	if err != nil || again.ID != stored.ID {
+		t.Fatalf("expected the known credential back, got %+v, %v", again, err)
+	}
+
+	var sealed []byte
+	if err := conn.QueryRow("SELECT secret FROM credentials WHERE id = 1").Scan(&sealed); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "s3cr3t-pass") {
+		t.Fatal("the secret is stored in the clear")
+	}
+
+	args, err := service.Substitute(ctx, []string{"sshpass", "-p", "{{cred:1}}", "ssh", "admin@10.10.10.5"})
+	if err != nil || args[2] != "s3cr3t-pass" {
+		t.Fatalf("unexpected substitution %v, %v", args, err)
+	}
+	if _, err := service.Substitute(ctx, []string{"{{ cred:7 }}"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected an unknown handle to fail, got %v", err)
+	}
+	if masked := service.Mask("login with s3cr3t-pass ok"); masked != "login with {{cred:1}} ok" {
+		t.Fatalf("unexpected mask %q", masked)
+	}
+
+	service.Lock()
+	if _, err := service.Substitute(ctx, []string{"{{cred:1}}"}); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected a locked vault, got %v", err)
+	}
+	if err := service.Unlock(ctx, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected a wrong passphrase, got %v", err)
+	}
+	reopened := NewService(db.New(conn))
+	if err := reopened.Unlock(ctx, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := reopened.Reveal(ctx, 1); err != nil || secret != "s3cr3t-pass" {
+		t.Fatalf("unexpected secret %q, %v", secret, err)
+	}
+}